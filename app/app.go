@@ -1,16 +1,68 @@
 package app
 
 import (
+	"context"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/p4xx07/order-service/app/domains/currency"
+	"github.com/p4xx07/order-service/app/domains/inventory"
 	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/app/domains/payment"
+	"github.com/p4xx07/order-service/app/domains/product"
+	"github.com/p4xx07/order-service/app/domains/promotion"
+	"github.com/p4xx07/order-service/app/domains/returns"
+	"github.com/p4xx07/order-service/app/domains/shipment"
+	"github.com/p4xx07/order-service/app/domains/tax"
+	"github.com/p4xx07/order-service/app/domains/user"
+	"github.com/p4xx07/order-service/app/domains/webhook"
+	"github.com/p4xx07/order-service/internal/event"
+	"github.com/p4xx07/order-service/internal/idempotency"
 	"net/http"
 )
 
 type App struct {
-	OrderHandler order.IHandler
+	OrderHandler     order.IHandler
+	ProductHandler   product.IHandler
+	InventoryHandler inventory.IHandler
+	UserHandler      user.IHandler
+	CurrencyHandler  currency.IHandler
+	PromotionHandler promotion.IHandler
+	TaxHandler       tax.IHandler
+	ShipmentHandler  shipment.IHandler
+	ReturnHandler    returns.IHandler
+	PaymentHandler   payment.IHandler
+	WebhookHandler   webhook.IHandler
+
+	OrderAdminHandler order.IAdminHandler
+
+	Idempotency idempotency.Middleware
+
+	EventBus          event.IBus
+	SearchIndexer     order.ISearchIndexer
+	WebhookSubscriber webhook.ISubscriber
+	StreamPublisher   order.IStreamPublisher
+	PaymentSubscriber payment.ISubscriber
+
+	ReservationSweeper order.IReservationSweeper
+	OutboxRelay        order.IOutboxRelay
+	Reindexer          order.IReindexer
+	WebhookDispatcher  webhook.IDispatcher
+}
+
+// StartWorkers subscribes the event handlers and launches the background jobs; they stop when ctx is cancelled.
+func (a *App) StartWorkers(ctx context.Context) {
+	a.SearchIndexer.Subscribe(a.EventBus)
+	a.WebhookSubscriber.Subscribe(a.EventBus)
+	a.StreamPublisher.Subscribe(a.EventBus)
+	a.PaymentSubscriber.Subscribe(a.EventBus)
+	go a.EventBus.Run(ctx)
+
+	go a.ReservationSweeper.Run(ctx)
+	go a.OutboxRelay.Run(ctx)
+	go a.Reindexer.Sync(ctx)
+	go a.WebhookDispatcher.Run(ctx)
 }
 
 func (a *App) Routes() *fiber.App {
@@ -19,14 +71,28 @@ func (a *App) Routes() *fiber.App {
 	f.Use(recover.New())
 	f.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
-		AllowHeaders: "Content-Type",
+		AllowHeaders: "Content-Type, " + idempotency.HeaderKey,
 		AllowMethods: "GET, HEAD, OPTIONS, PUT, PATCH, POST, DELETE",
 	}))
 	f.Get("/health", func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) })
 
 	api := f.Group("/api/v1.0")
 
-	order.SetRoutes(api, a.OrderHandler)
+	order.SetRoutes(api, a.OrderHandler, a.Idempotency)
+	product.SetRoutes(api, a.ProductHandler)
+	inventory.SetRoutes(api, a.InventoryHandler)
+	user.SetRoutes(api, a.UserHandler)
+	currency.SetRoutes(api, a.CurrencyHandler)
+	shipment.SetRoutes(api, a.ShipmentHandler)
+	returns.SetRoutes(api, a.ReturnHandler)
+	payment.SetRoutes(api, a.PaymentHandler)
+
+	admin := api.Group("admin")
+	order.SetAdminRoutes(admin, a.OrderAdminHandler)
+	currency.SetAdminRoutes(admin, a.CurrencyHandler)
+	promotion.SetAdminRoutes(admin, a.PromotionHandler)
+	tax.SetAdminRoutes(admin, a.TaxHandler)
+	webhook.SetAdminRoutes(admin, a.WebhookHandler)
 
 	return f
 }
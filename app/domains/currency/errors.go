@@ -0,0 +1,8 @@
+package currency
+
+import "errors"
+
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrInvalidRate         = errors.New("invalid exchange rate")
+)
@@ -0,0 +1,52 @@
+package currency
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	http2 "github.com/p4xx07/order-service/internal/http"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type IHandler interface {
+	ListRates(ctx *fiber.Ctx) error
+	PutRates(ctx *fiber.Ctx) error
+}
+
+type handler struct {
+	service IService
+	logger  *zap.SugaredLogger
+}
+
+func NewHandler(service IService, logger *zap.SugaredLogger) IHandler {
+	return &handler{service: service, logger: logger}
+}
+
+func (h *handler) ListRates(c *fiber.Ctx) error {
+	response, err := h.service.ListRates(c.Context())
+	if err != nil {
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) PutRates(c *fiber.Ctx) error {
+	var request PutRatesRequest
+	if err := c.BodyParser(&request); err != nil {
+		h.logger.Errorf("bodyRequest error %v | %v", request, err.Error())
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	response, err := h.service.SetRates(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, ErrUnsupportedCurrency) || errors.Is(err, ErrInvalidRate) {
+			return http2.JSON(c, http.StatusBadRequest, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
@@ -0,0 +1,13 @@
+package currency
+
+import (
+	"github.com/p4xx07/order-service/internal/money"
+	"time"
+)
+
+// ExchangeRate is how many units of Currency one unit of the base currency buys.
+type ExchangeRate struct {
+	Currency  money.Currency `gorm:"type:varchar(3);primaryKey"`
+	Rate      float64        `gorm:"type:decimal(18,8);not null"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
+}
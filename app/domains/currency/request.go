@@ -0,0 +1,7 @@
+package currency
+
+import "github.com/p4xx07/order-service/internal/money"
+
+type PutRatesRequest struct {
+	Rates map[money.Currency]float64 `json:"rates"`
+}
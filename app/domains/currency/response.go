@@ -0,0 +1,25 @@
+package currency
+
+import (
+	"github.com/p4xx07/order-service/internal/money"
+	"time"
+)
+
+type RateResponse struct {
+	Currency  money.Currency `json:"currency"`
+	Rate      float64        `json:"rate"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+func (r *ExchangeRate) ToResponse() RateResponse {
+	return RateResponse{
+		Currency:  r.Currency,
+		Rate:      r.Rate,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+type ListRatesResponse struct {
+	Base  money.Currency `json:"base"`
+	Items []RateResponse `json:"items"`
+}
@@ -0,0 +1,15 @@
+package currency
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetRoutes(router fiber.Router, handler IHandler) {
+	g := router.Group("currency")
+	g.Get("/rates", handler.ListRates)
+}
+
+func SetAdminRoutes(router fiber.Router, handler IHandler) {
+	g := router.Group("currency")
+	g.Put("/rates", handler.PutRates)
+}
@@ -0,0 +1,88 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/money"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// IService converts prices from the base currency, in which products are priced, to the currencies orders are placed in.
+type IService interface {
+	ListRates(ctx context.Context) (*ListRatesResponse, error)
+	SetRates(ctx context.Context, request PutRatesRequest) (*ListRatesResponse, error)
+	Rate(ctx context.Context, currency money.Currency) (float64, error)
+	Base() money.Currency
+}
+
+type service struct {
+	configuration *configuration.Configuration
+	logger        *zap.SugaredLogger
+	store         IStore
+}
+
+func NewService(store IStore, configuration *configuration.Configuration, logger *zap.SugaredLogger) IService {
+	return &service{store: store, configuration: configuration, logger: logger}
+}
+
+func (s *service) Base() money.Currency {
+	return money.Currency(s.configuration.BaseCurrency)
+}
+
+func (s *service) ListRates(ctx context.Context) (*ListRatesResponse, error) {
+	rates, err := s.store.List(ctx)
+	if err != nil {
+		s.logger.Errorw("error listing exchange rates", "error", err)
+		return nil, err
+	}
+
+	items := make([]RateResponse, len(rates))
+	for i := range rates {
+		items[i] = rates[i].ToResponse()
+	}
+	return &ListRatesResponse{Base: s.Base(), Items: items}, nil
+}
+
+// SetRates loads the given exchange rates; currencies left out keep their current rate.
+func (s *service) SetRates(ctx context.Context, request PutRatesRequest) (*ListRatesResponse, error) {
+	if len(request.Rates) == 0 {
+		return nil, ErrInvalidRate
+	}
+
+	rates := make([]ExchangeRate, 0, len(request.Rates))
+	for currency, rate := range request.Rates {
+		if !currency.IsValid() || currency == s.Base() {
+			return nil, ErrUnsupportedCurrency
+		}
+		if rate <= 0 {
+			return nil, ErrInvalidRate
+		}
+		rates = append(rates, ExchangeRate{Currency: currency, Rate: rate})
+	}
+
+	if err := s.store.Save(ctx, rates); err != nil {
+		s.logger.Errorw("error saving exchange rates", "error", err)
+		return nil, err
+	}
+
+	return s.ListRates(ctx)
+}
+
+// Rate is how many units of currency one unit of the base currency buys.
+func (s *service) Rate(ctx context.Context, currency money.Currency) (float64, error) {
+	if currency == s.Base() {
+		return 1, nil
+	}
+
+	rate, err := s.store.Get(ctx, currency)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrUnsupportedCurrency
+	}
+	if err != nil {
+		s.logger.Errorw("error getting exchange rate", "error", err, "currency", currency)
+		return 0, err
+	}
+	return rate.Rate, nil
+}
@@ -0,0 +1,49 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"github.com/p4xx07/order-service/internal/money"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IStore interface {
+	List(ctx context.Context) ([]ExchangeRate, error)
+	Get(ctx context.Context, currency money.Currency) (*ExchangeRate, error)
+	Save(ctx context.Context, rates []ExchangeRate) error
+}
+
+type store struct {
+	db *gorm.DB
+}
+
+func NewStore(db *gorm.DB) IStore {
+	return &store{db: db}
+}
+
+func (s *store) List(ctx context.Context) ([]ExchangeRate, error) {
+	var rates []ExchangeRate
+	if err := s.db.WithContext(ctx).Order("currency").Find(&rates).Error; err != nil {
+		return nil, fmt.Errorf("failed to list exchange rates: %w", err)
+	}
+	return rates, nil
+}
+
+func (s *store) Get(ctx context.Context, currency money.Currency) (*ExchangeRate, error) {
+	var rate ExchangeRate
+	err := s.db.
+		WithContext(ctx).
+		Where("currency = ?", currency).
+		First(&rate).Error
+
+	return &rate, err
+}
+
+// Save inserts the rates, replacing those already set for the same currencies.
+func (s *store) Save(ctx context.Context, rates []ExchangeRate) error {
+	return s.db.
+		WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(&rates).Error
+}
@@ -0,0 +1,12 @@
+package inventory
+
+import "errors"
+
+var (
+	ErrInsufficientStock = errors.New("not enough stock")
+	ErrInvalidReason     = errors.New("invalid adjustment reason")
+	ErrInvalidQuantity   = errors.New("invalid adjustment quantity")
+	ErrNoReservation     = errors.New("no active reservation")
+	ErrInvalidReorder    = errors.New("invalid reorder point")
+	ErrInvalidStatus     = errors.New("invalid alert status")
+)
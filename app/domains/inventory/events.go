@@ -0,0 +1,95 @@
+package inventory
+
+import (
+	"cmp"
+	"fmt"
+	"github.com/p4xx07/order-service/internal/event"
+	"slices"
+)
+
+const (
+	EventStockDecreased event.Name = "stock.decreased"
+	EventStockIncreased event.Name = "stock.increased"
+	EventStockLow       event.Name = "stock.low"
+)
+
+// StockDecreased and StockIncreased are published once the stock change committed.
+// Quantities maps the product IDs to the units taken or added.
+type StockDecreased struct {
+	Quantities map[uint]int
+	Reference  Reference
+}
+
+func (StockDecreased) EventName() event.Name {
+	return EventStockDecreased
+}
+
+func (e StockDecreased) Data() interface{} {
+	return newStockChangedData(e.Quantities, e.Reference)
+}
+
+type StockIncreased struct {
+	Quantities map[uint]int
+	Reference  Reference
+}
+
+func (StockIncreased) EventName() event.Name {
+	return EventStockIncreased
+}
+
+func (e StockIncreased) Data() interface{} {
+	return newStockChangedData(e.Quantities, e.Reference)
+}
+
+// StockLow is published once the stock change that brought a product down to its reorder point committed,
+// right after the StockDecreased event of that change.
+type StockLow struct {
+	Alert LowStockAlert
+}
+
+func (StockLow) EventName() event.Name {
+	return EventStockLow
+}
+
+// EventID is the same for every delivery of the alert.
+func (e StockLow) EventID() string {
+	return fmt.Sprintf("%s.%d", EventStockLow, e.Alert.ID)
+}
+
+func (e StockLow) Data() interface{} {
+	return e.Alert.ToResponse()
+}
+
+// StockChangedData is what the stock events look like to other services.
+type StockChangedData struct {
+	Items   []StockChange `json:"items"`
+	Reason  Reason        `json:"reason"`
+	OrderID uint          `json:"order_id,omitempty"`
+	Note    string        `json:"note,omitempty"`
+}
+
+type StockChange struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+}
+
+func newStockChangedData(quantities map[uint]int, reference Reference) StockChangedData {
+	items := make([]StockChange, 0, len(quantities))
+	for productID, quantity := range quantities {
+		items = append(items, StockChange{ProductID: productID, Quantity: quantity})
+	}
+	slices.SortFunc(items, func(a, b StockChange) int {
+		return cmp.Compare(a.ProductID, b.ProductID)
+	})
+
+	return StockChangedData{Items: items, Reason: reference.Reason, OrderID: reference.OrderID, Note: reference.Note}
+}
+
+// decreasedEvents are the StockDecreased event of a decrement followed by a StockLow event for every alert it raised.
+func decreasedEvents(quantities map[uint]int, reference Reference, alerts []LowStockAlert) []event.Event {
+	events := []event.Event{StockDecreased{Quantities: quantities, Reference: reference}}
+	for _, alert := range alerts {
+		events = append(events, StockLow{Alert: alert})
+	}
+	return events
+}
@@ -0,0 +1,185 @@
+package inventory
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	http2 "github.com/p4xx07/order-service/internal/http"
+	"go.uber.org/zap"
+	"gopkg.in/validator.v2"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+type IHandler interface {
+	Get(ctx *fiber.Ctx) error
+	Put(ctx *fiber.Ctx) error
+	PutReorder(ctx *fiber.Ctx) error
+	PostAdjustment(ctx *fiber.Ctx) error
+	ListMovements(ctx *fiber.Ctx) error
+	ListAlerts(ctx *fiber.Ctx) error
+}
+
+type handler struct {
+	service IService
+	logger  *zap.SugaredLogger
+}
+
+func NewHandler(service IService, logger *zap.SugaredLogger) IHandler {
+	return &handler{service: service, logger: logger}
+}
+
+func (h *handler) Get(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("productId"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	inventory, err := h.service.Get(c.Context(), uint(productID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, inventory.ToResponse(), nil)
+}
+
+func (h *handler) Put(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("productId"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	var request SetStockRequest
+	if err := c.BodyParser(&request); err != nil {
+		h.logger.Errorf("bodyRequest error: %v", err.Error())
+		return c.Status(http.StatusBadRequest).JSON("Invalid request body")
+	}
+	request.ProductID = uint(productID)
+
+	if errs := validator.Validate(request); errs != nil {
+		return c.Status(http.StatusBadRequest).JSON(errs)
+	}
+
+	response, err := h.service.SetStock(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) PutReorder(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("productId"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	var request ReorderRequest
+	if err := c.BodyParser(&request); err != nil {
+		h.logger.Errorf("bodyRequest error: %v", err.Error())
+		return c.Status(http.StatusBadRequest).JSON("Invalid request body")
+	}
+	request.ProductID = uint(productID)
+
+	if errs := validator.Validate(request); errs != nil {
+		return c.Status(http.StatusBadRequest).JSON(errs)
+	}
+
+	response, err := h.service.SetReorder(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, ErrInvalidReorder) {
+			return http2.JSON(c, http.StatusBadRequest, nil, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) PostAdjustment(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("productId"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	var request AdjustmentRequest
+	if err := c.BodyParser(&request); err != nil {
+		h.logger.Errorf("bodyRequest error: %v", err.Error())
+		return c.Status(http.StatusBadRequest).JSON("Invalid request body")
+	}
+	request.ProductID = uint(productID)
+
+	if errs := validator.Validate(request); errs != nil {
+		return c.Status(http.StatusBadRequest).JSON(errs)
+	}
+
+	response, err := h.service.Adjust(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, ErrInvalidReason) || errors.Is(err, ErrInvalidQuantity) {
+			return http2.JSON(c, http.StatusBadRequest, nil, err)
+		}
+		if errors.Is(err, ErrInsufficientStock) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) ListMovements(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("productId"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	request := ListMovementsRequest{
+		ProductID: uint(productID),
+		Limit:     c.QueryInt("limit"),
+		Offset:    c.QueryInt("offset"),
+	}
+
+	response, err := h.service.ListMovements(c.Context(), request)
+	if err != nil {
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) ListAlerts(c *fiber.Ctx) error {
+	request := ListAlertsRequest{
+		ProductID: uint(c.QueryInt("product_id")),
+		Status:    AlertStatus(c.Query("status")),
+		Limit:     c.QueryInt("limit"),
+		Offset:    c.QueryInt("offset"),
+	}
+
+	response, err := h.service.ListAlerts(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, ErrInvalidStatus) {
+			return http2.JSON(c, http.StatusBadRequest, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
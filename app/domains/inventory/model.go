@@ -1,11 +1,138 @@
 package inventory
 
-import "github.com/p4xx07/order-service/app/domains/product"
+import (
+	"github.com/p4xx07/order-service/app/domains/product"
+	"time"
+)
 
 type Inventory struct {
 	ID        uint `gorm:"primaryKey;autoIncrement"`
 	ProductID uint `gorm:"index;unique"`
 	Stock     int  `gorm:"type:int;not null"`
+	Reserved  int  `gorm:"-"`
+	// ReorderPoint is the stock at which a low-stock alert is raised, nil turns the alerts off.
+	ReorderPoint *int `gorm:"type:int"`
+	// ReorderQuantity is how many units purchasing should order when the alert is raised.
+	ReorderQuantity int `gorm:"type:int;not null;default:0"`
 
 	Product product.Product `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 }
+
+// Available is the stock that is neither sold nor held by an active reservation.
+func (i Inventory) Available() int {
+	return i.Stock - i.Reserved
+}
+
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationCommitted ReservationStatus = "committed"
+	ReservationReleased  ReservationStatus = "released"
+	ReservationExpired   ReservationStatus = "expired"
+)
+
+// Reservation holds stock for a pending order without decrementing it.
+type Reservation struct {
+	ID        uint              `gorm:"primaryKey;autoIncrement"`
+	OrderID   uint              `gorm:"index;not null"`
+	ProductID uint              `gorm:"index:idx_reservations_product_status;not null"`
+	Quantity  int               `gorm:"type:int;not null"`
+	Status    ReservationStatus `gorm:"type:varchar(20);not null;index:idx_reservations_product_status"`
+	CreatedAt time.Time         `gorm:"autoCreateTime"`
+	UpdatedAt time.Time         `gorm:"autoUpdateTime"`
+}
+
+type Reason string
+
+const (
+	ReasonRestock        Reason = "restock"
+	ReasonDamage         Reason = "damage"
+	ReasonCorrection     Reason = "correction"
+	ReasonOrderConfirmed Reason = "order_confirmed"
+	ReasonOrderUpdated   Reason = "order_updated"
+	ReasonOrderDeleted   Reason = "order_deleted"
+	ReasonOrderCancelled Reason = "order_cancelled"
+	ReasonOrderRefunded  Reason = "order_refunded"
+	ReasonOrderReturned  Reason = "order_returned"
+)
+
+// IsManual reports whether the reason can be used for adjustments posted through the API.
+func (r Reason) IsManual() bool {
+	return r == ReasonRestock || r == ReasonDamage || r == ReasonCorrection
+}
+
+// Reference describes why a stock change happened and is recorded on every movement it produces.
+type Reference struct {
+	Reason  Reason
+	OrderID uint
+	Note    string
+}
+
+// InventoryMovement is a ledger entry for a single stock change; Quantity is signed.
+type InventoryMovement struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	ProductID uint      `gorm:"index;not null"`
+	Quantity  int       `gorm:"type:int;not null"`
+	Reason    Reason    `gorm:"type:varchar(30);not null"`
+	OrderID   *uint     `gorm:"index"`
+	Note      string    `gorm:"type:varchar(255)"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index"`
+}
+
+func newMovement(productID uint, quantity int, reference Reference) InventoryMovement {
+	movement := InventoryMovement{
+		ProductID: productID,
+		Quantity:  quantity,
+		Reason:    reference.Reason,
+		Note:      reference.Note,
+	}
+	if reference.OrderID != 0 {
+		orderID := reference.OrderID
+		movement.OrderID = &orderID
+	}
+	return movement
+}
+
+type AlertStatus string
+
+const (
+	AlertOpen     AlertStatus = "open"
+	AlertResolved AlertStatus = "resolved"
+)
+
+// LowStockAlert is raised when taking stock brings a product down to its reorder point,
+// and resolved once the stock is back above it.
+type LowStockAlert struct {
+	ID              uint        `gorm:"primaryKey;autoIncrement"`
+	ProductID       uint        `gorm:"index:idx_low_stock_alerts_product_status;not null"`
+	Stock           int         `gorm:"type:int;not null"`
+	ReorderPoint    int         `gorm:"type:int;not null"`
+	ReorderQuantity int         `gorm:"type:int;not null"`
+	Reason          Reason      `gorm:"type:varchar(30);not null"`
+	OrderID         *uint       `gorm:"index"`
+	Status          AlertStatus `gorm:"type:varchar(20);not null;index:idx_low_stock_alerts_product_status"`
+	CreatedAt       time.Time   `gorm:"autoCreateTime;index"`
+	ResolvedAt      *time.Time
+}
+
+// crossesReorderPoint reports whether taking quantity units brought the stock from above the reorder point down to it.
+func (i Inventory) crossesReorderPoint(quantity int) bool {
+	return i.ReorderPoint != nil && i.Stock <= *i.ReorderPoint && i.Stock+quantity > *i.ReorderPoint
+}
+
+func newLowStockAlert(inventory Inventory, reference Reference) LowStockAlert {
+	alert := LowStockAlert{
+		ProductID:       inventory.ProductID,
+		Stock:           inventory.Stock,
+		ReorderPoint:    *inventory.ReorderPoint,
+		ReorderQuantity: inventory.ReorderQuantity,
+		Reason:          reference.Reason,
+		Status:          AlertOpen,
+	}
+	if reference.OrderID != 0 {
+		orderID := reference.OrderID
+		alert.OrderID = &orderID
+	}
+	return alert
+}
@@ -0,0 +1,34 @@
+package inventory
+
+type SetStockRequest struct {
+	ProductID uint   `json:"product_id,omitempty" validate:"min=1,nonnil" required:"true"`
+	Stock     int    `json:"stock" validate:"min=0"`
+	Note      string `json:"note,omitempty" validate:"max=255"`
+}
+
+type AdjustmentRequest struct {
+	ProductID uint   `json:"product_id,omitempty" validate:"min=1,nonnil" required:"true"`
+	Quantity  int    `json:"quantity,omitempty" validate:"nonzero" required:"true"`
+	Reason    Reason `json:"reason,omitempty" validate:"nonzero" required:"true"`
+	Note      string `json:"note,omitempty" validate:"max=255"`
+}
+
+type ListMovementsRequest struct {
+	ProductID uint `json:"product_id,omitempty"`
+	Limit     int  `json:"limit,omitempty"`
+	Offset    int  `json:"offset,omitempty"`
+}
+
+type ReorderRequest struct {
+	ProductID uint `json:"product_id,omitempty" validate:"min=1,nonnil" required:"true"`
+	// ReorderPoint is omitted or null to turn the low-stock alerts off.
+	ReorderPoint    *int `json:"reorder_point"`
+	ReorderQuantity int  `json:"reorder_quantity" validate:"min=0"`
+}
+
+type ListAlertsRequest struct {
+	ProductID uint        `json:"product_id,omitempty"`
+	Status    AlertStatus `json:"status,omitempty"`
+	Limit     int         `json:"limit,omitempty"`
+	Offset    int         `json:"offset,omitempty"`
+}
@@ -0,0 +1,92 @@
+package inventory
+
+import (
+	"github.com/p4xx07/order-service/app/domains/product"
+	"time"
+)
+
+type InventoryResponse struct {
+	ProductID       uint                    `json:"product_id"`
+	Stock           int                     `json:"stock"`
+	Reserved        int                     `json:"reserved"`
+	Available       int                     `json:"available"`
+	ReorderPoint    *int                    `json:"reorder_point"`
+	ReorderQuantity int                     `json:"reorder_quantity"`
+	Product         product.ProductResponse `json:"product"`
+}
+
+func (i *Inventory) ToResponse() *InventoryResponse {
+	return &InventoryResponse{
+		ProductID:       i.ProductID,
+		Stock:           i.Stock,
+		Reserved:        i.Reserved,
+		Available:       i.Available(),
+		ReorderPoint:    i.ReorderPoint,
+		ReorderQuantity: i.ReorderQuantity,
+		Product:         i.Product.ToResponse(),
+	}
+}
+
+type MovementResponse struct {
+	ID        uint      `json:"id"`
+	ProductID uint      `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	Reason    Reason    `json:"reason"`
+	OrderID   *uint     `json:"order_id,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (m *InventoryMovement) ToResponse() MovementResponse {
+	return MovementResponse{
+		ID:        m.ID,
+		ProductID: m.ProductID,
+		Quantity:  m.Quantity,
+		Reason:    m.Reason,
+		OrderID:   m.OrderID,
+		Note:      m.Note,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+type ListMovementsResponse struct {
+	Items  []MovementResponse `json:"items"`
+	Total  int64              `json:"total"`
+	Limit  int                `json:"limit"`
+	Offset int                `json:"offset"`
+}
+
+type AlertResponse struct {
+	ID              uint        `json:"id"`
+	ProductID       uint        `json:"product_id"`
+	Stock           int         `json:"stock"`
+	ReorderPoint    int         `json:"reorder_point"`
+	ReorderQuantity int         `json:"reorder_quantity"`
+	Reason          Reason      `json:"reason"`
+	OrderID         *uint       `json:"order_id,omitempty"`
+	Status          AlertStatus `json:"status"`
+	CreatedAt       time.Time   `json:"created_at"`
+	ResolvedAt      *time.Time  `json:"resolved_at,omitempty"`
+}
+
+func (a *LowStockAlert) ToResponse() AlertResponse {
+	return AlertResponse{
+		ID:              a.ID,
+		ProductID:       a.ProductID,
+		Stock:           a.Stock,
+		ReorderPoint:    a.ReorderPoint,
+		ReorderQuantity: a.ReorderQuantity,
+		Reason:          a.Reason,
+		OrderID:         a.OrderID,
+		Status:          a.Status,
+		CreatedAt:       a.CreatedAt,
+		ResolvedAt:      a.ResolvedAt,
+	}
+}
+
+type ListAlertsResponse struct {
+	Items  []AlertResponse `json:"items"`
+	Total  int64           `json:"total"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+}
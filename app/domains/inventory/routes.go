@@ -0,0 +1,15 @@
+package inventory
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetRoutes(router fiber.Router, handler IHandler) {
+	g := router.Group("inventory")
+	g.Get("/alerts", handler.ListAlerts)
+	g.Get("/:productId", handler.Get)
+	g.Put("/:productId", handler.Put)
+	g.Put("/:productId/reorder", handler.PutReorder)
+	g.Post("/:productId/adjustments", handler.PostAdjustment)
+	g.Get("/:productId/movements", handler.ListMovements)
+}
@@ -3,24 +3,47 @@ package inventory
 import (
 	"context"
 	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/db"
+	"github.com/p4xx07/order-service/internal/event"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
 )
 
 type IService interface {
 	Get(ctx context.Context, productID uint) (*Inventory, error)
 	GetMultiple(ctx context.Context, productIDs []uint) (map[uint]Inventory, error)
-	DecreaseStockBulk(ctx context.Context, updates map[uint]int) error
-	IncreaseStockBulk(ctx context.Context, updates map[uint]int) error
+	DecreaseStockBulk(ctx context.Context, updates map[uint]int, reference Reference) error
+	IncreaseStockBulk(ctx context.Context, updates map[uint]int, reference Reference) error
+	SetStock(ctx context.Context, request SetStockRequest) (*InventoryResponse, error)
+	SetReorder(ctx context.Context, request ReorderRequest) (*InventoryResponse, error)
+	Adjust(ctx context.Context, request AdjustmentRequest) (*InventoryResponse, error)
+	ListMovements(ctx context.Context, request ListMovementsRequest) (*ListMovementsResponse, error)
+	ListAlerts(ctx context.Context, request ListAlertsRequest) (*ListAlertsResponse, error)
+	Reserve(ctx context.Context, orderID uint, quantities map[uint]int) error
+	CommitReservations(ctx context.Context, orderID uint, reference Reference) error
+	ReleaseReservations(ctx context.Context, orderID uint, status ReservationStatus) error
+	WithTx(tx *gorm.DB) IService
 }
 
 type service struct {
 	configuration *configuration.Configuration
 	logger        *zap.SugaredLogger
 	store         IStore
+	bus           event.IBus
 }
 
-func NewService(store IStore, configuration *configuration.Configuration, logger *zap.SugaredLogger) IService {
-	return &service{store: store, configuration: configuration, logger: logger}
+func NewService(store IStore, bus event.IBus, configuration *configuration.Configuration, logger *zap.SugaredLogger) IService {
+	return &service{store: store, bus: bus, configuration: configuration, logger: logger}
+}
+
+// WithTx returns a copy of the service whose store runs on the given transaction.
+func (s *service) WithTx(tx *gorm.DB) IService {
+	return &service{store: s.store.WithTx(tx), bus: s.bus, configuration: s.configuration, logger: s.logger}
 }
 
 func (s *service) Get(ctx context.Context, productID uint) (*Inventory, error) {
@@ -31,10 +54,178 @@ func (s *service) GetMultiple(ctx context.Context, productIDs []uint) (map[uint]
 	return s.store.GetMultiple(ctx, productIDs)
 }
 
-func (s *service) DecreaseStockBulk(ctx context.Context, updates map[uint]int) error {
-	return s.store.DecreaseStockBulk(ctx, updates)
+func (s *service) DecreaseStockBulk(ctx context.Context, updates map[uint]int, reference Reference) error {
+	alerts, err := s.store.DecreaseStockBulk(ctx, updates, reference)
+	if err != nil {
+		return err
+	}
+
+	s.publish(ctx, decreasedEvents(updates, reference, alerts)...)
+	return nil
+}
+
+func (s *service) IncreaseStockBulk(ctx context.Context, updates map[uint]int, reference Reference) error {
+	if err := s.store.IncreaseStockBulk(ctx, updates, reference); err != nil {
+		return err
+	}
+
+	s.publish(ctx, StockIncreased{Quantities: updates, Reference: reference})
+	return nil
+}
+
+func (s *service) Reserve(ctx context.Context, orderID uint, quantities map[uint]int) error {
+	return s.store.Reserve(ctx, orderID, quantities)
+}
+
+// CommitReservations turns the active reservations of the order into a real stock decrement.
+func (s *service) CommitReservations(ctx context.Context, orderID uint, reference Reference) error {
+	quantities, alerts, err := s.store.CommitReservations(ctx, orderID, reference)
+	if err != nil {
+		return err
+	}
+
+	s.publish(ctx, decreasedEvents(quantities, reference, alerts)...)
+	return nil
+}
+
+func (s *service) ReleaseReservations(ctx context.Context, orderID uint, status ReservationStatus) error {
+	return s.store.ReleaseReservations(ctx, orderID, status)
+}
+
+func (s *service) SetStock(ctx context.Context, request SetStockRequest) (*InventoryResponse, error) {
+	reference := Reference{Reason: ReasonCorrection, Note: request.Note}
+	delta, err := s.store.SetStock(ctx, request.ProductID, request.Stock, reference)
+	if err != nil {
+		s.logger.Errorw("error setting stock", "error", err, "productID", request.ProductID)
+		return nil, err
+	}
+
+	if delta > 0 {
+		s.publish(ctx, StockIncreased{Quantities: map[uint]int{request.ProductID: delta}, Reference: reference})
+	}
+	if delta < 0 {
+		s.publish(ctx, StockDecreased{Quantities: map[uint]int{request.ProductID: -delta}, Reference: reference})
+	}
+
+	return s.getResponse(ctx, request.ProductID)
+}
+
+// SetReorder sets the reorder point and quantity of the product; alerts are raised by the next decrement that reaches the point.
+func (s *service) SetReorder(ctx context.Context, request ReorderRequest) (*InventoryResponse, error) {
+	if request.ReorderPoint != nil && *request.ReorderPoint < 0 {
+		return nil, ErrInvalidReorder
+	}
+
+	err := s.store.SetReorder(ctx, request.ProductID, request.ReorderPoint, request.ReorderQuantity)
+	if err != nil {
+		s.logger.Errorw("error setting reorder point", "error", err, "productID", request.ProductID)
+		return nil, err
+	}
+
+	return s.getResponse(ctx, request.ProductID)
+}
+
+func (s *service) Adjust(ctx context.Context, request AdjustmentRequest) (*InventoryResponse, error) {
+	if !request.Reason.IsManual() {
+		return nil, ErrInvalidReason
+	}
+	if request.Reason == ReasonRestock && request.Quantity < 0 || request.Reason == ReasonDamage && request.Quantity > 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	reference := Reference{Reason: request.Reason, Note: request.Note}
+	var err error
+	if request.Quantity > 0 {
+		err = s.IncreaseStockBulk(ctx, map[uint]int{request.ProductID: request.Quantity}, reference)
+	} else {
+		err = s.DecreaseStockBulk(ctx, map[uint]int{request.ProductID: -request.Quantity}, reference)
+	}
+	if err != nil {
+		s.logger.Errorw("error adjusting stock", "error", err, "productID", request.ProductID)
+		return nil, err
+	}
+
+	return s.getResponse(ctx, request.ProductID)
+}
+
+func (s *service) ListMovements(ctx context.Context, request ListMovementsRequest) (*ListMovementsResponse, error) {
+	if request.Limit <= 0 {
+		request.Limit = defaultListLimit
+	}
+	if request.Limit > maxListLimit {
+		request.Limit = maxListLimit
+	}
+	if request.Offset < 0 {
+		request.Offset = 0
+	}
+
+	movements, total, err := s.store.ListMovements(ctx, request)
+	if err != nil {
+		s.logger.Errorw("error listing movements", "error", err, "productID", request.ProductID)
+		return nil, err
+	}
+
+	items := make([]MovementResponse, len(movements))
+	for i := range movements {
+		items[i] = movements[i].ToResponse()
+	}
+
+	return &ListMovementsResponse{
+		Items:  items,
+		Total:  total,
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}, nil
+}
+
+func (s *service) ListAlerts(ctx context.Context, request ListAlertsRequest) (*ListAlertsResponse, error) {
+	if request.Status != "" && request.Status != AlertOpen && request.Status != AlertResolved {
+		return nil, ErrInvalidStatus
+	}
+	if request.Limit <= 0 {
+		request.Limit = defaultListLimit
+	}
+	if request.Limit > maxListLimit {
+		request.Limit = maxListLimit
+	}
+	if request.Offset < 0 {
+		request.Offset = 0
+	}
+
+	alerts, total, err := s.store.ListAlerts(ctx, request)
+	if err != nil {
+		s.logger.Errorw("error listing low-stock alerts", "error", err, "productID", request.ProductID)
+		return nil, err
+	}
+
+	items := make([]AlertResponse, len(alerts))
+	for i := range alerts {
+		items[i] = alerts[i].ToResponse()
+	}
+
+	return &ListAlertsResponse{
+		Items:  items,
+		Total:  total,
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}, nil
+}
+
+func (s *service) getResponse(ctx context.Context, productID uint) (*InventoryResponse, error) {
+	inventory, err := s.store.Get(ctx, productID)
+	if err != nil {
+		s.logger.Errorw("error getting inventory", "error", err, "productID", productID)
+		return nil, err
+	}
+	return inventory.ToResponse(), nil
 }
 
-func (s *service) IncreaseStockBulk(ctx context.Context, updates map[uint]int) error {
-	return s.store.IncreaseStockBulk(ctx, updates)
+// publish hands the events to the bus once the transaction the stock changed in, if any, committed.
+// The stock change stands either way, so a failing subscriber is only logged.
+func (s *service) publish(ctx context.Context, events ...event.Event) {
+	db.AfterCommit(ctx, func(ctx context.Context) {
+		if err := s.bus.Publish(ctx, events...); err != nil {
+			s.logger.Errorw("error publishing stock events", "error", err)
+		}
+	})
 }
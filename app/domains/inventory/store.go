@@ -2,15 +2,27 @@ package inventory
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/p4xx07/order-service/app/domains/product"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
 )
 
 type IStore interface {
 	Get(ctx context.Context, productID uint) (*Inventory, error)
 	GetMultiple(ctx context.Context, productIDs []uint) (map[uint]Inventory, error)
-	IncreaseStockBulk(ctx context.Context, updates map[uint]int) error
-	DecreaseStockBulk(ctx context.Context, updates map[uint]int) error
+	IncreaseStockBulk(ctx context.Context, updates map[uint]int, reference Reference) error
+	DecreaseStockBulk(ctx context.Context, updates map[uint]int, reference Reference) ([]LowStockAlert, error)
+	SetStock(ctx context.Context, productID uint, stock int, reference Reference) (int, error)
+	SetReorder(ctx context.Context, productID uint, reorderPoint *int, reorderQuantity int) error
+	ListMovements(ctx context.Context, request ListMovementsRequest) ([]InventoryMovement, int64, error)
+	ListAlerts(ctx context.Context, request ListAlertsRequest) ([]LowStockAlert, int64, error)
+	Reserve(ctx context.Context, orderID uint, quantities map[uint]int) error
+	CommitReservations(ctx context.Context, orderID uint, reference Reference) (map[uint]int, []LowStockAlert, error)
+	ReleaseReservations(ctx context.Context, orderID uint, status ReservationStatus) error
+	WithTx(tx *gorm.DB) IStore
 }
 
 type store struct {
@@ -21,6 +33,10 @@ func NewStore(db *gorm.DB) IStore {
 	return &store{db: db}
 }
 
+func (s *store) WithTx(tx *gorm.DB) IStore {
+	return &store{db: tx}
+}
+
 func (s *store) GetMultiple(ctx context.Context, productIDs []uint) (map[uint]Inventory, error) {
 	var inventories []Inventory
 	result := s.db.WithContext(ctx).
@@ -30,8 +46,14 @@ func (s *store) GetMultiple(ctx context.Context, productIDs []uint) (map[uint]In
 		return nil, result.Error
 	}
 
+	reserved, err := s.reservedQuantities(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	inventoryMap := make(map[uint]Inventory)
 	for i := range inventories {
+		inventories[i].Reserved = reserved[inventories[i].ProductID]
 		inventoryMap[inventories[i].ProductID] = inventories[i]
 	}
 
@@ -48,43 +70,332 @@ func (s *store) Get(ctx context.Context, productID uint) (*Inventory, error) {
 	if query.Error != nil {
 		return nil, query.Error
 	}
+
+	reserved, err := s.reservedQuantities(ctx, []uint{productID})
+	if err != nil {
+		return nil, err
+	}
+	result.Reserved = reserved[productID]
+
 	return &result, nil
 }
 
-func (s *store) IncreaseStockBulk(ctx context.Context, updates map[uint]int) error {
-	tx := s.db.WithContext(ctx).Begin()
+func (s *store) IncreaseStockBulk(ctx context.Context, updates map[uint]int, reference Reference) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		movements := make([]InventoryMovement, 0, len(updates))
+		for productID, quantity := range updates {
+			result := tx.Model(&Inventory{}).
+				Where("product_id = ?", productID).
+				Update("stock", gorm.Expr("stock + ?", quantity))
 
-	for productID, quantity := range updates {
-		result := tx.Model(&Inventory{}).
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("inventory for product %d: %w", productID, gorm.ErrRecordNotFound)
+			}
+			movements = append(movements, newMovement(productID, quantity, reference))
+		}
+		if err := s.recordMovements(tx, movements); err != nil {
+			return err
+		}
+		return s.resolveAlerts(tx, productIDs(updates))
+	})
+}
+
+// DecreaseStockBulk takes the stock and raises a low-stock alert for every product it brought down to its reorder point.
+// Units held by active reservations cannot be taken, they belong to the pending orders that reserved them.
+func (s *store) DecreaseStockBulk(ctx context.Context, updates map[uint]int, reference Reference) ([]LowStockAlert, error) {
+	var alerts []LowStockAlert
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		movements := make([]InventoryMovement, 0, len(updates))
+		for productID, quantity := range updates {
+			reserved := tx.Model(&Reservation{}).
+				Select("COALESCE(SUM(quantity), 0)").
+				Where("product_id = ? AND status = ?", productID, ReservationActive)
+			result := tx.Model(&Inventory{}).
+				Where("product_id = ? AND stock - (?) >= ?", productID, reserved, quantity).
+				Update("stock", gorm.Expr("stock - ?", quantity))
+
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("%w for product %d", ErrInsufficientStock, productID)
+			}
+			movements = append(movements, newMovement(productID, -quantity, reference))
+
+			// the update locked the row, so no other decrement can cross the reorder point at the same time
+			var inventory Inventory
+			if err := tx.Where("product_id = ?", productID).First(&inventory).Error; err != nil {
+				return err
+			}
+			if inventory.crossesReorderPoint(quantity) {
+				alerts = append(alerts, newLowStockAlert(inventory, reference))
+			}
+		}
+		if err := s.recordMovements(tx, movements); err != nil {
+			return err
+		}
+		if len(alerts) == 0 {
+			return nil
+		}
+		return tx.Create(&alerts).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+func (s *store) SetStock(ctx context.Context, productID uint, stock int, reference Reference) (int, error) {
+	var delta int
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current Inventory
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
 			Where("product_id = ?", productID).
-			Update("stock", gorm.Expr("stock + ?", quantity))
+			First(&current).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
 
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := tx.Select("id").First(&product.Product{}, productID).Error; err != nil {
+				return err
+			}
+			current = Inventory{ProductID: productID}
+			if err := tx.Omit("Product").Create(&current).Error; err != nil {
+				return err
+			}
+		}
+
+		delta = stock - current.Stock
+		if delta == 0 {
+			return nil
+		}
+
+		err = tx.Model(&Inventory{}).
+			Where("id = ?", current.ID).
+			Update("stock", stock).Error
+		if err != nil {
+			return err
+		}
+
+		if err := s.recordMovements(tx, []InventoryMovement{newMovement(productID, delta, reference)}); err != nil {
+			return err
+		}
+		return s.resolveAlerts(tx, []uint{productID})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return delta, nil
+}
+
+func (s *store) SetReorder(ctx context.Context, productID uint, reorderPoint *int, reorderQuantity int) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Inventory{}).
+			Where("product_id = ?", productID).
+			Updates(map[string]interface{}{"reorder_point": reorderPoint, "reorder_quantity": reorderQuantity})
 		if result.Error != nil {
-			tx.Rollback()
 			return result.Error
 		}
+		if result.RowsAffected == 0 {
+			// MySQL does not count the rows an update left as they were
+			if err := tx.Select("id").Where("product_id = ?", productID).First(&Inventory{}).Error; err != nil {
+				return err
+			}
+		}
+		return s.resolveAlerts(tx, []uint{productID})
+	})
+}
+
+func (s *store) ListMovements(ctx context.Context, request ListMovementsRequest) ([]InventoryMovement, int64, error) {
+	query := s.db.WithContext(ctx).Model(&InventoryMovement{})
+	if request.ProductID != 0 {
+		query = query.Where("product_id = ?", request.ProductID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var movements []InventoryMovement
+	err := query.
+		Order("id DESC").
+		Limit(request.Limit).
+		Offset(request.Offset).
+		Find(&movements).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list inventory movements: %w", err)
+	}
+
+	return movements, total, nil
+}
+
+func (s *store) ListAlerts(ctx context.Context, request ListAlertsRequest) ([]LowStockAlert, int64, error) {
+	query := s.db.WithContext(ctx).Model(&LowStockAlert{})
+	if request.ProductID != 0 {
+		query = query.Where("product_id = ?", request.ProductID)
+	}
+	if request.Status != "" {
+		query = query.Where("status = ?", request.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
 
-	return tx.Commit().Error
+	var alerts []LowStockAlert
+	err := query.
+		Order("id DESC").
+		Limit(request.Limit).
+		Offset(request.Offset).
+		Find(&alerts).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list low-stock alerts: %w", err)
+	}
+
+	return alerts, total, nil
 }
 
-func (s *store) DecreaseStockBulk(ctx context.Context, updates map[uint]int) error {
-	tx := s.db.WithContext(ctx).Begin()
+// Reserve holds the quantities for the order. The inventory rows stay locked until the reservations are written,
+// so concurrent reservations cannot together hold more than the stock.
+func (s *store) Reserve(ctx context.Context, orderID uint, quantities map[uint]int) error {
+	if len(quantities) == 0 {
+		return nil
+	}
 
-	for productID, quantity := range updates {
-		result := tx.Model(&Inventory{}).
-			Where("product_id = ? AND stock >= ?", productID, quantity).
-			Update("stock", gorm.Expr("stock - ?", quantity))
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := productIDs(quantities)
+		var inventories []Inventory
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id IN (?)", ids).
+			Order("product_id").
+			Find(&inventories).Error
+		if err != nil {
+			return err
+		}
 
-		if result.Error != nil {
-			tx.Rollback()
-			return result.Error
+		reserved, err := (&store{db: tx}).reservedQuantities(ctx, ids)
+		if err != nil {
+			return err
 		}
-		if result.RowsAffected == 0 {
-			tx.Rollback()
-			return fmt.Errorf("not enough stock for product %d", productID)
+
+		stock := make(map[uint]int, len(inventories))
+		for _, inventory := range inventories {
+			stock[inventory.ProductID] = inventory.Stock
+		}
+
+		reservations := make([]Reservation, 0, len(quantities))
+		for productID, quantity := range quantities {
+			current, ok := stock[productID]
+			if !ok {
+				return fmt.Errorf("inventory for product %d: %w", productID, gorm.ErrRecordNotFound)
+			}
+			if current-reserved[productID] < quantity {
+				return fmt.Errorf("%w for product %d", ErrInsufficientStock, productID)
+			}
+			reservations = append(reservations, Reservation{
+				OrderID:   orderID,
+				ProductID: productID,
+				Quantity:  quantity,
+				Status:    ReservationActive,
+			})
+		}
+		return tx.Create(&reservations).Error
+	})
+}
+
+func (s *store) CommitReservations(ctx context.Context, orderID uint, reference Reference) (map[uint]int, []LowStockAlert, error) {
+	updates := map[uint]int{}
+	var alerts []LowStockAlert
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var reservations []Reservation
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("order_id = ? AND status = ?", orderID, ReservationActive).
+			Find(&reservations).Error
+		if err != nil {
+			return err
+		}
+		if len(reservations) == 0 {
+			return fmt.Errorf("%w for order %d", ErrNoReservation, orderID)
 		}
+
+		for _, reservation := range reservations {
+			updates[reservation.ProductID] += reservation.Quantity
+		}
+
+		// committed first, so that the decrease does not count the units of this order as held by someone else
+		err = tx.Model(&Reservation{}).
+			Where("order_id = ? AND status = ?", orderID, ReservationActive).
+			Update("status", ReservationCommitted).Error
+		if err != nil {
+			return err
+		}
+
+		alerts, err = s.WithTx(tx).DecreaseStockBulk(ctx, updates, reference)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return updates, alerts, nil
+}
+
+func (s *store) ReleaseReservations(ctx context.Context, orderID uint, status ReservationStatus) error {
+	return s.db.WithContext(ctx).
+		Model(&Reservation{}).
+		Where("order_id = ? AND status = ?", orderID, ReservationActive).
+		Update("status", status).Error
+}
+
+func (s *store) reservedQuantities(ctx context.Context, productIDs []uint) (map[uint]int, error) {
+	var rows []struct {
+		ProductID uint
+		Reserved  int
+	}
+	err := s.db.WithContext(ctx).
+		Model(&Reservation{}).
+		Select("product_id, SUM(quantity) AS reserved").
+		Where("product_id IN (?) AND status = ?", productIDs, ReservationActive).
+		Group("product_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum reservations: %w", err)
 	}
 
-	return tx.Commit().Error
+	reserved := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		reserved[row.ProductID] = row.Reserved
+	}
+	return reserved, nil
+}
+
+// resolveAlerts resolves the open alerts of the products whose stock is back above their reorder point, or that have none anymore.
+func (s *store) resolveAlerts(tx *gorm.DB, productIDs []uint) error {
+	recovered := tx.Model(&Inventory{}).
+		Select("product_id").
+		Where("product_id IN (?) AND (reorder_point IS NULL OR stock > reorder_point)", productIDs)
+
+	return tx.Model(&LowStockAlert{}).
+		Where("status = ? AND product_id IN (?)", AlertOpen, recovered).
+		Updates(map[string]interface{}{"status": AlertResolved, "resolved_at": time.Now()}).Error
+}
+
+func (s *store) recordMovements(tx *gorm.DB, movements []InventoryMovement) error {
+	if len(movements) == 0 {
+		return nil
+	}
+	return tx.Create(&movements).Error
+}
+
+func productIDs(quantities map[uint]int) []uint {
+	ids := make([]uint, 0, len(quantities))
+	for productID := range quantities {
+		ids = append(ids, productID)
+	}
+	return ids
 }
@@ -0,0 +1,102 @@
+package order
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	http2 "github.com/p4xx07/order-service/internal/http"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+type IAdminHandler interface {
+	ListOutbox(ctx *fiber.Ctx) error
+	RetryOutbox(ctx *fiber.Ctx) error
+	StartReindex(ctx *fiber.Ctx) error
+	GetReindex(ctx *fiber.Ctx) error
+}
+
+type adminHandler struct {
+	service IAdminService
+	logger  *zap.SugaredLogger
+}
+
+func NewAdminHandler(service IAdminService, logger *zap.SugaredLogger) IAdminHandler {
+	return &adminHandler{service: service, logger: logger}
+}
+
+func (h *adminHandler) ListOutbox(c *fiber.Ctx) error {
+	request := ListOutboxRequest{
+		Status: OutboxStatus(c.Query("status")),
+		Limit:  c.QueryInt("limit"),
+		Offset: c.QueryInt("offset"),
+	}
+
+	response, err := h.service.ListOutbox(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, ErrInvalidOutboxStatus) {
+			return http2.JSON(c, http.StatusBadRequest, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *adminHandler) RetryOutbox(c *fiber.Ctx) error {
+	eventIDString := c.Params("id")
+	eventID, err := strconv.ParseUint(eventIDString, 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	err = h.service.RetryOutbox(c.Context(), uint(eventID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusAccepted, nil, nil)
+}
+
+func (h *adminHandler) StartReindex(c *fiber.Ctx) error {
+	var request ReindexRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			h.logger.Errorf("bodyRequest error %v | %v", request, err.Error())
+			return c.Status(http.StatusBadRequest).JSON(err)
+		}
+	}
+
+	response, err := h.service.StartReindex(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, ErrInvalidReindexMode) {
+			return http2.JSON(c, http.StatusBadRequest, nil, err)
+		}
+		if errors.Is(err, ErrReindexInProgress) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusAccepted, response, nil)
+}
+
+func (h *adminHandler) GetReindex(c *fiber.Ctx) error {
+	response, err := h.service.GetReindex(c.Context())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
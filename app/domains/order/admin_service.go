@@ -0,0 +1,93 @@
+package order
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/configuration"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// IAdminService gives operators a view on the order outbox and the search index, and ways to repair them.
+type IAdminService interface {
+	ListOutbox(ctx context.Context, request ListOutboxRequest) (*ListOutboxResponse, error)
+	RetryOutbox(ctx context.Context, id uint) error
+	StartReindex(ctx context.Context, request ReindexRequest) (*ReindexResponse, error)
+	GetReindex(ctx context.Context) (*ReindexResponse, error)
+}
+
+type adminService struct {
+	configuration *configuration.Configuration
+	logger        *zap.SugaredLogger
+	outboxStore   IOutboxStore
+	reindexer     IReindexer
+}
+
+func NewAdminService(outboxStore IOutboxStore, reindexer IReindexer, configuration *configuration.Configuration, logger *zap.SugaredLogger) IAdminService {
+	return &adminService{outboxStore: outboxStore, reindexer: reindexer, configuration: configuration, logger: logger}
+}
+
+func (s *adminService) ListOutbox(ctx context.Context, request ListOutboxRequest) (*ListOutboxResponse, error) {
+	if request.Status == "" {
+		request.Status = OutboxDead
+	}
+	if !request.Status.IsValid() {
+		return nil, ErrInvalidOutboxStatus
+	}
+
+	if request.Limit <= 0 {
+		request.Limit = defaultListLimit
+	}
+	if request.Limit > maxListLimit {
+		request.Limit = maxListLimit
+	}
+	if request.Offset < 0 {
+		request.Offset = 0
+	}
+
+	events, total, err := s.outboxStore.List(ctx, request.Status, request.Limit, request.Offset)
+	if err != nil {
+		s.logger.Errorw("error listing outbox events", "error", err, "status", request.Status)
+		return nil, err
+	}
+
+	items := make([]OutboxEventResponse, len(events))
+	for i := range events {
+		items[i] = events[i].ToResponse()
+	}
+
+	return &ListOutboxResponse{
+		Items:  items,
+		Total:  total,
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}, nil
+}
+
+func (s *adminService) RetryOutbox(ctx context.Context, id uint) error {
+	if err := s.outboxStore.Retry(ctx, id); err != nil {
+		s.logger.Errorw("error retrying outbox event", "error", err, "id", id)
+		return err
+	}
+	return nil
+}
+
+func (s *adminService) StartReindex(ctx context.Context, request ReindexRequest) (*ReindexResponse, error) {
+	if request.Mode == "" {
+		request.Mode = ReindexFull
+	}
+
+	progress, err := s.reindexer.Start(request.Mode)
+	if err != nil {
+		s.logger.Errorw("error starting reindex", "error", err, "mode", request.Mode)
+		return nil, err
+	}
+	return progress.ToResponse(), nil
+}
+
+func (s *adminService) GetReindex(ctx context.Context) (*ReindexResponse, error) {
+	progress := s.reindexer.Progress()
+	if progress == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return progress.ToResponse(), nil
+}
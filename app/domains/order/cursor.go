@@ -0,0 +1,97 @@
+package order
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// Cursor is a position in the orders sorted by creation time, ties broken by id.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+func (o *Order) cursor() Cursor {
+	return Cursor{CreatedAt: o.CreatedAt, ID: o.ID}
+}
+
+func (o *OrderResponse) cursor() Cursor {
+	return Cursor{CreatedAt: o.CreatedAt, ID: o.ID}
+}
+
+// listCursor is the position of a page in an order listing.
+// Listings sorted by creation time are paged by keyset, after or before an order, so that orders inserted
+// while a client pages through them are neither skipped nor repeated. Any other listing is paged by offset.
+// It is handed to clients as an opaque token so that its contents can change without breaking them.
+type listCursor struct {
+	Offset    int64 `json:"o,omitempty"`
+	CreatedAt int64 `json:"c,omitempty"`
+	ID        uint  `json:"i,omitempty"`
+	Before    bool  `json:"b,omitempty"`
+}
+
+func keysetCursor(position Cursor, before bool) listCursor {
+	return listCursor{CreatedAt: position.CreatedAt.UnixMilli(), ID: position.ID, Before: before}
+}
+
+func (c listCursor) isKeyset() bool {
+	return c.ID != 0
+}
+
+func (c listCursor) position() *Cursor {
+	return &Cursor{CreatedAt: time.UnixMilli(c.CreatedAt), ID: c.ID}
+}
+
+func (c listCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(token string) (listCursor, error) {
+	var cursor listCursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Offset < 0 {
+		return cursor, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// setOffsetCursors points the response at the pages around the current one by offset.
+func (r *ListOrdersResponse) setOffsetCursors() {
+	limit, offset := int64(r.Limit), int64(r.Offset)
+	if offset+limit < r.Total {
+		r.NextCursor = listCursor{Offset: offset + limit}.encode()
+	}
+	if offset > 0 {
+		r.PrevCursor = listCursor{Offset: max(offset-limit, 0)}.encode()
+	}
+}
+
+// setKeysetCursors trims the one extra order that was fetched to tell whether there is a further page
+// in the direction of travel, and points the response at the pages around the current one by keyset.
+func (r *ListOrdersResponse) setKeysetCursors(request ListRequest, limit int) {
+	more := len(r.Items) > limit
+	if more && request.Before != nil {
+		r.Items = r.Items[len(r.Items)-limit:]
+	} else if more {
+		r.Items = r.Items[:limit]
+	}
+	r.Limit = limit
+
+	if len(r.Items) == 0 {
+		return
+	}
+	first, last := r.Items[0].cursor(), r.Items[len(r.Items)-1].cursor()
+
+	backward := request.Before != nil
+	if more || backward {
+		r.NextCursor = keysetCursor(last, false).encode()
+	}
+	if more && backward || request.After != nil {
+		r.PrevCursor = keysetCursor(first, true).encode()
+	}
+}
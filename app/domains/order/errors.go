@@ -1,8 +1,38 @@
 package order
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrNoStockAvailable      error = errors.New("no stock available")
 	ErrStockUpdateInProgress       = errors.New("stock update in progress")
+	ErrUserNotFound                = errors.New("user not found")
+	ErrAddressNotFound             = errors.New("shipping address not found")
+	ErrProductNotAvailable         = errors.New("product not available")
+	ErrDuplicateProduct            = errors.New("product is listed on more than one item")
+	ErrInvalidStatus               = errors.New("invalid order status")
+	ErrInvalidTransition           = errors.New("invalid order status transition")
+	ErrOrderNotEditable            = errors.New("order can no longer be modified")
+	ErrReservationExpired          = errors.New("order reservation expired")
+	ErrOrderChanged                = errors.New("order was changed concurrently")
+	ErrInvalidFilter               = errors.New("invalid order filter")
+	ErrInvalidCursor               = errors.New("invalid order cursor")
+	ErrInvalidOutboxStatus         = errors.New("invalid outbox status")
+	ErrInvalidReindexMode          = errors.New("invalid reindex mode")
+	ErrReindexInProgress           = errors.New("reindex already in progress")
 )
+
+type TransitionError struct {
+	From Status
+	To   Status
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot transition order from %s to %s", e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
@@ -0,0 +1,105 @@
+package order
+
+import (
+	"fmt"
+	"github.com/p4xx07/order-service/internal/event"
+)
+
+const (
+	EventOrderCreated       event.Name = "order.created"
+	EventOrderUpdated       event.Name = "order.updated"
+	EventOrderDeleted       event.Name = "order.deleted"
+	EventOrderStatusChanged event.Name = "order.status_changed"
+)
+
+// The order events are published by the outbox relay once the change committed.
+// They carry the latest state of the order rather than the one at the time of the change,
+// and the ID of the outbox event, which stays the same when a failed delivery is published again.
+type OrderCreated struct {
+	OutboxID uint
+	Order    Order
+}
+
+func (OrderCreated) EventName() event.Name {
+	return EventOrderCreated
+}
+
+func (e OrderCreated) EventID() string {
+	return eventID(e, e.OutboxID)
+}
+
+func (e OrderCreated) Data() interface{} {
+	return e.Order.ToResponse()
+}
+
+type OrderUpdated struct {
+	OutboxID uint
+	Order    Order
+}
+
+func (OrderUpdated) EventName() event.Name {
+	return EventOrderUpdated
+}
+
+func (e OrderUpdated) EventID() string {
+	return eventID(e, e.OutboxID)
+}
+
+func (e OrderUpdated) Data() interface{} {
+	return e.Order.ToResponse()
+}
+
+type OrderDeleted struct {
+	OutboxID uint
+	OrderID  uint
+}
+
+func (OrderDeleted) EventName() event.Name {
+	return EventOrderDeleted
+}
+
+func (e OrderDeleted) EventID() string {
+	return eventID(e, e.OutboxID)
+}
+
+func (e OrderDeleted) Data() interface{} {
+	return OrderDeletedData{OrderID: e.OrderID}
+}
+
+// OrderStatusChanged is published along with OrderUpdated when the update moved the order from From to To.
+type OrderStatusChanged struct {
+	OutboxID uint
+	Order    Order
+	From     Status
+	To       Status
+}
+
+func (OrderStatusChanged) EventName() event.Name {
+	return EventOrderStatusChanged
+}
+
+func (e OrderStatusChanged) EventID() string {
+	return eventID(e, e.OutboxID)
+}
+
+func (e OrderStatusChanged) Data() interface{} {
+	return OrderStatusChangedData{From: e.From, To: e.To, Order: e.Order.ToResponse()}
+}
+
+// OrderDeletedData and OrderStatusChangedData are what the order events look like to other services.
+// The other order events are sent as the order itself.
+type OrderDeletedData struct {
+	OrderID uint `json:"order_id"`
+}
+
+type OrderStatusChangedData struct {
+	From  Status         `json:"from"`
+	To    Status         `json:"to"`
+	Order *OrderResponse `json:"order"`
+}
+
+// eventID identifies the change, e.g. order.updated.42. It stays the same when the outbox event is published again,
+// so other services can skip the events they already handled.
+func eventID(e event.Event, outboxID uint) string {
+	return fmt.Sprintf("%s.%d", e.EventName(), outboxID)
+}
@@ -2,22 +2,31 @@ package order
 
 import (
 	"errors"
+	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/p4xx07/order-service/app/domains/currency"
+	"github.com/p4xx07/order-service/app/domains/promotion"
 	http2 "github.com/p4xx07/order-service/internal/http"
+	"github.com/p4xx07/order-service/internal/money"
 	"go.uber.org/zap"
 	"gopkg.in/validator.v2"
 	"gorm.io/gorm"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 )
 
+const HeaderSearchBackend = "X-Search-Backend"
+
 type IHandler interface {
 	List(ctx *fiber.Ctx) error
 	Post(ctx *fiber.Ctx) error
 	Get(ctx *fiber.Ctx) error
+	ListByUser(ctx *fiber.Ctx) error
 	Put(ctx *fiber.Ctx) error
 	Delete(ctx *fiber.Ctx) error
+	Transition(ctx *fiber.Ctx) error
 }
 
 type handler struct {
@@ -42,9 +51,24 @@ func (h *handler) Post(c *fiber.Ctx) error {
 
 	response, err := h.service.Create(c.Context(), request)
 	if err != nil {
+		if errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrAddressNotFound) {
+			return http2.JSON(c, http.StatusUnprocessableEntity, nil, err)
+		}
 		if errors.Is(err, ErrNoStockAvailable) {
 			return http2.JSON(c, http.StatusInternalServerError, nil, err)
 		}
+		if errors.Is(err, ErrProductNotAvailable) {
+			return http2.JSON(c, http.StatusUnprocessableEntity, nil, err)
+		}
+		if errors.Is(err, currency.ErrUnsupportedCurrency) || errors.Is(err, ErrDuplicateProduct) {
+			return http2.JSON(c, http.StatusBadRequest, nil, err)
+		}
+		if isPromotionError(err) {
+			return http2.JSON(c, http.StatusUnprocessableEntity, nil, err)
+		}
+		if errors.Is(err, ErrStockUpdateInProgress) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
 
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return http2.JSON(c, http.StatusNotFound, nil, err)
@@ -58,56 +82,113 @@ func (h *handler) Post(c *fiber.Ctx) error {
 }
 
 func (h *handler) List(c *fiber.Ctx) error {
-	input := c.Query("input")
-	startDateStr := c.Query("start_date")
-	endDateStr := c.Query("end_date")
-	limit := c.Query("limit")
-	offset := c.Query("offset")
+	request, err := parseListRequest(c)
+	if err != nil {
+		h.logger.Errorw("invalid list request", "error", err)
+		return http2.JSON(c, http.StatusBadRequest, nil, err)
+	}
 
-	var startDate, endDate time.Time
-	var err error
+	if err := request.Validate(); err != nil {
+		return http2.JSON(c, http.StatusBadRequest, nil, err)
+	}
 
-	if startDateStr != "" {
-		startDate, err = time.Parse(time.RFC3339, startDateStr)
+	response, backend, err := h.service.List(c.Context(), request)
+	if errors.Is(err, ErrInvalidCursor) {
+		return http2.JSON(c, http.StatusBadRequest, nil, err)
+	}
+	c.Set(HeaderSearchBackend, string(backend))
+	if err != nil {
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func parseListRequest(c *fiber.Ctx) (ListRequest, error) {
+	request := ListRequest{
+		Input:    c.Query("input"),
+		Status:   Status(c.Query("status")),
+		Currency: money.Currency(c.Query("currency")),
+		Cursor:   c.Query("cursor"),
+	}
+
+	limit, err := strconv.ParseInt(c.Query("limit"), 10, 64)
+	if err == nil {
+		request.Limit = limit
+	}
+
+	offset, err := strconv.ParseInt(c.Query("offset"), 10, 64)
+	if err == nil {
+		request.Offset = offset
+	}
+
+	if startDate := c.Query("start_date"); startDate != "" {
+		parsed, err := time.Parse(time.RFC3339, startDate)
 		if err != nil {
-			h.logger.Error("Invalid start_date format: ", err)
-			return http2.JSON(c, http.StatusBadRequest, nil, err)
+			return request, fmt.Errorf("%w: start_date: %w", ErrInvalidFilter, err)
 		}
+		request.StartDate = &parsed
 	}
 
-	if endDateStr != "" {
-		endDate, err = time.Parse(time.RFC3339, endDateStr)
+	if endDate := c.Query("end_date"); endDate != "" {
+		parsed, err := time.Parse(time.RFC3339, endDate)
 		if err != nil {
-			h.logger.Error("Invalid end_date format: ", err)
-			return http2.JSON(c, http.StatusBadRequest, nil, err)
+			return request, fmt.Errorf("%w: end_date: %w", ErrInvalidFilter, err)
 		}
+		request.EndDate = &parsed
 	}
 
-	limitInt, err := strconv.ParseInt(limit, 10, 64)
-	if err != nil {
-		limitInt = 0
+	if userID := c.Query("user_id"); userID != "" {
+		parsed, err := strconv.ParseUint(userID, 10, 64)
+		if err != nil {
+			return request, fmt.Errorf("%w: user_id: %w", ErrInvalidFilter, err)
+		}
+		request.UserID = uint(parsed)
 	}
 
-	offsetInt, err := strconv.ParseInt(offset, 10, 64)
-	if err != nil {
-		offsetInt = 0
+	if productIDs := c.Query("product_ids"); productIDs != "" {
+		for _, productID := range strings.Split(productIDs, ",") {
+			parsed, err := strconv.ParseUint(strings.TrimSpace(productID), 10, 64)
+			if err != nil {
+				return request, fmt.Errorf("%w: product_ids: %w", ErrInvalidFilter, err)
+			}
+			request.ProductIDs = append(request.ProductIDs, uint(parsed))
+		}
 	}
 
-	request := ListRequest{
-		Input:     input,
-		StartDate: &startDate,
-		EndDate:   &endDate,
-		Limit:     limitInt,
-		Offset:    offsetInt,
+	for name, target := range map[string]**money.Amount{"min_total": &request.MinTotal, "max_total": &request.MaxTotal} {
+		if value := c.Query(name); value != "" {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return request, fmt.Errorf("%w: %s: %w", ErrInvalidFilter, name, err)
+			}
+			amount := money.Amount(parsed)
+			*target = &amount
+		}
 	}
 
-	response, err := h.service.List(c.Context(), request)
-	if err != nil {
-		h.logger.Error(err)
-		return c.SendStatus(http.StatusInternalServerError)
+	for name, target := range map[string]**int{"min_items": &request.MinItems, "max_items": &request.MaxItems} {
+		if value := c.Query(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return request, fmt.Errorf("%w: %s: %w", ErrInvalidFilter, name, err)
+			}
+			*target = &parsed
+		}
 	}
 
-	return http2.JSON(c, http.StatusOK, response, nil)
+	// sort is a field optionally followed by a direction, e.g. total:desc, or a direction alone to sort by creation time
+	if sort := c.Query("sort"); sort != "" {
+		field, direction, _ := strings.Cut(sort, ":")
+		if SortDirection(field).IsValid() {
+			field, direction = "", field
+		}
+		request.SortBy = SortField(field)
+		request.SortDirection = SortDirection(direction)
+	}
+
+	return request, nil
 }
 
 func (h *handler) Get(c *fiber.Ctx) error {
@@ -129,6 +210,31 @@ func (h *handler) Get(c *fiber.Ctx) error {
 	return http2.JSON(c, http.StatusOK, response, err)
 }
 
+func (h *handler) ListByUser(c *fiber.Ctx) error {
+	userIDString := c.Params("id")
+	userID, err := strconv.ParseUint(userIDString, 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	request := ListByUserRequest{
+		UserID: uint(userID),
+		Limit:  c.QueryInt("limit"),
+		Offset: c.QueryInt("offset"),
+	}
+
+	response, err := h.service.ListByUser(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
 func (h *handler) Put(c *fiber.Ctx) error {
 	orderIDString := c.Params("id")
 	orderID, err := strconv.ParseUint(orderIDString, 10, 64)
@@ -149,9 +255,21 @@ func (h *handler) Put(c *fiber.Ctx) error {
 
 	err = h.service.Update(c.Context(), request)
 	if err != nil {
+		if errors.Is(err, ErrDuplicateProduct) {
+			return http2.JSON(c, http.StatusBadRequest, nil, err)
+		}
 		if errors.Is(err, ErrNoStockAvailable) {
 			return http2.JSON(c, http.StatusInternalServerError, nil, err)
 		}
+		if errors.Is(err, ErrProductNotAvailable) {
+			return http2.JSON(c, http.StatusUnprocessableEntity, nil, err)
+		}
+		if isPromotionError(err) {
+			return http2.JSON(c, http.StatusUnprocessableEntity, nil, err)
+		}
+		if errors.Is(err, ErrOrderNotEditable) || errors.Is(err, ErrReservationExpired) || errors.Is(err, ErrStockUpdateInProgress) || errors.Is(err, ErrOrderChanged) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return http2.JSON(c, http.StatusNotFound, nil, err)
 		}
@@ -163,6 +281,14 @@ func (h *handler) Put(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusOK)
 }
 
+// isPromotionError reports whether a coupon code of the request could not be applied.
+func isPromotionError(err error) bool {
+	return errors.Is(err, promotion.ErrPromotionNotFound) ||
+		errors.Is(err, promotion.ErrPromotionNotActive) ||
+		errors.Is(err, promotion.ErrPromotionExhausted) ||
+		errors.Is(err, promotion.ErrPromotionNotApplicable)
+}
+
 func (h *handler) Delete(c *fiber.Ctx) error {
 	orderIDString := c.Params("id")
 	orderID, err := strconv.ParseUint(orderIDString, 10, 64)
@@ -175,6 +301,9 @@ func (h *handler) Delete(c *fiber.Ctx) error {
 		if errors.Is(err, ErrNoStockAvailable) {
 			return http2.JSON(c, http.StatusInternalServerError, nil, err)
 		}
+		if errors.Is(err, ErrOrderNotEditable) || errors.Is(err, ErrStockUpdateInProgress) || errors.Is(err, ErrOrderChanged) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return http2.JSON(c, http.StatusNotFound, nil, err)
 		}
@@ -185,3 +314,41 @@ func (h *handler) Delete(c *fiber.Ctx) error {
 
 	return c.SendStatus(http.StatusOK)
 }
+
+func (h *handler) Transition(c *fiber.Ctx) error {
+	orderIDString := c.Params("id")
+	orderID, err := strconv.ParseUint(orderIDString, 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	var request TransitionRequest
+	if err := c.BodyParser(&request); err != nil {
+		h.logger.Errorf("bodyRequest error: %v", err.Error())
+		return c.Status(http.StatusBadRequest).JSON("Invalid request body")
+	}
+	request.ID = uint(orderID)
+	request.Client = true
+
+	if errs := validator.Validate(request); errs != nil {
+		return c.Status(http.StatusBadRequest).JSON(errs)
+	}
+
+	response, err := h.service.Transition(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, ErrInvalidStatus) {
+			return http2.JSON(c, http.StatusBadRequest, nil, err)
+		}
+		if errors.Is(err, ErrInvalidTransition) || errors.Is(err, ErrReservationExpired) || errors.Is(err, ErrNoStockAvailable) || errors.Is(err, ErrStockUpdateInProgress) || errors.Is(err, ErrOrderChanged) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
@@ -0,0 +1,35 @@
+package order
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/internal/event"
+)
+
+// SubscriberSearchIndex is the search indexer as subscriber of the order events.
+const SubscriberSearchIndex event.Subscriber = "search_index"
+
+// ISearchIndexer keeps the orders search index in line with the order events.
+// It subscribes synchronously, so a failed index update is retried through the outbox.
+type ISearchIndexer interface {
+	Subscribe(bus event.IBus)
+}
+
+type searchIndexer struct {
+	meilisearchService IMeilisearchService
+}
+
+func NewSearchIndexer(meilisearchService IMeilisearchService) ISearchIndexer {
+	return &searchIndexer{meilisearchService: meilisearchService}
+}
+
+func (i *searchIndexer) Subscribe(bus event.IBus) {
+	bus.Subscribe(SubscriberSearchIndex, EventOrderCreated, func(ctx context.Context, e event.Event) error {
+		return i.meilisearchService.Add(e.(OrderCreated).Order)
+	})
+	bus.Subscribe(SubscriberSearchIndex, EventOrderUpdated, func(ctx context.Context, e event.Event) error {
+		return i.meilisearchService.Update(e.(OrderUpdated).Order)
+	})
+	bus.Subscribe(SubscriberSearchIndex, EventOrderDeleted, func(ctx context.Context, e event.Event) error {
+		return i.meilisearchService.Delete(e.(OrderDeleted).OrderID)
+	})
+}
@@ -2,65 +2,64 @@ package order
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/meilisearch/meilisearch-go"
 	"github.com/p4xx07/order-service/configuration"
 	"go.uber.org/zap"
-	"log"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ordersIndex      = "orders"
+	taskPollInterval = 100 * time.Millisecond
 )
 
 type IMeilisearchService interface {
-	List(ctx context.Context, request ListRequest) (interface{}, error)
+	List(ctx context.Context, request ListRequest) (*ListOrdersResponse, error)
 	Add(orders Order) error
 	Update(orders Order) error
 	Delete(orderIDs ...uint) error
+	IsEmpty(ctx context.Context) (bool, error)
+	PrepareIndex(ctx context.Context, uid string) error
+	IndexOrders(ctx context.Context, uid string, orders []Order) error
+	PromoteIndex(ctx context.Context, uid string) error
 }
 
 type meilisearchService struct {
 	configuration     *configuration.Configuration
 	logger            *zap.SugaredLogger
 	meilisearchClient meilisearch.ServiceManager
-	store             IStore
 }
 
-func NewMeilisearchService(meilisearchClient meilisearch.ServiceManager, configuration *configuration.Configuration, logger *zap.SugaredLogger, store IStore) IMeilisearchService {
-	s := &meilisearchService{meilisearchClient: meilisearchClient, configuration: configuration, logger: logger, store: store}
-	go s.syncOrdersToMeili()
-	return s
+func NewMeilisearchService(meilisearchClient meilisearch.ServiceManager, configuration *configuration.Configuration, logger *zap.SugaredLogger) IMeilisearchService {
+	return &meilisearchService{meilisearchClient: meilisearchClient, configuration: configuration, logger: logger}
 }
 
-func (s *meilisearchService) List(ctx context.Context, request ListRequest) (interface{}, error) {
-	index, err := s.meilisearchClient.GetIndexWithContext(ctx, "orders")
+func (s *meilisearchService) List(ctx context.Context, request ListRequest) (*ListOrdersResponse, error) {
+	index, err := s.meilisearchClient.GetIndexWithContext(ctx, ordersIndex)
 	if err != nil {
 		s.logger.Errorw("error getting index", "error", err)
 		return nil, err
 	}
 
-	attributes := s.getAttributes()
-	_, err = index.UpdateFilterableAttributes(&attributes)
+	_, err = index.UpdateSettingsWithContext(ctx, s.getSettings())
 	if err != nil {
 		s.logger.Errorw("error while updating meilisearch", "error", err)
 		return nil, err
 	}
 
-	var filter string
-	if request.StartDate != nil && request.EndDate != nil {
-		startTimestamp := request.StartDate.UnixMilli()
-		endTimestamp := request.EndDate.UnixMilli()
-		filter = fmt.Sprintf("CreatedAtTimestamp >= %d AND CreatedAtTimestamp <= %d", startTimestamp, endTimestamp)
-	} else if request.StartDate != nil {
-		startTimestamp := request.StartDate.UnixMilli()
-		filter = fmt.Sprintf("CreatedAtTimestamp >= %d", startTimestamp)
-	} else if request.EndDate != nil {
-		endTimestamp := request.EndDate.UnixMilli()
-		filter = fmt.Sprintf("CreatedAtTimestamp <= %d", endTimestamp)
-	}
-
+	filter := s.getFilter(request)
 	query := meilisearch.SearchRequest{
-		Filter: filter,
+		Filter: s.withKeyset(filter, request),
+		Facets: s.getFacets(),
 		Limit:  request.Limit,
 		Offset: request.Offset,
+		Sort:   s.getSort(request),
 	}
 
 	res, err := index.SearchWithContext(ctx, request.Input, &query)
@@ -69,7 +68,48 @@ func (s *meilisearchService) List(ctx context.Context, request ListRequest) (int
 		return nil, err
 	}
 
-	return res.Hits, nil
+	// the total and the facets count every matching order, not only those past the cursor
+	counts := res
+	if request.position() != nil {
+		counts, err = index.SearchWithContext(ctx, request.Input, &meilisearch.SearchRequest{
+			Filter:               filter,
+			Facets:               s.getFacets(),
+			Limit:                1,
+			AttributesToRetrieve: []string{"ID"},
+		})
+		if err != nil {
+			s.logger.Errorw("error while searching meilisearch", "error", err)
+			return nil, err
+		}
+	}
+
+	var documents []OrderMeilisearch
+	if err := remarshal(res.Hits, &documents); err != nil {
+		s.logger.Errorw("error decoding meilisearch hits", "error", err)
+		return nil, err
+	}
+
+	facets, err := s.toFacets(counts.FacetDistribution)
+	if err != nil {
+		s.logger.Errorw("error decoding meilisearch facets", "error", err)
+		return nil, err
+	}
+
+	items := make([]OrderResponse, len(documents))
+	for i := range documents {
+		items[i] = *documents[i].Order.ToResponse()
+	}
+	if request.Before != nil {
+		slices.Reverse(items)
+	}
+
+	return &ListOrdersResponse{
+		Items:  items,
+		Total:  counts.EstimatedTotalHits,
+		Limit:  int(request.Limit),
+		Offset: int(request.Offset),
+		Facets: facets,
+	}, nil
 }
 
 func (s *meilisearchService) Delete(orderIDs ...uint) error {
@@ -82,7 +122,7 @@ func (s *meilisearchService) Delete(orderIDs ...uint) error {
 		identifiers[i] = strconv.FormatUint(uint64(id), 10)
 	}
 
-	index := s.meilisearchClient.Index("orders")
+	index := s.meilisearchClient.Index(ordersIndex)
 	_, err := index.DeleteDocuments(identifiers)
 	if err != nil {
 		s.logger.Errorw("error while updating meilisearch", "error", err)
@@ -93,7 +133,7 @@ func (s *meilisearchService) Delete(orderIDs ...uint) error {
 }
 
 func (s *meilisearchService) Add(order Order) error {
-	index := s.meilisearchClient.Index("orders")
+	index := s.meilisearchClient.Index(ordersIndex)
 	_, err := index.AddDocuments(order.toDocument(), "ID")
 	if err != nil {
 		s.logger.Errorw("error while updating meilisearch", "error", err)
@@ -104,7 +144,7 @@ func (s *meilisearchService) Add(order Order) error {
 }
 
 func (s *meilisearchService) Update(order Order) error {
-	index := s.meilisearchClient.Index("orders")
+	index := s.meilisearchClient.Index(ordersIndex)
 	_, err := index.UpdateDocuments(order.toDocument(), "ID")
 	if err != nil {
 		s.logger.Errorw("error while updating meilisearch", "error", err)
@@ -114,54 +154,214 @@ func (s *meilisearchService) Update(order Order) error {
 	return nil
 }
 
-func (s *meilisearchService) syncOrdersToMeili() {
-	index := s.meilisearchClient.Index("orders")
-	attributes := s.getAttributes()
-	_, err := index.UpdateFilterableAttributes(&attributes)
+func (s *meilisearchService) IsEmpty(ctx context.Context) (bool, error) {
+	stats, err := s.meilisearchClient.Index(ordersIndex).GetStatsWithContext(ctx)
+	if isMeilisearchError(err, "index_not_found") {
+		return true, nil
+	}
 	if err != nil {
-		s.logger.Errorw("error while updating meilisearch", "error", err)
-		return
+		return false, err
 	}
+	return stats.NumberOfDocuments == 0, nil
+}
 
-	_, err = index.UpdateSortableAttributes(&attributes)
+// PrepareIndex (re)creates uid as an empty index configured like the orders index.
+func (s *meilisearchService) PrepareIndex(ctx context.Context, uid string) error {
+	task, err := s.meilisearchClient.DeleteIndexWithContext(ctx, uid)
 	if err != nil {
-		s.logger.Errorw("error while updating meilisearch", "error", err)
-		return
-	}
-
-	stats, err := index.GetStats()
-	if err != nil || stats.NumberOfDocuments == 0 {
-		log.Println("Meilisearch empty, starting sync...")
-
-		batchSize := 1000
-		offset := 0
-		for {
-			orders, err := s.store.Fetch(batchSize, offset)
-			if err != nil {
-				s.logger.Errorw("error while fetching meilisearch orders", "error", err)
-				return
-			}
-
-			if len(orders) == 0 {
-				break
-			}
-
-			documents := make([]OrderMeilisearch, len(orders))
-			for i, order := range orders {
-				documents[i] = order.toDocument()
-			}
-
-			_, err = index.AddDocuments(documents, "ID")
-			if err != nil {
-				s.logger.Error("Error syncing to Meilisearch:", err)
-				return
-			}
-
-			offset += batchSize
+		return err
+	}
+	if err := s.waitForTask(ctx, task); err != nil && !isMeilisearchError(err, "index_not_found") {
+		return err
+	}
+
+	task, err = s.meilisearchClient.CreateIndexWithContext(ctx, &meilisearch.IndexConfig{Uid: uid, PrimaryKey: "ID"})
+	if err != nil {
+		return err
+	}
+	if err := s.waitForTask(ctx, task); err != nil {
+		return err
+	}
+
+	task, err = s.meilisearchClient.Index(uid).UpdateSettingsWithContext(ctx, s.getSettings())
+	if err != nil {
+		return err
+	}
+	return s.waitForTask(ctx, task)
+}
+
+func (s *meilisearchService) IndexOrders(ctx context.Context, uid string, orders []Order) error {
+	documents := make([]OrderMeilisearch, len(orders))
+	for i, order := range orders {
+		documents[i] = order.toDocument()
+	}
+
+	task, err := s.meilisearchClient.Index(uid).UpdateDocumentsWithContext(ctx, documents, "ID")
+	if err != nil {
+		return err
+	}
+	return s.waitForTask(ctx, task)
+}
+
+// PromoteIndex atomically swaps uid with the orders index and drops the previous orders documents.
+func (s *meilisearchService) PromoteIndex(ctx context.Context, uid string) error {
+	task, err := s.meilisearchClient.CreateIndexWithContext(ctx, &meilisearch.IndexConfig{Uid: ordersIndex, PrimaryKey: "ID"})
+	if err != nil {
+		return err
+	}
+	if err := s.waitForTask(ctx, task); err != nil && !isMeilisearchError(err, "index_already_exists") {
+		return err
+	}
+
+	task, err = s.meilisearchClient.SwapIndexesWithContext(ctx, []*meilisearch.SwapIndexesParams{{Indexes: []string{ordersIndex, uid}}})
+	if err != nil {
+		return err
+	}
+	if err := s.waitForTask(ctx, task); err != nil {
+		return err
+	}
+
+	task, err = s.meilisearchClient.DeleteIndexWithContext(ctx, uid)
+	if err != nil {
+		return err
+	}
+	return s.waitForTask(ctx, task)
+}
+
+func (s *meilisearchService) waitForTask(ctx context.Context, info *meilisearch.TaskInfo) error {
+	task, err := s.meilisearchClient.WaitForTaskWithContext(ctx, info.TaskUID, taskPollInterval)
+	if err != nil {
+		return err
+	}
+	if task.Status == meilisearch.TaskStatusFailed {
+		return &meilisearch.Error{MeilisearchApiError: task.Error}
+	}
+	return nil
+}
+
+func isMeilisearchError(err error, code string) bool {
+	var meilisearchErr *meilisearch.Error
+	return errors.As(err, &meilisearchErr) && meilisearchErr.MeilisearchApiError.Code == code
+}
+
+func (s *meilisearchService) getSettings() *meilisearch.Settings {
+	return &meilisearch.Settings{
+		FilterableAttributes: []string{"ID", "CreatedAtTimestamp", "UserID", "Status", "Currency", "ProductIDs", "Total", "ItemCount", "Items.Product.Name", "Items.Product.Description"},
+		SortableAttributes:   []string{"ID", "CreatedAtTimestamp", "Total", "Status"},
+	}
+}
+
+func (s *meilisearchService) getFacets() []string {
+	return []string{"Status", "ProductIDs"}
+}
+
+func (s *meilisearchService) toFacets(distribution interface{}) (*OrderFacets, error) {
+	var counts map[string]map[string]int64
+	if err := remarshal(distribution, &counts); err != nil {
+		return nil, err
+	}
+
+	facets := &OrderFacets{Status: map[Status]int64{}, Product: map[uint]int64{}}
+	for status, count := range counts["Status"] {
+		facets.Status[Status(status)] = count
+	}
+	for productID, count := range counts["ProductIDs"] {
+		id, err := strconv.ParseUint(productID, 10, 64)
+		if err != nil {
+			return nil, err
 		}
+		facets.Product[uint(id)] = count
 	}
+	return facets, nil
 }
 
-func (s *meilisearchService) getAttributes() []string {
-	return []string{"CreatedAtTimestamp", "Items.Product.Name", "Items.Product.Description"}
+// remarshal converts the loosely typed values returned by meilisearch into our own types.
+func remarshal(from interface{}, to interface{}) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
+
+func (s *meilisearchService) getFilter(request ListRequest) string {
+	var filters []string
+	if request.StartDate != nil {
+		filters = append(filters, fmt.Sprintf("CreatedAtTimestamp >= %d", request.StartDate.UnixMilli()))
+	}
+	if request.EndDate != nil {
+		filters = append(filters, fmt.Sprintf("CreatedAtTimestamp <= %d", request.EndDate.UnixMilli()))
+	}
+	if request.UserID != 0 {
+		filters = append(filters, fmt.Sprintf("UserID = %d", request.UserID))
+	}
+	if request.Status != "" {
+		filters = append(filters, fmt.Sprintf("Status = %q", request.Status))
+	}
+	if request.Currency != "" {
+		filters = append(filters, fmt.Sprintf("Currency = %q", request.Currency))
+	}
+	if len(request.ProductIDs) > 0 {
+		productIDs := make([]string, len(request.ProductIDs))
+		for i, productID := range request.ProductIDs {
+			productIDs[i] = strconv.FormatUint(uint64(productID), 10)
+		}
+		filters = append(filters, fmt.Sprintf("ProductIDs IN [%s]", strings.Join(productIDs, ", ")))
+	}
+	if request.MinTotal != nil {
+		filters = append(filters, fmt.Sprintf("Total >= %d", *request.MinTotal))
+	}
+	if request.MaxTotal != nil {
+		filters = append(filters, fmt.Sprintf("Total <= %d", *request.MaxTotal))
+	}
+	if request.MinItems != nil {
+		filters = append(filters, fmt.Sprintf("ItemCount >= %d", *request.MinItems))
+	}
+	if request.MaxItems != nil {
+		filters = append(filters, fmt.Sprintf("ItemCount <= %d", *request.MaxItems))
+	}
+	return strings.Join(filters, " AND ")
+}
+
+// withKeyset narrows the filter to the orders past the cursor in the listing order.
+func (s *meilisearchService) withKeyset(filter string, request ListRequest) string {
+	position := request.position()
+	if position == nil {
+		return filter
+	}
+
+	operator := ">"
+	if request.scansDescending() {
+		operator = "<"
+	}
+	timestamp := position.CreatedAt.UnixMilli()
+	keyset := fmt.Sprintf("(CreatedAtTimestamp %s %d OR (CreatedAtTimestamp = %d AND ID %s %d))", operator, timestamp, timestamp, operator, position.ID)
+	if filter == "" {
+		return keyset
+	}
+	return filter + " AND " + keyset
+}
+
+func (s *meilisearchService) getSort(request ListRequest) []string {
+	if request.pagesByKeyset() {
+		direction := SortAsc
+		if request.scansDescending() {
+			direction = SortDesc
+		}
+		return []string{"CreatedAtTimestamp:" + string(direction), "ID:" + string(direction)}
+	}
+	// text searches are ranked by relevance unless a sort is asked for
+	if request.SortBy == "" && request.SortDirection == "" {
+		return nil
+	}
+
+	attribute := "CreatedAtTimestamp"
+	switch request.SortBy {
+	case SortTotal:
+		attribute = "Total"
+	case SortStatus:
+		attribute = "Status"
+	}
+
+	return []string{attribute + ":" + string(request.direction())}
 }
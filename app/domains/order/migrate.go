@@ -0,0 +1,26 @@
+package order
+
+import (
+	"github.com/p4xx07/order-service/internal/money"
+	"gorm.io/gorm"
+)
+
+// BackfillTotals works out the totals of the orders placed before they were persisted.
+// It runs after AutoMigrate has added the total columns, and only touches orders whose totals are still unset.
+func BackfillTotals(db *gorm.DB) error {
+	err := db.Exec("UPDATE order_items SET total = price * quantity WHERE total = 0").Error
+	if err != nil {
+		return err
+	}
+
+	return db.Exec(`
+		UPDATE orders
+		JOIN (SELECT order_id, SUM(total) AS subtotal FROM order_items GROUP BY order_id) AS items ON items.order_id = orders.id
+		SET orders.subtotal = items.subtotal, orders.total = items.subtotal - orders.discount + orders.tax
+		WHERE orders.subtotal = 0`).Error
+}
+
+// BackfillCurrency marks the orders placed before orders had a currency as placed in the base currency.
+func BackfillCurrency(db *gorm.DB, base money.Currency) error {
+	return db.Exec("UPDATE orders SET currency = ?, exchange_rate = 1 WHERE currency = ''", base).Error
+}
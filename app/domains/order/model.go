@@ -2,40 +2,224 @@ package order
 
 import (
 	"github.com/p4xx07/order-service/app/domains/product"
+	"github.com/p4xx07/order-service/app/domains/promotion"
+	"github.com/p4xx07/order-service/app/domains/tax"
+	"github.com/p4xx07/order-service/app/domains/user"
+	"github.com/p4xx07/order-service/internal/money"
 	"time"
 )
 
 type Order struct {
-	ID        uint        `gorm:"primaryKey;autoIncrement"`
-	UserID    uint        `gorm:"index"`
-	Status    string      `gorm:"type:varchar(20);default:'pending'"`
-	CreatedAt time.Time   `gorm:"autoCreateTime"`
-	UpdatedAt time.Time   `gorm:"autoUpdateTime"`
-	Items     []OrderItem `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
+	ID            uint       `gorm:"primaryKey;autoIncrement;index:idx_orders_updated_at_id,priority:2;index:idx_orders_created_at_id,priority:2"`
+	UserID        uint       `gorm:"index"`
+	Status        Status     `gorm:"type:varchar(20);default:'pending';index:idx_orders_status_reserved_until"`
+	ReservedUntil *time.Time `gorm:"index:idx_orders_status_reserved_until"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime;index:idx_orders_created_at_id,priority:1"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime;index:idx_orders_updated_at_id,priority:1"`
+	// Currency is the currency the order was placed in and ExchangeRate the rate from the base currency
+	// its prices were converted at, kept so that the order totals never change afterwards.
+	Currency     money.Currency `gorm:"type:varchar(3);not null;default:''"`
+	ExchangeRate float64        `gorm:"type:decimal(18,8);not null;default:1"`
+	// ShippingAddress is a copy of the user address the order ships to, taken when the order is placed.
+	ShippingAddress Address `gorm:"embedded;embeddedPrefix:shipping_"`
+	// Region is the shipping region the order is taxed for.
+	Region     string           `gorm:"type:varchar(20);not null;default:''"`
+	Subtotal   money.Amount     `gorm:"not null;default:0"`
+	Discount   money.Amount     `gorm:"not null;default:0"`
+	Tax        money.Amount     `gorm:"not null;default:0"`
+	Total      money.Amount     `gorm:"not null;default:0;index"`
+	Items      []OrderItem      `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
+	Promotions []OrderPromotion `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
 }
 
-func NewOrder(userID uint, items []OrderItem) *Order {
-	return &Order{
-		UserID:    userID,
-		Status:    "pending",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		Items:     items,
+func NewOrder(userID uint, currency money.Currency, exchangeRate float64, region string, items []OrderItem, reservedUntil time.Time) *Order {
+	order := &Order{
+		UserID:        userID,
+		Currency:      currency,
+		ExchangeRate:  exchangeRate,
+		Region:        region,
+		Status:        StatusPending,
+		ReservedUntil: &reservedUntil,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
+	order.setItems(items)
+	return order
+}
+
+// setItems replaces the order items and works out the order totals again.
+func (o *Order) setItems(items []OrderItem) {
+	o.Items = items
+	o.calculateTotals()
+}
+
+// setPromotions replaces the promotions applied to the order and works out the order totals again.
+func (o *Order) setPromotions(discounts []promotion.Discount) {
+	o.Promotions = make([]OrderPromotion, len(discounts))
+	for i, discount := range discounts {
+		o.Promotions[i] = OrderPromotion{
+			OrderID:     o.ID,
+			PromotionID: discount.PromotionID,
+			Code:        discount.Code,
+			Discount:    discount.Amount,
+		}
+	}
+	o.calculateTotals()
+}
+
+func (o *Order) calculateTotals() {
+	o.Subtotal = 0
+	for i := range o.Items {
+		o.Items[i].Total = o.Items[i].Price.Times(o.Items[i].Quantity)
+		o.Subtotal += o.Items[i].Total
+	}
+	o.Discount = 0
+	for _, applied := range o.Promotions {
+		o.Discount += applied.Discount
+	}
+
+	// inclusive taxes are already part of the item prices
+	o.Tax = 0
+	var exclusiveTax money.Amount
+	for _, item := range o.Items {
+		o.Tax += item.Tax
+		if !item.TaxInclusive {
+			exclusiveTax += item.Tax
+		}
+	}
+	o.Total = o.Subtotal - o.Discount + exclusiveTax
+}
+
+// setTaxes stores the tax of every item, given in the order of the items, and works out the order totals again.
+func (o *Order) setTaxes(taxes []tax.LineTax) {
+	for i := range o.Items {
+		o.Items[i].TaxPercent = taxes[i].Percent
+		o.Items[i].TaxInclusive = taxes[i].Inclusive
+		o.Items[i].Tax = taxes[i].Amount
+	}
+	o.calculateTotals()
+}
+
+// taxRequest lists what is paid for every item, the order discount being spread over the items in proportion to their totals.
+func (o *Order) taxRequest(categories map[uint]string) tax.CalculateRequest {
+	lines := make([]tax.Line, len(o.Items))
+	remaining := o.Discount
+	for i, item := range o.Items {
+		share := remaining
+		if i < len(o.Items)-1 && o.Subtotal > 0 {
+			share = money.Amount(int64(o.Discount) * int64(item.Total) / int64(o.Subtotal))
+		}
+		remaining -= share
+		lines[i] = tax.Line{Category: categories[item.ProductID], Amount: item.Total - share}
+	}
+	return tax.CalculateRequest{Region: o.Region, Lines: lines}
+}
+
+// reservationExpired reports whether the stock reservation of a pending order ran out.
+func (o *Order) reservationExpired(now time.Time) bool {
+	return o.ReservedUntil != nil && !o.ReservedUntil.After(now)
+}
+
+func (o *Order) productIDs() []uint {
+	productIDs := make([]uint, len(o.Items))
+	for i, item := range o.Items {
+		productIDs[i] = item.ProductID
+	}
+	return productIDs
+}
+
+// itemCount is the number of units ordered across all items.
+func (o *Order) itemCount() int {
+	count := 0
+	for _, item := range o.Items {
+		count += item.Quantity
+	}
+	return count
+}
+
+// cart is what the promotions of the order are worked out on.
+func (o *Order) cart() promotion.Cart {
+	lines := make([]promotion.Line, len(o.Items))
+	for i, item := range o.Items {
+		lines[i] = promotion.Line{ProductID: item.ProductID, Quantity: item.Quantity, Price: item.Price}
+	}
+	return promotion.Cart{ExchangeRate: o.ExchangeRate, Lines: lines}
+}
+
+func (o *Order) promotionCodes() []string {
+	codes := make([]string, len(o.Promotions))
+	for i, applied := range o.Promotions {
+		codes[i] = applied.Code
+	}
+	return codes
+}
+
+func (o *Order) promotionIDs() []uint {
+	promotionIDs := make([]uint, len(o.Promotions))
+	for i, applied := range o.Promotions {
+		promotionIDs[i] = applied.PromotionID
+	}
+	return promotionIDs
+}
+
+func (o *Order) quantities() map[uint]int {
+	quantities := map[uint]int{}
+	for _, item := range o.Items {
+		quantities[item.ProductID] += item.Quantity
+	}
+	return quantities
 }
 
 type OrderItem struct {
-	ID        uint            `gorm:"primaryKey;autoIncrement"`
-	OrderID   uint            `gorm:"index"`
-	ProductID uint            `gorm:"index"`
-	Quantity  int             `gorm:"type:int;not null"`
-	Price     float64         `gorm:"type:decimal(10,2);not null"`
-	Product   product.Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	ID        uint         `gorm:"primaryKey;autoIncrement"`
+	OrderID   uint         `gorm:"index"`
+	ProductID uint         `gorm:"index"`
+	Quantity  int          `gorm:"type:int;not null"`
+	Price     money.Amount `gorm:"not null"`
+	Total     money.Amount `gorm:"not null;default:0"`
+	// Tax is the tax of the line, charged at TaxPercent and, when TaxInclusive, already part of Total.
+	TaxPercent   float64         `gorm:"type:decimal(7,4);not null;default:0"`
+	TaxInclusive bool            `gorm:"not null;default:false"`
+	Tax          money.Amount    `gorm:"not null;default:0"`
+	Product      product.Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+}
+
+type Address struct {
+	Name       string `gorm:"type:varchar(100)"`
+	Line1      string `gorm:"type:varchar(200)"`
+	Line2      string `gorm:"type:varchar(200)"`
+	City       string `gorm:"type:varchar(100)"`
+	PostalCode string `gorm:"type:varchar(20)"`
+	Region     string `gorm:"type:varchar(100)"`
+	Country    string `gorm:"type:varchar(2)"`
+}
+
+func newAddress(address *user.AddressResponse) Address {
+	return Address{
+		Name:       address.Name,
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		PostalCode: address.PostalCode,
+		Region:     address.Region,
+		Country:    address.Country,
+	}
+}
+
+// OrderPromotion is a promotion code redeemed by an order and the discount it gave.
+type OrderPromotion struct {
+	ID          uint         `gorm:"primaryKey;autoIncrement"`
+	OrderID     uint         `gorm:"index"`
+	PromotionID uint         `gorm:"index"`
+	Code        string       `gorm:"type:varchar(50);not null"`
+	Discount    money.Amount `gorm:"not null"`
 }
 
 type OrderMeilisearch struct {
 	Order
 	CreatedAtTimestamp int64 `gorm:"autoCreateTime"`
+	ItemCount          int
+	ProductIDs         []uint
 }
 
 func (o *Order) toDocument() OrderMeilisearch {
@@ -46,5 +230,7 @@ func (o *Order) toDocument() OrderMeilisearch {
 	return OrderMeilisearch{
 		Order:              *o,
 		CreatedAtTimestamp: o.CreatedAt.UnixMilli(),
+		ItemCount:          o.itemCount(),
+		ProductIDs:         o.productIDs(),
 	}
 }
@@ -0,0 +1,286 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"github.com/p4xx07/order-service/internal/event"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+type OutboxEventType string
+
+const (
+	OutboxOrderCreated OutboxEventType = "order_created"
+	OutboxOrderUpdated OutboxEventType = "order_updated"
+	OutboxOrderDeleted OutboxEventType = "order_deleted"
+	// OutboxOrderStatusChanged is an update that moved the order from FromStatus to ToStatus.
+	OutboxOrderStatusChanged OutboxEventType = "order_status_changed"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "pending"
+	OutboxDelivered OutboxStatus = "delivered"
+	OutboxDead      OutboxStatus = "dead"
+)
+
+func (s OutboxStatus) IsValid() bool {
+	return s == OutboxPending || s == OutboxDelivered || s == OutboxDead
+}
+
+// OutboxDelivery is how far the delivery of an outbox event to one of its subscribers got.
+type OutboxDelivery struct {
+	Status        OutboxStatus `json:"status"`
+	Attempts      int          `json:"attempts"`
+	NextAttemptAt time.Time    `json:"next_attempt_at"`
+	LastError     string       `json:"last_error,omitempty"`
+}
+
+// OutboxEvent records an order change in the same transaction as the change itself,
+// so that the search index can be brought up to date even when it was unreachable at the time.
+// Every subscriber has a delivery of its own; Status, Attempts, NextAttemptAt and LastError sum them up.
+type OutboxEvent struct {
+	ID            uint            `gorm:"primaryKey;autoIncrement"`
+	OrderID       uint            `gorm:"index;not null"`
+	Type          OutboxEventType `gorm:"type:varchar(30);not null;index:idx_order_outbox_type_created_at"`
+	FromStatus    Status          `gorm:"type:varchar(30)"`
+	ToStatus      Status          `gorm:"type:varchar(30)"`
+	Status        OutboxStatus    `gorm:"type:varchar(20);not null;index:idx_order_outbox_status_next_attempt_at"`
+	Attempts      int             `gorm:"not null;default:0"`
+	NextAttemptAt time.Time       `gorm:"not null;index:idx_order_outbox_status_next_attempt_at"`
+	LastError     string          `gorm:"type:varchar(1000)"`
+	DeliveredAt   *time.Time
+	Deliveries    map[event.Subscriber]*OutboxDelivery `gorm:"serializer:json;type:json"`
+	CreatedAt     time.Time                            `gorm:"autoCreateTime;index:idx_order_outbox_type_created_at"`
+	UpdatedAt     time.Time                            `gorm:"autoUpdateTime"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "order_outbox"
+}
+
+func newOutboxEvent(orderID uint, eventType OutboxEventType) *OutboxEvent {
+	return &OutboxEvent{
+		OrderID:       orderID,
+		Type:          eventType,
+		Status:        OutboxPending,
+		NextAttemptAt: time.Now(),
+	}
+}
+
+func newStatusOutboxEvent(orderID uint, from Status, to Status) *OutboxEvent {
+	event := newOutboxEvent(orderID, OutboxOrderStatusChanged)
+	event.FromStatus = from
+	event.ToStatus = to
+	return event
+}
+
+// events are the order events the outbox event is published as, given the latest state of the order or nil once it is gone.
+func (e *OutboxEvent) events(order *Order) []event.Event {
+	if order == nil {
+		return []event.Event{OrderDeleted{OutboxID: e.ID, OrderID: e.OrderID}}
+	}
+
+	switch e.Type {
+	case OutboxOrderCreated:
+		return []event.Event{OrderCreated{OutboxID: e.ID, Order: *order}}
+	case OutboxOrderStatusChanged:
+		return []event.Event{
+			OrderUpdated{OutboxID: e.ID, Order: *order},
+			OrderStatusChanged{OutboxID: e.ID, Order: *order, From: e.FromStatus, To: e.ToStatus},
+		}
+	}
+	return []event.Event{OrderUpdated{OutboxID: e.ID, Order: *order}}
+}
+
+// delivery returns the delivery to subscriber, starting one that is due right away when there is none yet.
+func (e *OutboxEvent) delivery(subscriber event.Subscriber, now time.Time) *OutboxDelivery {
+	if e.Deliveries == nil {
+		e.Deliveries = map[event.Subscriber]*OutboxDelivery{}
+	}
+	if e.Deliveries[subscriber] == nil {
+		e.Deliveries[subscriber] = &OutboxDelivery{Status: OutboxPending, NextAttemptAt: now}
+	}
+	return e.Deliveries[subscriber]
+}
+
+// keepDeliveries drops the deliveries to subscribers the event is no longer published to,
+// such as the ones of an update whose order got deleted in the meantime.
+func (e *OutboxEvent) keepDeliveries(subscribers []event.Subscriber) {
+	maps.DeleteFunc(e.Deliveries, func(subscriber event.Subscriber, _ *OutboxDelivery) bool {
+		return !slices.Contains(subscribers, subscriber)
+	})
+}
+
+// settle sums the deliveries up: the event is pending while any subscriber is still due to get it, dead when a subscriber
+// ran out of attempts and delivered once all of them got it.
+func (e *OutboxEvent) settle(now time.Time) {
+	e.Status = OutboxDelivered
+	e.Attempts = 0
+	var nextAttemptAt time.Time
+	var lastErrors []string
+	for _, subscriber := range slices.Sorted(maps.Keys(e.Deliveries)) {
+		delivery := e.Deliveries[subscriber]
+		e.Attempts = max(e.Attempts, delivery.Attempts)
+		if delivery.LastError != "" {
+			lastErrors = append(lastErrors, fmt.Sprintf("%s: %s", subscriber, delivery.LastError))
+		}
+
+		switch delivery.Status {
+		case OutboxPending:
+			e.Status = OutboxPending
+			if nextAttemptAt.IsZero() || delivery.NextAttemptAt.Before(nextAttemptAt) {
+				nextAttemptAt = delivery.NextAttemptAt
+			}
+		case OutboxDead:
+			if e.Status != OutboxPending {
+				e.Status = OutboxDead
+			}
+		}
+	}
+
+	e.LastError = truncateLastError(strings.Join(lastErrors, "; "))
+	switch e.Status {
+	case OutboxPending:
+		e.NextAttemptAt = nextAttemptAt
+	case OutboxDelivered:
+		e.DeliveredAt = &now
+	}
+}
+
+// retry gives the subscribers that ran out of attempts a fresh set of them.
+func (e *OutboxEvent) retry(now time.Time) {
+	for _, delivery := range e.Deliveries {
+		if delivery.Status == OutboxDead {
+			*delivery = OutboxDelivery{Status: OutboxPending, NextAttemptAt: now}
+		}
+	}
+	e.settle(now)
+	// events that died before they had deliveries go back to every subscriber
+	e.Status = OutboxPending
+	e.NextAttemptAt = now
+	e.DeliveredAt = nil
+}
+
+func truncateLastError(lastError string) string {
+	if len(lastError) > maxOutboxLastErrorSize {
+		return lastError[:maxOutboxLastErrorSize]
+	}
+	return lastError
+}
+
+type IOutboxStore interface {
+	Add(ctx context.Context, event *OutboxEvent) error
+	ClaimDue(ctx context.Context, now time.Time, limit int, leaseUntil time.Time) ([]OutboxEvent, error)
+	List(ctx context.Context, status OutboxStatus, limit int, offset int) ([]OutboxEvent, int64, error)
+	ListDeletedSince(ctx context.Context, since time.Time, afterID uint, limit int) ([]OutboxEvent, error)
+	Update(ctx context.Context, event *OutboxEvent) error
+	Retry(ctx context.Context, id uint) error
+	WithTx(tx *gorm.DB) IOutboxStore
+}
+
+type outboxStore struct {
+	db *gorm.DB
+}
+
+func NewOutboxStore(db *gorm.DB) IOutboxStore {
+	return &outboxStore{db: db}
+}
+
+func (s *outboxStore) WithTx(tx *gorm.DB) IOutboxStore {
+	return &outboxStore{db: tx}
+}
+
+func (s *outboxStore) Add(ctx context.Context, event *OutboxEvent) error {
+	return s.db.WithContext(ctx).Create(event).Error
+}
+
+// ClaimDue leases the pending events that are ready to be delivered until leaseUntil, and commits before returning,
+// so no rows stay locked while they are delivered. Rows locked by another relay are skipped, so several instances
+// can relay side by side; the events of a relay that died before recording the outcome are due again once the lease ran out.
+func (s *outboxStore) ClaimDue(ctx context.Context, now time.Time, limit int, leaseUntil time.Time) ([]OutboxEvent, error) {
+	var events []OutboxEvent
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", OutboxPending, now).
+			Order("id").
+			Limit(limit).
+			Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(events))
+		for i := range events {
+			ids[i] = events[i].ID
+		}
+		return tx.Model(&OutboxEvent{}).
+			Where("id IN (?)", ids).
+			Update("next_attempt_at", leaseUntil).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due outbox events: %w", err)
+	}
+	return events, nil
+}
+
+func (s *outboxStore) List(ctx context.Context, status OutboxStatus, limit int, offset int) ([]OutboxEvent, int64, error) {
+	query := s.db.WithContext(ctx).Model(&OutboxEvent{}).Where("status = ?", status)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count outbox events: %w", err)
+	}
+
+	var events []OutboxEvent
+	err := query.
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&events).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list outbox events: %w", err)
+	}
+	return events, total, nil
+}
+
+// ListDeletedSince pages through the deletions recorded after the (since, afterID) position, oldest first.
+// The outbox keeps them after they were delivered, so they serve as tombstones of the deleted orders.
+func (s *outboxStore) ListDeletedSince(ctx context.Context, since time.Time, afterID uint, limit int) ([]OutboxEvent, error) {
+	var events []OutboxEvent
+	err := s.db.WithContext(ctx).
+		Where("type = ? AND (created_at > ? OR (created_at = ? AND id > ?))", OutboxOrderDeleted, since, since, afterID).
+		Order("created_at, id").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted orders: %w", err)
+	}
+	return events, nil
+}
+
+func (s *outboxStore) Update(ctx context.Context, event *OutboxEvent) error {
+	return s.db.WithContext(ctx).Save(event).Error
+}
+
+// Retry puts a dead event back in the queue, with a fresh set of attempts for the subscribers that ran out of them.
+func (s *outboxStore) Retry(ctx context.Context, id uint) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var event OutboxEvent
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", id, OutboxDead).
+			First(&event).Error
+		if err != nil {
+			return err
+		}
+
+		event.retry(time.Now())
+		return tx.Save(&event).Error
+	})
+}
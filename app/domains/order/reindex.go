@@ -0,0 +1,320 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/lock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"sync"
+	"time"
+)
+
+const (
+	reindexBatchSize = 500
+	reindexLockKey   = "reindex_orders"
+	shadowIndex      = ordersIndex + "_reindex"
+	// reindexOverlap re-reads the changes right before the watermark, so that orders committed
+	// late with an older updated_at are not skipped. Indexing is an upsert and deleting twice
+	// is a no-op, so reading twice is harmless.
+	reindexOverlap = time.Minute
+)
+
+type ReindexMode string
+
+const (
+	ReindexFull        ReindexMode = "full"
+	ReindexIncremental ReindexMode = "incremental"
+)
+
+func (m ReindexMode) IsValid() bool {
+	return m == ReindexFull || m == ReindexIncremental
+}
+
+type ReindexState string
+
+const (
+	ReindexRunning   ReindexState = "running"
+	ReindexCompleted ReindexState = "completed"
+	ReindexFailed    ReindexState = "failed"
+)
+
+type ReindexProgress struct {
+	Mode       ReindexMode
+	State      ReindexState
+	Indexed    int
+	StartedAt  time.Time
+	FinishedAt *time.Time
+	Error      string
+}
+
+// IReindexer rebuilds the orders search index from the database.
+// A full reindex fills a shadow index and swaps it in, an incremental one upserts the orders changed since the last run
+// and removes the ones deleted since, as told by the deletions in the outbox.
+type IReindexer interface {
+	Reindex(ctx context.Context, mode ReindexMode) (*ReindexProgress, error)
+	Start(mode ReindexMode) (*ReindexProgress, error)
+	Progress() *ReindexProgress
+	Sync(ctx context.Context)
+}
+
+type reindexer struct {
+	configuration      *configuration.Configuration
+	logger             *zap.SugaredLogger
+	store              IStore
+	outboxStore        IOutboxStore
+	watermarkStore     IWatermarkStore
+	meilisearchService IMeilisearchService
+	locker             lock.ILocker
+
+	mu       sync.Mutex
+	progress *ReindexProgress
+}
+
+func NewReindexer(store IStore, outboxStore IOutboxStore, watermarkStore IWatermarkStore, meilisearchService IMeilisearchService, locker lock.ILocker, configuration *configuration.Configuration, logger *zap.SugaredLogger) IReindexer {
+	return &reindexer{store: store, outboxStore: outboxStore, watermarkStore: watermarkStore, meilisearchService: meilisearchService, locker: locker, configuration: configuration, logger: logger}
+}
+
+// Reindex runs a reindex to completion.
+func (r *reindexer) Reindex(ctx context.Context, mode ReindexMode) (*ReindexProgress, error) {
+	held, err := r.acquire(ctx, mode)
+	if err != nil {
+		return nil, err
+	}
+	defer r.release(held)
+
+	err = r.run(ctx, mode, held)
+	return r.Progress(), err
+}
+
+// Start runs a reindex in the background and returns as soon as it is under way.
+func (r *reindexer) Start(mode ReindexMode) (*ReindexProgress, error) {
+	ctx := context.Background()
+	held, err := r.acquire(ctx, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := r.Progress()
+	go func() {
+		defer r.release(held)
+		if err := r.run(ctx, mode, held); err != nil {
+			r.logger.Errorw("error reindexing orders", "error", err, "mode", mode)
+		}
+	}()
+	return progress, nil
+}
+
+// Progress returns a snapshot of the last reindex started by this instance, or nil if there was none.
+func (r *reindexer) Progress() *ReindexProgress {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.progress == nil {
+		return nil
+	}
+	progress := *r.progress
+	return &progress
+}
+
+// Sync brings the index up to date on startup: it is rebuilt when empty and caught up otherwise.
+func (r *reindexer) Sync(ctx context.Context) {
+	empty, err := r.meilisearchService.IsEmpty(ctx)
+	if err != nil {
+		r.logger.Errorw("error checking orders index", "error", err)
+		return
+	}
+
+	mode := ReindexIncremental
+	if empty {
+		mode = ReindexFull
+	}
+
+	progress, err := r.Reindex(ctx, mode)
+	if errors.Is(err, ErrReindexInProgress) {
+		return
+	}
+	if err != nil {
+		r.logger.Errorw("error syncing orders index", "error", err, "mode", mode)
+		return
+	}
+	r.logger.Infow("orders index synced", "mode", mode, "indexed", progress.Indexed)
+}
+
+func (r *reindexer) acquire(ctx context.Context, mode ReindexMode) (lock.ILock, error) {
+	if !mode.IsValid() {
+		return nil, ErrInvalidReindexMode
+	}
+
+	held, err := r.locker.Acquire(ctx, reindexLockKey)
+	if errors.Is(err, lock.ErrNotAcquired) {
+		return nil, ErrReindexInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.progress = &ReindexProgress{Mode: mode, State: ReindexRunning, StartedAt: time.Now()}
+	r.mu.Unlock()
+	return held, nil
+}
+
+func (r *reindexer) release(held lock.ILock) {
+	if err := held.Release(context.Background()); err != nil {
+		r.logger.Warnw("error releasing reindex lock", "error", err)
+	}
+}
+
+// run reindexes while holding the reindex lock; it stops before swapping the index or moving the watermark
+// once the lock is lost, so that two reindexes never write either of them.
+func (r *reindexer) run(ctx context.Context, mode ReindexMode, held lock.ILock) error {
+	var err error
+	if mode == ReindexFull {
+		err = r.full(ctx, held)
+	} else {
+		err = r.incremental(ctx, held)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.progress.FinishedAt = &now
+	r.progress.State = ReindexCompleted
+	if err != nil {
+		r.progress.State = ReindexFailed
+		r.progress.Error = err.Error()
+	}
+	return err
+}
+
+func (r *reindexer) full(ctx context.Context, held lock.ILock) error {
+	startedAt := r.Progress().StartedAt
+
+	if err := r.meilisearchService.PrepareIndex(ctx, shadowIndex); err != nil {
+		return err
+	}
+
+	var after Cursor
+	for {
+		orders, err := r.store.ListAfter(ctx, after, reindexBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(orders) == 0 {
+			break
+		}
+
+		if err := r.meilisearchService.IndexOrders(ctx, shadowIndex, orders); err != nil {
+			return err
+		}
+		after = orders[len(orders)-1].cursor()
+		r.advance(len(orders))
+	}
+
+	if err := held.Err(); err != nil {
+		return err
+	}
+	if err := r.meilisearchService.PromoteIndex(ctx, shadowIndex); err != nil {
+		return err
+	}
+
+	// changes made while the shadow index was filling went to the old index, catch up on them;
+	// this removes the orders deleted in the meantime that the shadow index still picked up as well
+	if err := r.watermarkStore.Save(ctx, &ReindexWatermark{Index: ordersIndex, SyncedAt: startedAt}); err != nil {
+		return err
+	}
+	return r.incremental(ctx, held)
+}
+
+func (r *reindexer) incremental(ctx context.Context, held lock.ILock) error {
+	startedAt := time.Now()
+	watermark, err := r.watermarkStore.Get(ctx, ordersIndex)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		watermark = &ReindexWatermark{Index: ordersIndex}
+	} else if err != nil {
+		return err
+	}
+
+	since := watermark.SyncedAt
+	if !since.IsZero() {
+		since = since.Add(-reindexOverlap)
+	}
+
+	if err := r.removeDeleted(ctx, since); err != nil {
+		return err
+	}
+
+	var afterID uint
+	for {
+		orders, err := r.store.ListUpdatedSince(ctx, since, afterID, reindexBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(orders) == 0 {
+			return nil
+		}
+
+		if err := r.meilisearchService.IndexOrders(ctx, ordersIndex, orders); err != nil {
+			return err
+		}
+
+		last := orders[len(orders)-1]
+		since, afterID = last.UpdatedAt, last.ID
+		r.advance(len(orders))
+
+		// the watermark stays behind the start of the run, so the next run also sees the deletions made during this one
+		if synced := minTime(since, startedAt); synced.After(watermark.SyncedAt) {
+			watermark.SyncedAt = synced
+			if err := held.Err(); err != nil {
+				return err
+			}
+			if err := r.watermarkStore.Save(ctx, watermark); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// removeDeleted takes the orders deleted after since out of the index.
+func (r *reindexer) removeDeleted(ctx context.Context, since time.Time) error {
+	var afterID uint
+	for {
+		deletions, err := r.outboxStore.ListDeletedSince(ctx, since, afterID, reindexBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(deletions) == 0 {
+			return nil
+		}
+
+		orderIDs := make([]uint, len(deletions))
+		for i := range deletions {
+			orderIDs[i] = deletions[i].OrderID
+		}
+		if err := r.meilisearchService.Delete(orderIDs...); err != nil {
+			return err
+		}
+
+		last := deletions[len(deletions)-1]
+		since, afterID = last.CreatedAt, last.ID
+	}
+}
+
+func minTime(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func (r *reindexer) advance(indexed int) {
+	r.mu.Lock()
+	r.progress.Indexed += indexed
+	progress := *r.progress
+	r.mu.Unlock()
+
+	r.logger.Infow("reindex progress", "mode", progress.Mode, "indexed", progress.Indexed)
+}
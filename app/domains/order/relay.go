@@ -0,0 +1,155 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/db"
+	"github.com/p4xx07/order-service/internal/event"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	relayBatchSize         = 100
+	relayLease             = 5 * time.Minute
+	maxOutboxRetryDelay    = 10 * time.Minute
+	maxOutboxLastErrorSize = 1000
+)
+
+// IOutboxRelay publishes the order outbox as order events to the synchronous subscribers on the bus.
+// Every subscriber gets the event on its own: a failed delivery is retried with exponential backoff
+// until it runs out of attempts and is marked dead, while the other subscribers carry on.
+type IOutboxRelay interface {
+	Run(ctx context.Context)
+	Relay(ctx context.Context) (int, error)
+}
+
+type outboxRelay struct {
+	configuration *configuration.Configuration
+	logger        *zap.SugaredLogger
+	outboxStore   IOutboxStore
+	store         IStore
+	transactor    db.ITransactor
+	bus           event.IBus
+}
+
+func NewOutboxRelay(outboxStore IOutboxStore, store IStore, transactor db.ITransactor, bus event.IBus, configuration *configuration.Configuration, logger *zap.SugaredLogger) IOutboxRelay {
+	return &outboxRelay{outboxStore: outboxStore, store: store, transactor: transactor, bus: bus, configuration: configuration, logger: logger}
+}
+
+func (r *outboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.configuration.OutboxRelayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Relay(ctx); err != nil {
+				r.logger.Errorw("error relaying order outbox", "error", err)
+			}
+		}
+	}
+}
+
+// Relay attempts one batch of due events and returns how many were delivered to all of their subscribers.
+// The events are claimed in a transaction of their own and delivered outside of any,
+// so slow subscribers hold neither row locks nor a database connection; the outcomes are recorded afterwards.
+func (r *outboxRelay) Relay(ctx context.Context) (int, error) {
+	now := time.Now()
+	events, err := r.outboxStore.ClaimDue(ctx, now, relayBatchSize, now.Add(relayLease))
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range events {
+		r.deliver(ctx, &events[i], now)
+		if events[i].Status == OutboxDelivered {
+			delivered++
+		}
+	}
+
+	err = r.transactor.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		outboxStore := r.outboxStore.WithTx(tx)
+		for i := range events {
+			if err := outboxStore.Update(ctx, &events[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return delivered, err
+}
+
+// deliver publishes the outbox event to every synchronous subscriber whose delivery is due, one subscriber at a time,
+// so a subscriber that keeps failing is retried and ends up dead on its own while the others get the event.
+func (r *outboxRelay) deliver(ctx context.Context, outboxEvent *OutboxEvent, now time.Time) {
+	published, err := r.events(ctx, outboxEvent)
+	if err != nil {
+		// without the order no subscriber can get the event, so it fails them all
+		published = outboxEvent.events(&Order{ID: outboxEvent.OrderID})
+	}
+
+	subscribers := r.bus.Subscribers(published...)
+	outboxEvent.keepDeliveries(subscribers)
+	for _, subscriber := range subscribers {
+		delivery := outboxEvent.delivery(subscriber, now)
+		if delivery.Status != OutboxPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+
+		deliveryErr := err
+		if deliveryErr == nil {
+			deliveryErr = r.bus.PublishTo(ctx, subscriber, published...)
+		}
+		if deliveryErr != nil {
+			r.fail(outboxEvent, subscriber, delivery, deliveryErr)
+			continue
+		}
+		delivery.Status = OutboxDelivered
+		delivery.LastError = ""
+	}
+	outboxEvent.settle(time.Now())
+}
+
+func (r *outboxRelay) events(ctx context.Context, outboxEvent *OutboxEvent) ([]event.Event, error) {
+	if outboxEvent.Type == OutboxOrderDeleted {
+		return outboxEvent.events(nil), nil
+	}
+
+	// subscribers always get the latest state of the order, not the one at the time of the event
+	order, err := r.store.Get(ctx, outboxEvent.OrderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return outboxEvent.events(nil), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return outboxEvent.events(order), nil
+}
+
+func (r *outboxRelay) fail(outboxEvent *OutboxEvent, subscriber event.Subscriber, delivery *OutboxDelivery, err error) {
+	delivery.Attempts++
+	delivery.LastError = truncateLastError(err.Error())
+
+	if delivery.Attempts >= r.configuration.OutboxMaxAttempts {
+		r.logger.Errorw("order outbox delivery is dead", "error", err, "id", outboxEvent.ID, "orderID", outboxEvent.OrderID, "subscriber", subscriber, "attempts", delivery.Attempts)
+		delivery.Status = OutboxDead
+		return
+	}
+
+	r.logger.Warnw("error delivering order outbox event", "error", err, "id", outboxEvent.ID, "orderID", outboxEvent.OrderID, "subscriber", subscriber, "attempts", delivery.Attempts)
+	delivery.NextAttemptAt = time.Now().Add(r.backoff(delivery.Attempts))
+}
+
+func (r *outboxRelay) backoff(attempts int) time.Duration {
+	delay := r.configuration.OutboxRetryDelay
+	for i := 1; i < attempts && delay < maxOutboxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxOutboxRetryDelay)
+}
@@ -1,23 +1,164 @@
 package order
 
-import "time"
+import (
+	"fmt"
+	"github.com/p4xx07/order-service/internal/money"
+	"time"
+)
+
+type SortField string
+
+const (
+	SortCreatedAt SortField = "created_at"
+	SortTotal     SortField = "total"
+	SortStatus    SortField = "status"
+)
+
+func (f SortField) IsValid() bool {
+	return f == SortCreatedAt || f == SortTotal || f == SortStatus
+}
+
+type SortDirection string
+
+const (
+	SortAsc  SortDirection = "asc"
+	SortDesc SortDirection = "desc"
+)
+
+func (d SortDirection) IsValid() bool {
+	return d == SortAsc || d == SortDesc
+}
 
 type ListRequest struct {
-	Input     string     `json:"input,omitempty"`
-	StartDate *time.Time `json:"start_date"`
-	EndDate   *time.Time `json:"end_date"`
-	Limit     int64      `json:"limit,omitempty"`
-	Offset    int64      `json:"offset,omitempty"`
+	Input         string         `json:"input,omitempty"`
+	StartDate     *time.Time     `json:"start_date"`
+	EndDate       *time.Time     `json:"end_date"`
+	UserID        uint           `json:"user_id,omitempty"`
+	Status        Status         `json:"status,omitempty"`
+	Currency      money.Currency `json:"currency,omitempty"`
+	ProductIDs    []uint         `json:"product_ids,omitempty"`
+	MinTotal      *money.Amount  `json:"min_total,omitempty"`
+	MaxTotal      *money.Amount  `json:"max_total,omitempty"`
+	MinItems      *int           `json:"min_items,omitempty"`
+	MaxItems      *int           `json:"max_items,omitempty"`
+	SortBy        SortField      `json:"sort_by,omitempty"`
+	SortDirection SortDirection  `json:"sort_direction,omitempty"`
+	Limit         int64          `json:"limit,omitempty"`
+	Offset        int64          `json:"offset,omitempty"`
+	Cursor        string         `json:"cursor,omitempty"`
+	// After and Before are the keyset position decoded from Cursor.
+	After  *Cursor `json:"-"`
+	Before *Cursor `json:"-"`
+}
+
+// pagesByKeyset reports whether the listing is sorted by creation time alone, so that it can be paged by keyset.
+// Text searches are ranked by relevance first and are paged by offset.
+func (r ListRequest) pagesByKeyset() bool {
+	return r.Input == "" && (r.SortBy == "" || r.SortBy == SortCreatedAt)
+}
+
+// direction is the order the listing is sorted in, newest or largest first unless ascending is asked for.
+func (r ListRequest) direction() SortDirection {
+	if r.SortDirection == "" {
+		return SortDesc
+	}
+	return r.SortDirection
+}
+
+// scansDescending reports whether a keyset listing reads the orders newest first.
+// Pages before the cursor are read in the opposite order and flipped back afterwards.
+func (r ListRequest) scansDescending() bool {
+	return (r.direction() == SortDesc) != (r.Before != nil)
+}
+
+func (r ListRequest) position() *Cursor {
+	if r.Before != nil {
+		return r.Before
+	}
+	return r.After
+}
+
+func (r ListRequest) Validate() error {
+	if r.Status != "" && !r.Status.IsValid() {
+		return ErrInvalidStatus
+	}
+	if r.Currency != "" && !r.Currency.IsValid() {
+		return fmt.Errorf("%w: invalid currency %s", ErrInvalidFilter, r.Currency)
+	}
+	if r.StartDate != nil && r.EndDate != nil && r.StartDate.After(*r.EndDate) {
+		return fmt.Errorf("%w: start_date is after end_date", ErrInvalidFilter)
+	}
+	if (r.MinTotal != nil && *r.MinTotal < 0) || (r.MaxTotal != nil && *r.MaxTotal < 0) {
+		return fmt.Errorf("%w: totals cannot be negative", ErrInvalidFilter)
+	}
+	if r.MinTotal != nil && r.MaxTotal != nil && *r.MinTotal > *r.MaxTotal {
+		return fmt.Errorf("%w: min_total is greater than max_total", ErrInvalidFilter)
+	}
+	if (r.MinItems != nil && *r.MinItems < 0) || (r.MaxItems != nil && *r.MaxItems < 0) {
+		return fmt.Errorf("%w: item counts cannot be negative", ErrInvalidFilter)
+	}
+	if r.MinItems != nil && r.MaxItems != nil && *r.MinItems > *r.MaxItems {
+		return fmt.Errorf("%w: min_items is greater than max_items", ErrInvalidFilter)
+	}
+	if r.SortBy != "" && !r.SortBy.IsValid() {
+		return fmt.Errorf("%w: cannot sort by %s", ErrInvalidFilter, r.SortBy)
+	}
+	if r.SortDirection != "" && !r.SortDirection.IsValid() {
+		return fmt.Errorf("%w: invalid sort direction %s", ErrInvalidFilter, r.SortDirection)
+	}
+	return nil
+}
+
+type ListByUserRequest struct {
+	UserID uint `json:"user_id,omitempty" validate:"min=1,nonnil" required:"true"`
+	Limit  int  `json:"limit,omitempty"`
+	Offset int  `json:"offset,omitempty"`
 }
 
 type PostRequest struct {
-	UserID uint               `json:"user_id,omitempty" validate:"min=1,nonnil" required:"true"`
-	Items  []OrderItemRequest `json:"items,omitempty" validate:"min=1,nonnil" required:"true"`
+	UserID   uint           `json:"user_id,omitempty" validate:"min=1,nonnil" required:"true"`
+	Currency money.Currency `json:"currency,omitempty"`
+	// AddressID is an address of the user to ship to; its country is the tax region unless Region is given.
+	AddressID uint               `json:"address_id,omitempty"`
+	Region    string             `json:"region,omitempty" validate:"max=20"`
+	Coupons   []string           `json:"coupons,omitempty"`
+	Items     []OrderItemRequest `json:"items,omitempty" validate:"min=1,nonnil" required:"true"`
 }
 
 type PutRequest struct {
 	ID    uint               `json:"id,omitempty" validate:"min=1,nonnil" required:"true"`
 	Items []OrderItemRequest `json:"items,omitempty" validate:"min=1,nonnil" required:"true"`
+	// Coupons replaces the coupon codes of the order; left out, the order keeps the ones it has.
+	Coupons []string `json:"coupons"`
+}
+
+type TransitionRequest struct {
+	ID     uint   `json:"id,omitempty" validate:"min=1,nonnil" required:"true"`
+	Status Status `json:"status,omitempty" validate:"nonzero" required:"true"`
+	// Client limits the request to the transitions clients can make, the others are left to the domain services.
+	Client bool `json:"-"`
+}
+
+type ListOutboxRequest struct {
+	Status OutboxStatus `json:"status,omitempty"`
+	Limit  int          `json:"limit,omitempty"`
+	Offset int          `json:"offset,omitempty"`
+}
+
+type ReindexRequest struct {
+	Mode ReindexMode `json:"mode,omitempty"`
+}
+
+// validateItems rejects a product listed on more than one item, its whole quantity goes on a single item.
+func validateItems(items []OrderItemRequest) error {
+	seen := make(map[uint]struct{}, len(items))
+	for _, item := range items {
+		if _, ok := seen[item.ProductID]; ok {
+			return fmt.Errorf("%w: product %d", ErrDuplicateProduct, item.ProductID)
+		}
+		seen[item.ProductID] = struct{}{}
+	}
+	return nil
 }
 
 type OrderItemRequest struct {
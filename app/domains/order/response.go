@@ -2,6 +2,8 @@ package order
 
 import (
 	"github.com/p4xx07/order-service/app/domains/product"
+	"github.com/p4xx07/order-service/internal/event"
+	"github.com/p4xx07/order-service/internal/money"
 	"time"
 )
 
@@ -10,11 +12,21 @@ type CreateOrderResponse struct {
 }
 
 type OrderResponse struct {
-	ID        uint                `json:"id,omitempty"`
-	UserID    uint                `json:"user_id,omitempty"`
-	Status    string              `json:"status,omitempty"`
-	CreatedAt time.Time           `json:"created_at"`
-	Items     []OrderItemResponse `json:"items,omitempty"`
+	ID              uint                     `json:"id,omitempty"`
+	UserID          uint                     `json:"user_id,omitempty"`
+	Status          Status                   `json:"status,omitempty"`
+	ReservedUntil   *time.Time               `json:"reserved_until,omitempty"`
+	CreatedAt       time.Time                `json:"created_at"`
+	Currency        money.Currency           `json:"currency"`
+	ExchangeRate    float64                  `json:"exchange_rate"`
+	Region          string                   `json:"region,omitempty"`
+	ShippingAddress *AddressResponse         `json:"shipping_address,omitempty"`
+	Subtotal        money.Amount             `json:"subtotal"`
+	Discount        money.Amount             `json:"discount"`
+	Tax             money.Amount             `json:"tax"`
+	Total           money.Amount             `json:"total"`
+	Items           []OrderItemResponse      `json:"items,omitempty"`
+	Promotions      []OrderPromotionResponse `json:"promotions,omitempty"`
 }
 
 func (o *Order) ToResponse() *OrderResponse {
@@ -22,25 +34,165 @@ func (o *Order) ToResponse() *OrderResponse {
 	for i, item := range o.Items {
 		items[i] = item.ToResponse()
 	}
-	return &OrderResponse{
-		ID:        o.ID,
-		UserID:    o.UserID,
-		Status:    o.Status,
-		CreatedAt: o.CreatedAt,
-		Items:     items,
+	promotions := make([]OrderPromotionResponse, len(o.Promotions))
+	for i, applied := range o.Promotions {
+		promotions[i] = applied.ToResponse()
 	}
+	response := &OrderResponse{
+		ID:           o.ID,
+		UserID:       o.UserID,
+		Status:       o.Status,
+		CreatedAt:    o.CreatedAt,
+		Currency:     o.Currency,
+		ExchangeRate: o.ExchangeRate,
+		Region:       o.Region,
+		Subtotal:     o.Subtotal,
+		Discount:     o.Discount,
+		Tax:          o.Tax,
+		Total:        o.Total,
+		Items:        items,
+		Promotions:   promotions,
+	}
+	if o.ShippingAddress.Country != "" {
+		address := o.ShippingAddress.ToResponse()
+		response.ShippingAddress = &address
+	}
+	if o.Status.HoldsReservation() {
+		response.ReservedUntil = o.ReservedUntil
+	}
+	return response
+}
+
+type ListOrdersResponse struct {
+	Items      []OrderResponse `json:"items"`
+	Total      int64           `json:"total"`
+	Limit      int             `json:"limit"`
+	Offset     int             `json:"offset"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	PrevCursor string          `json:"prev_cursor,omitempty"`
+	Facets     *OrderFacets    `json:"facets,omitempty"`
+}
+
+// OrderFacets counts the orders matching a search by status and by product.
+type OrderFacets struct {
+	Status  map[Status]int64 `json:"status"`
+	Product map[uint]int64   `json:"product"`
 }
 
 type OrderItemResponse struct {
-	Quantity int                     `json:"quantity,omitempty"`
-	Price    float64                 `json:"price,omitempty"`
-	Product  product.ProductResponse `json:"product"`
+	ID           uint                    `json:"id"`
+	Quantity     int                     `json:"quantity,omitempty"`
+	Price        money.Amount            `json:"price,omitempty"`
+	Total        money.Amount            `json:"total,omitempty"`
+	TaxPercent   float64                 `json:"tax_percent"`
+	TaxInclusive bool                    `json:"tax_inclusive"`
+	Tax          money.Amount            `json:"tax"`
+	Product      product.ProductResponse `json:"product"`
 }
 
 func (o *OrderItem) ToResponse() OrderItemResponse {
 	return OrderItemResponse{
-		Quantity: o.Quantity,
-		Price:    o.Price,
-		Product:  o.Product.ToResponse(),
+		ID:           o.ID,
+		Quantity:     o.Quantity,
+		Price:        o.Price,
+		Total:        o.Total,
+		TaxPercent:   o.TaxPercent,
+		TaxInclusive: o.TaxInclusive,
+		Tax:          o.Tax,
+		Product:      o.Product.ToResponse(),
+	}
+}
+
+type OrderPromotionResponse struct {
+	Code     string       `json:"code"`
+	Discount money.Amount `json:"discount"`
+}
+
+func (o *OrderPromotion) ToResponse() OrderPromotionResponse {
+	return OrderPromotionResponse{
+		Code:     o.Code,
+		Discount: o.Discount,
+	}
+}
+
+type OutboxEventResponse struct {
+	ID            uint            `json:"id"`
+	OrderID       uint            `json:"order_id"`
+	Type          OutboxEventType `json:"type"`
+	FromStatus    Status          `json:"from_status,omitempty"`
+	ToStatus      Status          `json:"to_status,omitempty"`
+	Status        OutboxStatus    `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastError     string          `json:"last_error,omitempty"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+	// Deliveries tells per subscriber how far the delivery got.
+	Deliveries map[event.Subscriber]*OutboxDelivery `json:"deliveries,omitempty"`
+	CreatedAt  time.Time                            `json:"created_at"`
+}
+
+func (e *OutboxEvent) ToResponse() OutboxEventResponse {
+	return OutboxEventResponse{
+		ID:            e.ID,
+		OrderID:       e.OrderID,
+		Type:          e.Type,
+		FromStatus:    e.FromStatus,
+		ToStatus:      e.ToStatus,
+		Status:        e.Status,
+		Attempts:      e.Attempts,
+		NextAttemptAt: e.NextAttemptAt,
+		LastError:     e.LastError,
+		DeliveredAt:   e.DeliveredAt,
+		Deliveries:    e.Deliveries,
+		CreatedAt:     e.CreatedAt,
+	}
+}
+
+type ListOutboxResponse struct {
+	Items  []OutboxEventResponse `json:"items"`
+	Total  int64                 `json:"total"`
+	Limit  int                   `json:"limit"`
+	Offset int                   `json:"offset"`
+}
+
+type ReindexResponse struct {
+	Mode       ReindexMode  `json:"mode"`
+	State      ReindexState `json:"state"`
+	Indexed    int          `json:"indexed"`
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+	Error      string       `json:"error,omitempty"`
+}
+
+func (p *ReindexProgress) ToResponse() *ReindexResponse {
+	return &ReindexResponse{
+		Mode:       p.Mode,
+		State:      p.State,
+		Indexed:    p.Indexed,
+		StartedAt:  p.StartedAt,
+		FinishedAt: p.FinishedAt,
+		Error:      p.Error,
+	}
+}
+
+type AddressResponse struct {
+	Name       string `json:"name"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Region     string `json:"region,omitempty"`
+	Country    string `json:"country"`
+}
+
+func (a *Address) ToResponse() AddressResponse {
+	return AddressResponse{
+		Name:       a.Name,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		PostalCode: a.PostalCode,
+		Region:     a.Region,
+		Country:    a.Country,
 	}
 }
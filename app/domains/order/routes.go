@@ -2,13 +2,25 @@ package order
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/p4xx07/order-service/internal/idempotency"
 )
 
-func SetRoutes(router fiber.Router, handler IHandler) {
+func SetRoutes(router fiber.Router, handler IHandler, idempotent idempotency.Middleware) {
 	g := router.Group("order")
 	g.Get("/", handler.List)
-	g.Post("/", handler.Post)
+	g.Post("/", fiber.Handler(idempotent), handler.Post)
 	g.Get("/:id", handler.Get)
-	g.Put("/:id", handler.Put)
-	g.Delete("/:id", handler.Delete)
+	g.Put("/:id", fiber.Handler(idempotent), handler.Put)
+	g.Delete("/:id", fiber.Handler(idempotent), handler.Delete)
+	g.Post("/:id/transitions", handler.Transition)
+
+	router.Get("/user/:id/orders", handler.ListByUser)
+}
+
+func SetAdminRoutes(router fiber.Router, handler IAdminHandler) {
+	g := router.Group("order")
+	g.Get("/outbox", handler.ListOutbox)
+	g.Post("/outbox/:id/retry", handler.RetryOutbox)
+	g.Get("/reindex", handler.GetReindex)
+	g.Post("/reindex", handler.StartReindex)
 }
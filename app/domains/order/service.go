@@ -2,20 +2,46 @@ package order
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/p4xx07/order-service/app/domains/currency"
 	"github.com/p4xx07/order-service/app/domains/inventory"
+	"github.com/p4xx07/order-service/app/domains/promotion"
+	"github.com/p4xx07/order-service/app/domains/tax"
+	"github.com/p4xx07/order-service/app/domains/user"
 	"github.com/p4xx07/order-service/configuration"
-	"github.com/redis/go-redis/v9"
+	"github.com/p4xx07/order-service/internal/db"
+	"github.com/p4xx07/order-service/internal/lock"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"maps"
+	"slices"
+	"sync/atomic"
 	"time"
 )
 
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// SearchBackend tells which backend served an order search.
+type SearchBackend string
+
+const (
+	SearchBackendMeilisearch SearchBackend = "meilisearch"
+	SearchBackendDatabase    SearchBackend = "database"
+)
+
 type IService interface {
-	List(ctx context.Context, request ListRequest) (interface{}, error)
+	List(ctx context.Context, request ListRequest) (*ListOrdersResponse, SearchBackend, error)
 	Get(ctx context.Context, orderID uint) (*OrderResponse, error)
+	ListByUser(ctx context.Context, request ListByUserRequest) (*ListOrdersResponse, error)
 	Create(ctx context.Context, request PostRequest) (*CreateOrderResponse, error)
 	Update(ctx context.Context, request PutRequest) error
 	Delete(ctx context.Context, id uint) error
+	Transition(ctx context.Context, request TransitionRequest) (*OrderResponse, error)
+	WithTx(tx *gorm.DB) IService
 }
 
 type service struct {
@@ -23,99 +49,264 @@ type service struct {
 	logger             *zap.SugaredLogger
 	store              IStore
 	inventoryService   inventory.IService
-	redisClient        *redis.Client
+	userService        user.IService
+	transactor         db.ITransactor
+	outboxStore        IOutboxStore
+	currencyService    currency.IService
+	promotionService   promotion.IService
+	taxCalculator      tax.TaxCalculator
+	locker             lock.ILocker
 	meilisearchService IMeilisearchService
+
+	// searchUnavailableUntil is the unix nano time until which searches skip Meilisearch after it failed.
+	searchUnavailableUntil atomic.Int64
 }
 
-func NewService(meilisearchService IMeilisearchService, redisClient *redis.Client, configuration *configuration.Configuration, logger *zap.SugaredLogger, store IStore, inventoryService inventory.IService) IService {
-	return &service{meilisearchService: meilisearchService, redisClient: redisClient, configuration: configuration, logger: logger, store: store, inventoryService: inventoryService}
+func NewService(meilisearchService IMeilisearchService, locker lock.ILocker, configuration *configuration.Configuration, logger *zap.SugaredLogger, store IStore, inventoryService inventory.IService, userService user.IService, transactor db.ITransactor, outboxStore IOutboxStore, currencyService currency.IService, promotionService promotion.IService, taxCalculator tax.TaxCalculator) IService {
+	return &service{meilisearchService: meilisearchService, locker: locker, configuration: configuration, logger: logger, store: store, inventoryService: inventoryService, userService: userService, transactor: transactor, outboxStore: outboxStore, currencyService: currencyService, promotionService: promotionService, taxCalculator: taxCalculator}
 }
 
-func (s *service) List(ctx context.Context, request ListRequest) (interface{}, error) {
-	return s.meilisearchService.List(ctx, request)
+// WithTx returns a copy of the service whose reads and writes run on the given transaction,
+// so that other domains can change orders as part of their own unit of work.
+func (s *service) WithTx(tx *gorm.DB) IService {
+	return &service{
+		meilisearchService: s.meilisearchService,
+		locker:             s.locker,
+		configuration:      s.configuration,
+		logger:             s.logger,
+		store:              s.store.WithTx(tx),
+		inventoryService:   s.inventoryService.WithTx(tx),
+		userService:        s.userService,
+		transactor:         db.NewTransactor(tx),
+		outboxStore:        s.outboxStore.WithTx(tx),
+		currencyService:    s.currencyService,
+		promotionService:   s.promotionService.WithTx(tx),
+		taxCalculator:      s.taxCalculator,
+	}
+}
+
+// List searches Meilisearch and falls back to the database when it fails.
+// After a failure Meilisearch is left alone for SearchFallbackCooldown instead of timing out on every request.
+func (s *service) List(ctx context.Context, request ListRequest) (*ListOrdersResponse, SearchBackend, error) {
+	if request.Cursor != "" {
+		cursor, err := decodeCursor(request.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+
+		switch {
+		case !cursor.isKeyset():
+			request.Offset = cursor.Offset
+		case !request.pagesByKeyset():
+			return nil, "", ErrInvalidCursor
+		case cursor.Before:
+			request.Before, request.Offset = cursor.position(), 0
+		default:
+			request.After, request.Offset = cursor.position(), 0
+		}
+	}
+
+	if request.Limit <= 0 {
+		request.Limit = defaultListLimit
+	}
+	if request.Limit > maxListLimit {
+		request.Limit = maxListLimit
+	}
+	if request.Offset < 0 {
+		request.Offset = 0
+	}
+
+	if !request.pagesByKeyset() {
+		response, backend, err := s.search(ctx, request)
+		if err != nil {
+			return nil, backend, err
+		}
+
+		response.setOffsetCursors()
+		return response, backend, nil
+	}
+
+	// one extra order tells whether there is a further page
+	limit := int(request.Limit)
+	request.Limit++
+
+	response, backend, err := s.search(ctx, request)
+	if err != nil {
+		return nil, backend, err
+	}
+
+	response.setKeysetCursors(request, limit)
+	return response, backend, nil
+}
+
+func (s *service) search(ctx context.Context, request ListRequest) (*ListOrdersResponse, SearchBackend, error) {
+	if time.Now().UnixNano() >= s.searchUnavailableUntil.Load() {
+		response, err := s.meilisearchService.List(ctx, request)
+		if err == nil {
+			return response, SearchBackendMeilisearch, nil
+		}
+
+		s.logger.Warnw("meilisearch unavailable, listing orders from the database", "error", err)
+		s.searchUnavailableUntil.Store(time.Now().Add(s.configuration.SearchFallbackCooldown).UnixNano())
+	}
+
+	orders, total, err := s.store.List(ctx, request)
+	if err != nil {
+		s.logger.Errorw("error listing orders", "error", err)
+		return nil, SearchBackendDatabase, err
+	}
+
+	items := make([]OrderResponse, len(orders))
+	for i := range orders {
+		items[i] = *orders[i].ToResponse()
+	}
+
+	// facet counts are only available from meilisearch
+	return &ListOrdersResponse{
+		Items:  items,
+		Total:  total,
+		Limit:  int(request.Limit),
+		Offset: int(request.Offset),
+	}, SearchBackendDatabase, nil
 }
 
 func (s *service) Create(ctx context.Context, request PostRequest) (*CreateOrderResponse, error) {
+	if err := validateItems(request.Items); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkUser(ctx, request.UserID); err != nil {
+		return nil, err
+	}
+
+	var shippingAddress Address
+	region := request.Region
+	if request.AddressID != 0 {
+		address, err := s.userService.GetAddress(ctx, request.UserID, request.AddressID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAddressNotFound
+		}
+		if err != nil {
+			s.logger.Errorw("error getting shipping address", "error", err, "userID", request.UserID, "addressID", request.AddressID)
+			return nil, err
+		}
+
+		shippingAddress = newAddress(address)
+		if region == "" {
+			region = address.Country
+		}
+	}
+
+	orderCurrency := request.Currency
+	if orderCurrency == "" {
+		orderCurrency = s.currencyService.Base()
+	}
+
+	// the rate is taken once and kept with the order, so that later changes of the exchange rates leave it alone
+	exchangeRate, err := s.currencyService.Rate(ctx, orderCurrency)
+	if err != nil {
+		return nil, err
+	}
+
 	productIDs := make([]uint, len(request.Items))
 	for i, item := range request.Items {
 		productIDs[i] = item.ProductID
 	}
 
+	stock, err := s.lockProducts(ctx, productIDs)
+	defer s.unlock(ctx, stock)
+	if err != nil {
+		return nil, err
+	}
+
+	// read under the lock, so the stock checked is the one the order reserves from
 	inventories, err := s.inventoryService.GetMultiple(ctx, productIDs)
 	if err != nil {
 		s.logger.Errorw("error getting inventory", "error", err)
 		return nil, err
 	}
 
-	var lockedProducts []string
-
-	defer func() {
-		for _, key := range lockedProducts {
-			s.redisClient.Del(ctx, key)
-		}
-	}()
-
 	for _, item := range request.Items {
-		redisKey := s.getLockProductKey(item.ProductID)
-		lock := s.redisClient.SetNX(ctx, redisKey, "locked", 5*time.Second)
-		if !lock.Val() {
-			s.logger.Errorw("stock update in progress", "productID", item.ProductID)
-			return nil, ErrStockUpdateInProgress
+		if inventories[item.ProductID].Product.ID == 0 {
+			s.logger.Errorw("product not available", "productID", item.ProductID)
+			return nil, ErrProductNotAvailable
 		}
-		lockedProducts = append(lockedProducts, redisKey)
-
-		if inventories[item.ProductID].Stock < item.Quantity {
-			s.logger.Errorw("stock update in progress for product %d", item.ProductID)
+		if inventories[item.ProductID].Available() < item.Quantity {
+			s.logger.Errorw("not enough stock available", "productID", item.ProductID)
 			return nil, ErrNoStockAvailable
 		}
 	}
 
 	updates := map[uint]int{}
+	categories := map[uint]string{}
 	var orderItems []OrderItem
 
 	for _, item := range request.Items {
 		updates[item.ProductID] = item.Quantity
+		categories[item.ProductID] = inventories[item.ProductID].Product.Category
 
 		orderItems = append(orderItems, OrderItem{
 			ProductID: item.ProductID,
 			Quantity:  item.Quantity,
-			Price:     inventories[item.ProductID].Product.Price,
+			Price:     inventories[item.ProductID].Product.Price.Convert(exchangeRate),
 		})
 	}
-	if err := s.inventoryService.DecreaseStockBulk(ctx, updates); err != nil {
-		s.logger.Errorw("failed to decrease stock bulk", "error", err)
+
+	order := NewOrder(request.UserID, orderCurrency, exchangeRate, region, orderItems, time.Now().Add(s.configuration.ReservationTTL))
+	order.ShippingAddress = shippingAddress
+	if err := s.applyPromotions(ctx, order, request.Coupons, nil); err != nil {
 		return nil, err
 	}
-
-	order := NewOrder(request.UserID, orderItems)
-	err = s.store.Create(ctx, order)
-	if err != nil {
-		s.logger.Errorw("failed to store order", "error", err)
+	if err := s.applyTax(ctx, order, categories); err != nil {
 		return nil, err
 	}
 
-	go func() {
-		order, err = s.store.Get(ctx, order.ID)
-		if err != nil {
-			s.logger.Errorw("failed to get order", "error", err)
+	err = s.transactor.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		if err := s.store.WithTx(tx).Create(ctx, order); err != nil {
+			s.logger.Errorw("failed to store order", "error", err)
+			return err
 		}
 
-		err = s.meilisearchService.Add(*order)
-		if err != nil {
-			s.logger.Errorw("failed to update order", "error", err)
+		if err := s.redeemPromotions(ctx, tx, nil, order.promotionIDs()); err != nil {
+			return err
 		}
-	}()
+
+		if err := s.inventoryService.WithTx(tx).Reserve(ctx, order.ID, updates); err != nil {
+			if errors.Is(err, inventory.ErrInsufficientStock) {
+				return ErrNoStockAvailable
+			}
+			s.logger.Errorw("failed to reserve stock", "error", err)
+			return err
+		}
+
+		if err := s.addOutboxEvent(ctx, tx, order.ID, OutboxOrderCreated); err != nil {
+			return err
+		}
+		return s.checkStockLock(stock)
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	return &CreateOrderResponse{ID: order.ID}, nil
 }
 
 func (s *service) Update(ctx context.Context, request PutRequest) error {
+	if err := validateItems(request.Items); err != nil {
+		return err
+	}
+
 	existingOrder, err := s.store.Get(ctx, request.ID)
 	if err != nil {
 		s.logger.Errorw("error getting existing order", "error", err, "id", request.ID)
 		return fmt.Errorf("order not found: %w", err)
 	}
 
+	if !existingOrder.Status.IsEditable() {
+		s.logger.Errorw("order is not editable", "id", request.ID, "status", existingOrder.Status)
+		return ErrOrderNotEditable
+	}
+
 	productIDs := make(map[uint]struct{})
 	for _, item := range existingOrder.Items {
 		productIDs[item.ProductID] = struct{}{}
@@ -129,95 +320,138 @@ func (s *service) Update(ctx context.Context, request PutRequest) error {
 		ids = append(ids, id)
 	}
 
-	inventories, err := s.inventoryService.GetMultiple(ctx, ids)
+	stock, err := s.lockProducts(ctx, ids)
+	defer s.unlock(ctx, stock)
 	if err != nil {
-		s.logger.Errorw("error getting inventory", "error", err, "id", ids)
 		return err
 	}
 
-	var lockedProducts []string
-
-	defer func() {
-		for _, key := range lockedProducts {
-			s.redisClient.Del(ctx, key)
-		}
-	}()
-
-	for _, productID := range ids {
-		redisKey := s.getLockProductKey(productID)
-		lock := s.redisClient.SetNX(ctx, redisKey, "locked", 5*time.Second)
-		if !lock.Val() {
-			s.logger.Errorw("stock update in progress", "productID", productID)
-			return ErrStockUpdateInProgress
-		}
-		lockedProducts = append(lockedProducts, redisKey)
+	inventories, err := s.inventoryService.GetMultiple(ctx, ids)
+	if err != nil {
+		s.logger.Errorw("error getting inventory", "error", err, "id", ids)
+		return err
 	}
 
-	existingUpdates := map[uint]int{}
-	for _, item := range existingOrder.Items {
-		existingUpdates[item.ProductID] = item.Quantity
+	existingUpdates := existingOrder.quantities()
+	reserved := existingOrder.Status.HoldsReservation()
+	if reserved && existingOrder.reservationExpired(time.Now()) {
+		return ErrReservationExpired
 	}
 
 	for _, item := range request.Items {
-		if inventories[item.ProductID].Stock+existingUpdates[item.ProductID] < item.Quantity {
+		if inventories[item.ProductID].Product.ID == 0 {
+			s.logger.Errorw("product not available", "productID", item.ProductID)
+			return ErrProductNotAvailable
+		}
+		// units reserved for other orders are not available, the ones this order holds are given back first
+		if inventories[item.ProductID].Available()+existingUpdates[item.ProductID] < item.Quantity {
 			s.logger.Errorw("not enough stock for product %d", item.ProductID)
 			return ErrNoStockAvailable
 		}
 	}
 
-	if err := s.inventoryService.IncreaseStockBulk(ctx, existingUpdates); err != nil {
-		s.logger.Errorw("error increasing stock bulk", "error", err, "id", existingOrder.ID)
-		return err
-	}
-
 	updates := map[uint]int{}
+	categories := map[uint]string{}
 	var orderItems []OrderItem
 
 	for _, item := range request.Items {
 		updates[item.ProductID] = item.Quantity
+		categories[item.ProductID] = inventories[item.ProductID].Product.Category
 
 		orderItems = append(orderItems, OrderItem{
 			ProductID: item.ProductID,
 			Quantity:  item.Quantity,
-			Price:     inventories[item.ProductID].Product.Price,
+			Price:     inventories[item.ProductID].Product.Price.Convert(existingOrder.ExchangeRate),
 		})
 	}
-	if err := s.inventoryService.DecreaseStockBulk(ctx, updates); err != nil {
-		s.logger.Errorw("error decreasing stock", "error", err, "id", request.ID)
-		return err
-	}
 
 	toDelete := make([]uint, len(existingOrder.Items))
 	for i, item := range existingOrder.Items {
 		toDelete[i] = item.ID
 	}
 
-	if err := s.store.DeleteOrderItems(ctx, toDelete); err != nil {
-		s.logger.Errorw("error deleting items", "error", err, "id", request.ID)
-		return err
+	// promotions the order already redeemed keep applying, even once they ended or ran out
+	redeemed := existingOrder.promotionCodes()
+	redeemedIDs := existingOrder.promotionIDs()
+	codes := redeemed
+	if request.Coupons != nil {
+		codes = request.Coupons
 	}
 
-	existingOrder.Items = orderItems
-
-	err = s.store.Update(ctx, existingOrder)
-	if err != nil {
-		s.logger.Errorw("error updating order", "error", err, "id", request.ID)
+	existingOrder.setItems(orderItems)
+	if err := s.applyPromotions(ctx, existingOrder, codes, redeemed); err != nil {
+		return err
+	}
+	if err := s.applyTax(ctx, existingOrder, categories); err != nil {
 		return err
 	}
 
-	go func() {
-		existingOrder, err = s.store.Get(ctx, existingOrder.ID)
-		if err != nil {
-			s.logger.Errorw("failed to get order", "error", err)
+	return s.transactor.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		inventoryService := s.inventoryService.WithTx(tx)
+		store := s.store.WithTx(tx)
+
+		if err := s.lockOrder(ctx, store, existingOrder.ID, existingOrder.Status, existingUpdates); err != nil {
+			return err
 		}
 
-		err = s.meilisearchService.Update(*existingOrder)
-		if err != nil {
-			s.logger.Errorw("failed to update order", "error", err)
+		if reserved {
+			if err := inventoryService.ReleaseReservations(ctx, existingOrder.ID, inventory.ReservationReleased); err != nil {
+				s.logger.Errorw("error releasing reservations", "error", err, "id", existingOrder.ID)
+				return err
+			}
+
+			if err := inventoryService.Reserve(ctx, existingOrder.ID, updates); err != nil {
+				if errors.Is(err, inventory.ErrInsufficientStock) {
+					return ErrNoStockAvailable
+				}
+				s.logger.Errorw("error reserving stock", "error", err, "id", request.ID)
+				return err
+			}
+
+			reservedUntil := time.Now().Add(s.configuration.ReservationTTL)
+			existingOrder.ReservedUntil = &reservedUntil
+		} else {
+			reference := inventory.Reference{Reason: inventory.ReasonOrderUpdated, OrderID: existingOrder.ID}
+			if err := inventoryService.IncreaseStockBulk(ctx, existingUpdates, reference); err != nil {
+				s.logger.Errorw("error increasing stock bulk", "error", err, "id", existingOrder.ID)
+				return err
+			}
+
+			if err := inventoryService.DecreaseStockBulk(ctx, updates, reference); err != nil {
+				if errors.Is(err, inventory.ErrInsufficientStock) {
+					return ErrNoStockAvailable
+				}
+				s.logger.Errorw("error decreasing stock", "error", err, "id", request.ID)
+				return err
+			}
 		}
-	}()
 
-	return nil
+		if err := store.DeleteOrderItems(ctx, toDelete); err != nil {
+			s.logger.Errorw("error deleting items", "error", err, "id", request.ID)
+			return err
+		}
+
+		if len(redeemedIDs) > 0 {
+			if err := store.DeleteOrderPromotions(ctx, existingOrder.ID); err != nil {
+				s.logger.Errorw("error deleting order promotions", "error", err, "id", request.ID)
+				return err
+			}
+		}
+
+		if err := s.redeemPromotions(ctx, tx, redeemedIDs, existingOrder.promotionIDs()); err != nil {
+			return err
+		}
+
+		if err := store.Update(ctx, existingOrder); err != nil {
+			s.logger.Errorw("error updating order", "error", err, "id", request.ID)
+			return err
+		}
+
+		if err := s.addOutboxEvent(ctx, tx, existingOrder.ID, OutboxOrderUpdated); err != nil {
+			return err
+		}
+		return s.checkStockLock(stock)
+	})
 }
 
 func (s *service) Get(ctx context.Context, id uint) (*OrderResponse, error) {
@@ -229,6 +463,40 @@ func (s *service) Get(ctx context.Context, id uint) (*OrderResponse, error) {
 	return order.ToResponse(), nil
 }
 
+func (s *service) ListByUser(ctx context.Context, request ListByUserRequest) (*ListOrdersResponse, error) {
+	if err := s.checkUser(ctx, request.UserID); err != nil {
+		return nil, err
+	}
+
+	if request.Limit <= 0 {
+		request.Limit = defaultListLimit
+	}
+	if request.Limit > maxListLimit {
+		request.Limit = maxListLimit
+	}
+	if request.Offset < 0 {
+		request.Offset = 0
+	}
+
+	orders, total, err := s.store.ListByUser(ctx, request.UserID, request.Limit, request.Offset)
+	if err != nil {
+		s.logger.Errorw("error listing user orders", "error", err, "userID", request.UserID)
+		return nil, err
+	}
+
+	items := make([]OrderResponse, len(orders))
+	for i := range orders {
+		items[i] = *orders[i].ToResponse()
+	}
+
+	return &ListOrdersResponse{
+		Items:  items,
+		Total:  total,
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}, nil
+}
+
 func (s *service) Delete(ctx context.Context, id uint) error {
 	order, err := s.store.Get(ctx, id)
 	if err != nil {
@@ -236,63 +504,325 @@ func (s *service) Delete(ctx context.Context, id uint) error {
 		return fmt.Errorf("order not found: %w", err)
 	}
 
-	productIDs := make([]uint, len(order.Items))
-	for i, item := range order.Items {
-		productIDs[i] = item.ProductID
+	if !order.Status.IsDeletable() {
+		s.logger.Errorw("order is not deletable", "id", id, "status", order.Status)
+		return ErrOrderNotEditable
 	}
 
-	var lockedProducts []string
+	restock := order.Status.HoldsStock()
+	var stock stockLock
+	if restock {
+		stock, err = s.lockProducts(ctx, order.productIDs())
+		defer s.unlock(ctx, stock)
+		if err != nil {
+			return err
+		}
+	}
+
+	var orderItemIDs []uint
+	for _, item := range order.Items {
+		orderItemIDs = append(orderItemIDs, item.ID)
+	}
+
+	return s.transactor.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		store := s.store.WithTx(tx)
+
+		if err := s.lockOrder(ctx, store, order.ID, order.Status, order.quantities()); err != nil {
+			return err
+		}
 
-	defer func() {
-		for _, key := range lockedProducts {
-			s.redisClient.Del(ctx, key)
+		if restock {
+			reference := inventory.Reference{Reason: inventory.ReasonOrderDeleted, OrderID: order.ID}
+			if err := s.inventoryService.WithTx(tx).IncreaseStockBulk(ctx, order.quantities(), reference); err != nil {
+				s.logger.Errorw("error increasing stock", "error", err, "id", id)
+				return err
+			}
 		}
-	}()
 
-	for _, productID := range productIDs {
-		redisKey := s.getLockProductKey(productID)
-		lock := s.redisClient.SetNX(ctx, redisKey, "locked", 5*time.Second)
-		if !lock.Val() {
-			s.logger.Errorw("stock update in progress", "productID", productID)
-			return ErrStockUpdateInProgress
+		if order.Status.HoldsReservation() {
+			if err := s.inventoryService.WithTx(tx).ReleaseReservations(ctx, order.ID, inventory.ReservationReleased); err != nil {
+				s.logger.Errorw("error releasing reservations", "error", err, "id", id)
+				return err
+			}
 		}
-		lockedProducts = append(lockedProducts, redisKey)
+
+		if len(orderItemIDs) > 0 {
+			if err := store.DeleteOrderItems(ctx, orderItemIDs); err != nil {
+				s.logger.Errorw("error bulk deleting order items", "error", err, "orderItemIDs", orderItemIDs)
+				return err
+			}
+		}
+
+		if len(order.Promotions) > 0 {
+			if err := store.DeleteOrderPromotions(ctx, id); err != nil {
+				s.logger.Errorw("error deleting order promotions", "error", err, "id", id)
+				return err
+			}
+
+			if err := s.redeemPromotions(ctx, tx, order.promotionIDs(), nil); err != nil {
+				return err
+			}
+		}
+
+		if err := store.Delete(ctx, id); err != nil {
+			s.logger.Errorw("error deleting order", "error", err, "id", id)
+			return err
+		}
+
+		if err := s.addOutboxEvent(ctx, tx, id, OutboxOrderDeleted); err != nil {
+			return err
+		}
+		return s.checkStockLock(stock)
+	})
+}
+
+func (s *service) Transition(ctx context.Context, request TransitionRequest) (*OrderResponse, error) {
+	if !request.Status.IsValid() {
+		return nil, ErrInvalidStatus
 	}
 
-	updates := map[uint]int{}
-	for _, item := range order.Items {
-		updates[item.ProductID] = item.Quantity
+	order, err := s.store.Get(ctx, request.ID)
+	if err != nil {
+		s.logger.Errorw("failed to get order", "error", err, "id", request.ID)
+		return nil, fmt.Errorf("order not found: %w", err)
+	}
+
+	allowed := order.Status.CanTransitionTo(request.Status)
+	if request.Client {
+		allowed = order.Status.CanClientTransitionTo(request.Status)
+	}
+	if !allowed {
+		return nil, &TransitionError{From: order.Status, To: request.Status}
+	}
+
+	restock := order.Status.RestocksOn(request.Status)
+	var stock stockLock
+	if restock {
+		stock, err = s.lockProducts(ctx, order.productIDs())
+		defer s.unlock(ctx, stock)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if order.Status.HoldsReservation() && request.Status == StatusConfirmed && order.reservationExpired(time.Now()) {
+		return nil, ErrReservationExpired
+	}
+
+	err = s.transactor.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		inventoryService := s.inventoryService.WithTx(tx)
+
+		if err := s.lockOrder(ctx, s.store.WithTx(tx), order.ID, order.Status, order.quantities()); err != nil {
+			return err
+		}
+
+		if restock {
+			reference := inventory.Reference{Reason: restockReason(request.Status), OrderID: order.ID}
+			if err := inventoryService.IncreaseStockBulk(ctx, order.quantities(), reference); err != nil {
+				s.logger.Errorw("error increasing stock", "error", err, "id", order.ID)
+				return err
+			}
+		}
+
+		if order.Status.HoldsReservation() {
+			if err := s.settleReservations(ctx, inventoryService, order, request.Status); err != nil {
+				return err
+			}
+		}
+
+		if err := s.store.WithTx(tx).UpdateStatus(ctx, order.ID, order.Status, request.Status); err != nil {
+			s.logger.Errorw("error updating order status", "error", err, "id", order.ID)
+			return err
+		}
+
+		if err := s.addOutbox(ctx, tx, newStatusOutboxEvent(order.ID, order.Status, request.Status)); err != nil {
+			return err
+		}
+		return s.checkStockLock(stock)
+	})
+	if err != nil {
+		return nil, err
+	}
+	order.Status = request.Status
+
+	return order.ToResponse(), nil
+}
+
+// lockOrder locks the order row for the rest of the transaction and checks the order still has the status and items
+// the change was worked out from, so that concurrent changes of the same order cannot both restock or reserve its items.
+func (s *service) lockOrder(ctx context.Context, store IStore, id uint, status Status, quantities map[uint]int) error {
+	locked, err := store.GetForUpdate(ctx, id)
+	if err != nil {
+		s.logger.Errorw("error locking order", "error", err, "id", id)
+		return err
+	}
+	if locked.Status != status || !maps.Equal(locked.quantities(), quantities) {
+		s.logger.Warnw("order changed concurrently", "id", id, "status", status, "lockedStatus", locked.Status)
+		return ErrOrderChanged
 	}
-	if err := s.inventoryService.IncreaseStockBulk(ctx, updates); err != nil {
-		s.logger.Errorw("error increasing stock", "error", err, "id", id)
+	return nil
+}
+
+// settleReservations commits the reservations of a pending order when it gets confirmed and releases them otherwise.
+func (s *service) settleReservations(ctx context.Context, inventoryService inventory.IService, order *Order, next Status) error {
+	if next != StatusConfirmed {
+		if err := inventoryService.ReleaseReservations(ctx, order.ID, inventory.ReservationReleased); err != nil {
+			s.logger.Errorw("error releasing reservations", "error", err, "id", order.ID)
+			return err
+		}
+		return nil
+	}
+
+	reference := inventory.Reference{Reason: inventory.ReasonOrderConfirmed, OrderID: order.ID}
+	err := inventoryService.CommitReservations(ctx, order.ID, reference)
+	if errors.Is(err, inventory.ErrNoReservation) {
+		// orders placed before reservations existed had their stock decremented on creation
+		s.logger.Warnw("confirming order without reservations", "id", order.ID)
+		return nil
+	}
+	if errors.Is(err, inventory.ErrInsufficientStock) {
+		return ErrNoStockAvailable
+	}
+	if err != nil {
+		s.logger.Errorw("error committing reservations", "error", err, "id", order.ID)
 		return err
 	}
+	return nil
+}
 
-	var orderItemIDs []uint
-	for _, item := range order.Items {
-		orderItemIDs = append(orderItemIDs, item.ID)
+// applyPromotions works out the discounts of the coupon codes on the order items.
+func (s *service) applyPromotions(ctx context.Context, order *Order, codes []string, redeemed []string) error {
+	if len(codes) == 0 {
+		order.setPromotions(nil)
+		return nil
+	}
+
+	discounts, err := s.promotionService.Apply(ctx, promotion.ApplyRequest{Codes: codes, Redeemed: redeemed, Cart: order.cart()})
+	if err != nil {
+		s.logger.Errorw("error applying promotions", "error", err, "codes", codes)
+		return err
+	}
+
+	order.setPromotions(discounts)
+	return nil
+}
+
+// applyTax works out the tax of the order items once the order discount is known, as tax is due on what is paid.
+func (s *service) applyTax(ctx context.Context, order *Order, categories map[uint]string) error {
+	taxes, err := s.taxCalculator.Calculate(ctx, order.taxRequest(categories))
+	if err != nil {
+		s.logger.Errorw("error calculating tax", "error", err, "region", order.Region)
+		return err
+	}
+
+	order.setTaxes(taxes)
+	return nil
+}
+
+// redeemPromotions counts the uses of the promotions an order applies now and gives back the ones it no longer applies.
+func (s *service) redeemPromotions(ctx context.Context, tx *gorm.DB, previous []uint, current []uint) error {
+	var released, redeemed []uint
+	for _, promotionID := range previous {
+		if !slices.Contains(current, promotionID) {
+			released = append(released, promotionID)
+		}
+	}
+	for _, promotionID := range current {
+		if !slices.Contains(previous, promotionID) {
+			redeemed = append(redeemed, promotionID)
+		}
 	}
 
-	if len(orderItemIDs) > 0 {
-		if err := s.store.DeleteOrderItems(ctx, orderItemIDs); err != nil {
-			s.logger.Errorw("error bulk deleting order items", "error", err, "orderItemIDs", orderItemIDs)
+	promotionService := s.promotionService.WithTx(tx)
+	if len(released) > 0 {
+		if err := promotionService.Release(ctx, released); err != nil {
+			return err
+		}
+	}
+	if len(redeemed) > 0 {
+		if err := promotionService.Redeem(ctx, redeemed); err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
-	err = s.store.Delete(ctx, id)
+func (s *service) checkUser(ctx context.Context, userID uint) error {
+	_, err := s.userService.Get(ctx, userID)
 	if err != nil {
-		s.logger.Errorw("error deleting order", "error", err, "id", id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		s.logger.Errorw("error getting user", "error", err, "userID", userID)
 		return err
 	}
+	return nil
+}
 
-	go func() {
-		err = s.meilisearchService.Delete(id)
-		if err != nil {
-			s.logger.Errorw("failed to update order", "error", err)
+// addOutboxEvent queues the order for publishing as part of the transaction that changed it.
+func (s *service) addOutboxEvent(ctx context.Context, tx *gorm.DB, orderID uint, eventType OutboxEventType) error {
+	return s.addOutbox(ctx, tx, newOutboxEvent(orderID, eventType))
+}
+
+func (s *service) addOutbox(ctx context.Context, tx *gorm.DB, event *OutboxEvent) error {
+	if err := s.outboxStore.WithTx(tx).Add(ctx, event); err != nil {
+		s.logger.Errorw("error adding outbox event", "error", err, "id", event.OrderID, "type", event.Type)
+		return err
+	}
+	return nil
+}
+
+func restockReason(status Status) inventory.Reason {
+	if status == StatusRefunded {
+		return inventory.ReasonOrderRefunded
+	}
+	return inventory.ReasonOrderCancelled
+}
+
+// stockLock is the lock on the stock of the products an order change touches. The zero value locks nothing.
+type stockLock struct {
+	held       lock.ILock
+	productIDs []uint
+}
+
+func (s *service) lockProducts(ctx context.Context, productIDs []uint) (stockLock, error) {
+	keys := make([]string, len(productIDs))
+	for i, productID := range productIDs {
+		keys[i] = s.getLockProductKey(productID)
+	}
+
+	held, err := s.locker.Acquire(ctx, keys...)
+	if err != nil {
+		if errors.Is(err, lock.ErrNotAcquired) {
+			s.logger.Errorw("stock update in progress", "productIDs", productIDs)
+			return stockLock{}, ErrStockUpdateInProgress
 		}
-	}()
+		s.logger.Errorw("error acquiring stock lock", "error", err, "productIDs", productIDs)
+		return stockLock{}, err
+	}
+	return stockLock{held: held, productIDs: productIDs}, nil
+}
+
+// unlock releases the stock lock, also when the request that took it was cancelled.
+func (s *service) unlock(ctx context.Context, stock stockLock) {
+	if stock.held == nil {
+		return
+	}
+	if err := stock.held.Release(context.WithoutCancel(ctx)); err != nil {
+		s.logger.Warnw("error releasing stock lock", "error", err, "productIDs", stock.productIDs)
+	}
+}
 
+// checkStockLock fails the transaction the stock lock guards once the lock was lost, so that it rolls back
+// instead of committing stock changes someone else may be making as well.
+func (s *service) checkStockLock(stock stockLock) error {
+	if stock.held == nil {
+		return nil
+	}
+	if err := stock.held.Err(); err != nil {
+		s.logger.Errorw("stock lock lost", "error", err, "productIDs", stock.productIDs)
+		return fmt.Errorf("%w: %w", ErrStockUpdateInProgress, err)
+	}
 	return nil
 }
 
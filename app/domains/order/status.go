@@ -0,0 +1,80 @@
+package order
+
+import "slices"
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusConfirmed Status = "confirmed"
+	StatusPaid      Status = "paid"
+	StatusShipped   Status = "shipped"
+	StatusDelivered Status = "delivered"
+	StatusCancelled Status = "cancelled"
+	StatusRefunded  Status = "refunded"
+	StatusExpired   Status = "expired"
+	// StatusPartiallyReturned and StatusReturned are reached when the goods of some or all of the items come back.
+	StatusPartiallyReturned Status = "partially_returned"
+	StatusReturned          Status = "returned"
+)
+
+// clientTransitions are the moves clients can make through the API.
+var clientTransitions = map[Status][]Status{
+	StatusPending:   {StatusConfirmed, StatusCancelled},
+	StatusConfirmed: {StatusCancelled},
+}
+
+// internalTransitions are the moves only the payment, shipment and return services make, as the money or goods move.
+var internalTransitions = map[Status][]Status{
+	StatusPending:   {},
+	StatusConfirmed: {StatusPaid},
+	StatusPaid:      {StatusShipped, StatusRefunded},
+	StatusShipped:   {StatusDelivered},
+	StatusDelivered: {StatusRefunded, StatusPartiallyReturned, StatusReturned},
+	StatusCancelled: {},
+	StatusRefunded:  {},
+	StatusExpired:   {},
+
+	StatusPartiallyReturned: {StatusReturned},
+	StatusReturned:          {},
+}
+
+func (s Status) IsValid() bool {
+	_, ok := internalTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether the domain services can move the order to next.
+func (s Status) CanTransitionTo(next Status) bool {
+	return s.CanClientTransitionTo(next) || slices.Contains(internalTransitions[s], next)
+}
+
+// CanClientTransitionTo reports whether a client can move the order to next.
+func (s Status) CanClientTransitionTo(next Status) bool {
+	return slices.Contains(clientTransitions[s], next)
+}
+
+// IsEditable reports whether the order items can still be changed.
+func (s Status) IsEditable() bool {
+	return s == StatusPending || s == StatusConfirmed
+}
+
+// IsDeletable reports whether the order can be removed altogether.
+func (s Status) IsDeletable() bool {
+	return s.IsEditable() || s == StatusCancelled || s == StatusExpired
+}
+
+// HoldsReservation reports whether the order items are only reserved and not yet deducted from the inventory.
+func (s Status) HoldsReservation() bool {
+	return s == StatusPending
+}
+
+// HoldsStock reports whether the order items are still deducted from the inventory.
+func (s Status) HoldsStock() bool {
+	return s == StatusConfirmed || s == StatusPaid
+}
+
+// RestocksOn reports whether moving to next gives the order items back to the inventory.
+func (s Status) RestocksOn(next Status) bool {
+	return s.HoldsStock() && (next == StatusCancelled || next == StatusRefunded)
+}
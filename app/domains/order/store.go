@@ -4,15 +4,26 @@ import (
 	"context"
 	"fmt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"slices"
+	"time"
 )
 
 type IStore interface {
 	Create(ctx context.Context, order *Order) error
 	Get(ctx context.Context, id uint) (*Order, error)
+	GetForUpdate(ctx context.Context, id uint) (*Order, error)
 	Update(ctx context.Context, order *Order) error
+	UpdateStatus(ctx context.Context, id uint, from Status, to Status) error
 	Delete(ctx context.Context, id uint) error
 	DeleteOrderItems(ctx context.Context, orderItemIDs []uint) error
-	Fetch(size int, offset int) ([]Order, error)
+	DeleteOrderPromotions(ctx context.Context, orderID uint) error
+	List(ctx context.Context, request ListRequest) ([]Order, int64, error)
+	ListAfter(ctx context.Context, after Cursor, limit int) ([]Order, error)
+	ListUpdatedSince(ctx context.Context, since time.Time, afterID uint, limit int) ([]Order, error)
+	ListByUser(ctx context.Context, userID uint, limit int, offset int) ([]Order, int64, error)
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]Order, error)
+	WithTx(tx *gorm.DB) IStore
 }
 
 type store struct {
@@ -23,6 +34,10 @@ func NewStore(db *gorm.DB) IStore {
 	return &store{db: db}
 }
 
+func (s *store) WithTx(tx *gorm.DB) IStore {
+	return &store{db: tx}
+}
+
 func (s *store) Create(ctx context.Context, order *Order) error {
 	return s.db.WithContext(ctx).Create(order).Error
 }
@@ -32,7 +47,21 @@ func (s *store) Get(ctx context.Context, id uint) (*Order, error) {
 	err := s.db.
 		WithContext(ctx).
 		Preload("Items").
-		Preload("Items.Product").
+		Preload("Items.Product", withDeletedProducts).
+		Preload("Promotions").
+		Where("id = ?", id).
+		First(&order).Error
+
+	return &order, err
+}
+
+// GetForUpdate reads the order and locks its row until the transaction ends.
+func (s *store) GetForUpdate(ctx context.Context, id uint) (*Order, error) {
+	var order Order
+	err := s.db.
+		WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Items").
 		Where("id = ?", id).
 		First(&order).Error
 
@@ -43,6 +72,20 @@ func (s *store) Update(ctx context.Context, order *Order) error {
 	return s.db.WithContext(ctx).Save(order).Error
 }
 
+func (s *store) UpdateStatus(ctx context.Context, id uint, from Status, to Status) error {
+	result := s.db.WithContext(ctx).
+		Model(&Order{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &TransitionError{From: from, To: to}
+	}
+	return nil
+}
+
 func (s *store) Delete(ctx context.Context, id uint) error {
 	return s.db.WithContext(ctx).Where("id = ?", id).Delete(&Order{}).Error
 }
@@ -54,17 +97,203 @@ func (s *store) DeleteOrderItems(ctx context.Context, orderItemIDs []uint) error
 	return nil
 }
 
-func (s *store) Fetch(size int, offset int) ([]Order, error) {
+func (s *store) DeleteOrderPromotions(ctx context.Context, orderID uint) error {
+	if err := s.db.WithContext(ctx).Where("order_id = ?", orderID).Delete(&OrderPromotion{}).Error; err != nil {
+		return fmt.Errorf("failed to delete order promotions: %w", err)
+	}
+	return nil
+}
+
+// List is the database counterpart of the search index, used when Meilisearch cannot serve the request.
+// Input is matched against the name and description of the ordered products.
+func (s *store) List(ctx context.Context, request ListRequest) ([]Order, int64, error) {
+	counts := s.db.
+		Table("order_items").
+		Select("order_id, SUM(quantity) AS item_count").
+		Group("order_id")
+
+	query := s.db.WithContext(ctx).
+		Model(&Order{}).
+		Joins("LEFT JOIN (?) AS counts ON counts.order_id = orders.id", counts)
+
+	if request.Input != "" {
+		pattern := "%" + request.Input + "%"
+		matching := s.db.
+			Table("order_items").
+			Select("order_items.order_id").
+			Joins("JOIN products ON products.id = order_items.product_id").
+			Where("products.name LIKE ? OR products.description LIKE ?", pattern, pattern)
+		query = query.Where("orders.id IN (?)", matching)
+	}
+	if len(request.ProductIDs) > 0 {
+		matching := s.db.
+			Table("order_items").
+			Select("order_id").
+			Where("product_id IN ?", request.ProductIDs)
+		query = query.Where("orders.id IN (?)", matching)
+	}
+	if request.StartDate != nil {
+		query = query.Where("orders.created_at >= ?", request.StartDate)
+	}
+	if request.EndDate != nil {
+		query = query.Where("orders.created_at <= ?", request.EndDate)
+	}
+	if request.UserID != 0 {
+		query = query.Where("orders.user_id = ?", request.UserID)
+	}
+	if request.Status != "" {
+		query = query.Where("orders.status = ?", request.Status)
+	}
+	if request.Currency != "" {
+		query = query.Where("orders.currency = ?", request.Currency)
+	}
+	if request.MinTotal != nil {
+		query = query.Where("orders.total >= ?", *request.MinTotal)
+	}
+	if request.MaxTotal != nil {
+		query = query.Where("orders.total <= ?", *request.MaxTotal)
+	}
+	if request.MinItems != nil {
+		query = query.Where("COALESCE(counts.item_count, 0) >= ?", *request.MinItems)
+	}
+	if request.MaxItems != nil {
+		query = query.Where("COALESCE(counts.item_count, 0) <= ?", *request.MaxItems)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count orders: %w", err)
+	}
+
+	if position := request.position(); position != nil {
+		operator := ">"
+		if request.scansDescending() {
+			operator = "<"
+		}
+		query = query.Where(
+			fmt.Sprintf("(orders.created_at %s ? OR (orders.created_at = ? AND orders.id %s ?))", operator, operator),
+			position.CreatedAt, position.CreatedAt, position.ID,
+		)
+	}
+
+	var orders []Order
+	err := query.
+		Preload("Items").
+		Preload("Items.Product", withDeletedProducts).
+		Preload("Promotions").
+		Order(listOrderBy(request)).
+		Limit(int(request.Limit)).
+		Offset(int(request.Offset)).
+		Find(&orders).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	if request.Before != nil {
+		slices.Reverse(orders)
+	}
+	return orders, total, nil
+}
+
+// listOrderBy sorts like the search index; without a direction the newest or largest orders come first.
+func listOrderBy(request ListRequest) string {
+	if request.pagesByKeyset() {
+		if request.scansDescending() {
+			return "orders.created_at DESC, orders.id DESC"
+		}
+		return "orders.created_at ASC, orders.id ASC"
+	}
+
+	column := "orders.created_at"
+	switch request.SortBy {
+	case SortTotal:
+		column = "orders.total"
+	case SortStatus:
+		column = "orders.status"
+	}
+
+	direction := "DESC"
+	if request.direction() == SortAsc {
+		direction = "ASC"
+	}
+	return fmt.Sprintf("%s %s, orders.id %s", column, direction, direction)
+}
+
+// ListAfter pages through all orders past the after position, oldest first.
+func (s *store) ListAfter(ctx context.Context, after Cursor, limit int) ([]Order, error) {
 	var orders []Order
 	err := s.db.
+		WithContext(ctx).
 		Preload("Items").
-		Preload("Items.Product").
-		Limit(size).
+		Preload("Items.Product", withDeletedProducts).
+		Preload("Promotions").
+		Where("created_at > ? OR (created_at = ? AND id > ?)", after.CreatedAt, after.CreatedAt, after.ID).
+		Order("created_at, id").
+		Limit(limit).
+		Find(&orders).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+	return orders, nil
+}
+
+// ListUpdatedSince pages through the orders changed after the (since, afterID) position, oldest change first.
+func (s *store) ListUpdatedSince(ctx context.Context, since time.Time, afterID uint, limit int) ([]Order, error) {
+	var orders []Order
+	err := s.db.
+		WithContext(ctx).
+		Preload("Items").
+		Preload("Items.Product", withDeletedProducts).
+		Preload("Promotions").
+		Where("updated_at > ? OR (updated_at = ? AND id > ?)", since, since, afterID).
+		Order("updated_at, id").
+		Limit(limit).
+		Find(&orders).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list updated orders: %w", err)
+	}
+	return orders, nil
+}
+
+func (s *store) ListByUser(ctx context.Context, userID uint, limit int, offset int) ([]Order, int64, error) {
+	query := s.db.WithContext(ctx).Model(&Order{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count user orders: %w", err)
+	}
+
+	var orders []Order
+	err := query.
+		Preload("Items").
+		Preload("Items.Product", withDeletedProducts).
+		Preload("Promotions").
+		Order("created_at DESC, id DESC").
+		Limit(limit).
 		Offset(offset).
-		Find(&orders).
-		Error
+		Find(&orders).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list user orders: %w", err)
+	}
+	return orders, total, nil
+}
+
+// ListExpired returns pending orders whose stock reservation ran out.
+func (s *store) ListExpired(ctx context.Context, now time.Time, limit int) ([]Order, error) {
+	var orders []Order
+	err := s.db.
+		WithContext(ctx).
+		Where("status = ? AND reserved_until <= ?", StatusPending, now).
+		Order("reserved_until").
+		Limit(limit).
+		Find(&orders).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch orders: %w", err)
+		return nil, fmt.Errorf("failed to list expired orders: %w", err)
 	}
 	return orders, nil
 }
+
+// withDeletedProducts keeps soft-deleted products attached to the orders that already reference them.
+func withDeletedProducts(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
@@ -0,0 +1,90 @@
+package order
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/p4xx07/order-service/app/domains/inventory"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/event"
+	"github.com/p4xx07/order-service/pkg/eventstream"
+	"go.uber.org/zap"
+	"time"
+)
+
+// SubscriberEventStream is the stream publisher as subscriber of the order events.
+const SubscriberEventStream event.Subscriber = "event_stream"
+
+// IStreamPublisher publishes the order and stock events to the EVENT_STREAM Redis Stream for other services.
+// Order events are published synchronously, so a failed publish is retried through the outbox.
+// Stock events, low-stock alerts included, are published asynchronously, as nothing would retry them anyway.
+type IStreamPublisher interface {
+	Subscribe(bus event.IBus)
+}
+
+// streamEvent is an event as published to other services.
+type streamEvent interface {
+	event.Event
+	Data() interface{}
+}
+
+type streamPublisher struct {
+	configuration *configuration.Configuration
+	logger        *zap.SugaredLogger
+	publisher     *eventstream.Publisher
+}
+
+func NewStreamPublisher(publisher *eventstream.Publisher, configuration *configuration.Configuration, logger *zap.SugaredLogger) IStreamPublisher {
+	return &streamPublisher{publisher: publisher, configuration: configuration, logger: logger}
+}
+
+func (p *streamPublisher) Subscribe(bus event.IBus) {
+	if p.configuration.EventStream == "" {
+		return
+	}
+
+	for _, name := range []event.Name{EventOrderCreated, EventOrderUpdated, EventOrderDeleted, EventOrderStatusChanged} {
+		bus.Subscribe(SubscriberEventStream, name, p.publish)
+	}
+	for _, name := range []event.Name{inventory.EventStockDecreased, inventory.EventStockIncreased, inventory.EventStockLow} {
+		bus.SubscribeAsync(name, p.publish)
+	}
+}
+
+func (p *streamPublisher) publish(ctx context.Context, e event.Event) error {
+	streamed, ok := e.(streamEvent)
+	if !ok {
+		return nil
+	}
+
+	id, err := streamEventID(e)
+	if err != nil {
+		return err
+	}
+
+	envelope, err := eventstream.NewEnvelope(id, string(e.EventName()), time.Now(), streamed.Data())
+	if err != nil {
+		return err
+	}
+
+	if err := p.publisher.Publish(ctx, envelope); err != nil {
+		p.logger.Errorw("error publishing to event stream", "error", err, "id", id)
+		return err
+	}
+	return nil
+}
+
+// streamEventID is the ID of the order events, which stays the same when they are published again,
+// or a random one for the events that are published only once.
+func streamEventID(e event.Event) (string, error) {
+	if identified, ok := e.(interface{ EventID() string }); ok {
+		return identified.EventID(), nil
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s.%s", e.EventName(), hex.EncodeToString(b)), nil
+}
@@ -0,0 +1,88 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"github.com/p4xx07/order-service/app/domains/inventory"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/db"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"time"
+)
+
+const sweepBatchSize = 100
+
+// IReservationSweeper expires pending orders whose stock reservation ran out and releases the reserved stock.
+type IReservationSweeper interface {
+	Run(ctx context.Context)
+	Sweep(ctx context.Context) (int, error)
+}
+
+type reservationSweeper struct {
+	configuration    *configuration.Configuration
+	logger           *zap.SugaredLogger
+	store            IStore
+	inventoryService inventory.IService
+	transactor       db.ITransactor
+	outboxStore      IOutboxStore
+}
+
+func NewReservationSweeper(store IStore, inventoryService inventory.IService, transactor db.ITransactor, outboxStore IOutboxStore, configuration *configuration.Configuration, logger *zap.SugaredLogger) IReservationSweeper {
+	return &reservationSweeper{store: store, inventoryService: inventoryService, transactor: transactor, outboxStore: outboxStore, configuration: configuration, logger: logger}
+}
+
+func (s *reservationSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.configuration.ReservationSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := s.Sweep(ctx)
+			if err != nil {
+				s.logger.Errorw("error sweeping expired reservations", "error", err)
+				continue
+			}
+			if expired > 0 {
+				s.logger.Infow("expired pending orders", "count", expired)
+			}
+		}
+	}
+}
+
+// Sweep expires one batch of orders and returns how many were expired.
+func (s *reservationSweeper) Sweep(ctx context.Context) (int, error) {
+	orders, err := s.store.ListExpired(ctx, time.Now(), sweepBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for i := range orders {
+		order := &orders[i]
+		err := s.transactor.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+			if err := s.store.WithTx(tx).UpdateStatus(ctx, order.ID, StatusPending, StatusExpired); err != nil {
+				return err
+			}
+			if err := s.inventoryService.WithTx(tx).ReleaseReservations(ctx, order.ID, inventory.ReservationExpired); err != nil {
+				return err
+			}
+			return s.outboxStore.WithTx(tx).Add(ctx, newStatusOutboxEvent(order.ID, StatusPending, StatusExpired))
+		})
+		if errors.Is(err, ErrInvalidTransition) {
+			// the order was confirmed or cancelled in the meantime
+			continue
+		}
+		if err != nil {
+			s.logger.Errorw("error expiring order", "error", err, "id", order.ID)
+			continue
+		}
+
+		expired++
+	}
+
+	return expired, nil
+}
@@ -0,0 +1,40 @@
+package order
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// ReindexWatermark remembers up to which change an index was synced by the incremental reindex.
+type ReindexWatermark struct {
+	Index     string    `gorm:"primaryKey;type:varchar(100)"`
+	SyncedAt  time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+type IWatermarkStore interface {
+	Get(ctx context.Context, index string) (*ReindexWatermark, error)
+	Save(ctx context.Context, watermark *ReindexWatermark) error
+}
+
+type watermarkStore struct {
+	db *gorm.DB
+}
+
+func NewWatermarkStore(db *gorm.DB) IWatermarkStore {
+	return &watermarkStore{db: db}
+}
+
+func (s *watermarkStore) Get(ctx context.Context, index string) (*ReindexWatermark, error) {
+	var watermark ReindexWatermark
+	err := s.db.WithContext(ctx).Where("`index` = ?", index).First(&watermark).Error
+	return &watermark, err
+}
+
+func (s *watermarkStore) Save(ctx context.Context, watermark *ReindexWatermark) error {
+	return s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(watermark).Error
+}
@@ -0,0 +1,38 @@
+package payment
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/internal/money"
+)
+
+type disabledProvider struct{}
+
+// NewDisabledProvider is the provider used when PAYMENT_PROVIDER is not set: every payment fails with
+// ErrPaymentsDisabled and every webhook is rejected.
+func NewDisabledProvider() PaymentProvider {
+	return disabledProvider{}
+}
+
+func (disabledProvider) Name() string {
+	return "disabled"
+}
+
+func (disabledProvider) Authorize(ctx context.Context, request AuthorizeRequest) (*Authorization, error) {
+	return nil, ErrPaymentsDisabled
+}
+
+func (disabledProvider) Capture(ctx context.Context, reference string, amount money.Amount) error {
+	return ErrPaymentsDisabled
+}
+
+func (disabledProvider) Void(ctx context.Context, reference string) error {
+	return ErrPaymentsDisabled
+}
+
+func (disabledProvider) Refund(ctx context.Context, reference string, amount money.Amount) error {
+	return ErrPaymentsDisabled
+}
+
+func (disabledProvider) ParseWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	return nil, ErrInvalidSignature
+}
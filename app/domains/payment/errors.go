@@ -0,0 +1,15 @@
+package payment
+
+import "errors"
+
+var (
+	ErrOrderNotPayable   = errors.New("order cannot be paid")
+	ErrAlreadyPaid       = errors.New("order already has an active payment")
+	ErrPaymentDeclined   = errors.New("payment declined")
+	ErrProviderRejected  = errors.New("payment provider rejected the operation")
+	ErrPaymentsDisabled  = errors.New("no payment provider is configured")
+	ErrInvalidTransition = errors.New("invalid payment status transition")
+	ErrInvalidAmount     = errors.New("invalid payment amount")
+	ErrInvalidSignature  = errors.New("invalid webhook signature")
+	ErrInvalidEvent      = errors.New("invalid webhook event")
+)
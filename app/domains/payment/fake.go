@@ -0,0 +1,100 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/money"
+	"sync"
+)
+
+// DeclinedToken is the payment token the fake provider declines, to try out failed payments.
+const DeclinedToken = "tok_declined"
+
+type fakePayment struct {
+	status   Status
+	amount   money.Amount
+	refunded money.Amount
+}
+
+type fakeProvider struct {
+	secret   string
+	mu       sync.Mutex
+	sequence int
+	payments map[string]*fakePayment
+}
+
+// NewFakeProvider is an in-process provider for tests and local runs. It accepts every token but DeclinedToken
+// and keeps its payments in memory; its webhooks are signed with the PAYMENT_WEBHOOK_SECRET.
+func NewFakeProvider(configuration *configuration.Configuration) PaymentProvider {
+	return &fakeProvider{secret: configuration.PaymentWebhookSecret, payments: map[string]*fakePayment{}}
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *fakeProvider) Authorize(ctx context.Context, request AuthorizeRequest) (*Authorization, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sequence++
+	reference := fmt.Sprintf("fake_%d_%d", request.OrderID, p.sequence)
+	if request.Token == DeclinedToken {
+		p.payments[reference] = &fakePayment{status: StatusFailed, amount: request.Amount}
+		return &Authorization{Reference: reference, Declined: true, Reason: "card declined"}, nil
+	}
+
+	p.payments[reference] = &fakePayment{status: StatusAuthorized, amount: request.Amount}
+	return &Authorization{Reference: reference}, nil
+}
+
+func (p *fakeProvider) Capture(ctx context.Context, reference string, amount money.Amount) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[reference]
+	if !ok || payment.status != StatusAuthorized || amount > payment.amount {
+		return fmt.Errorf("%w: cannot capture %s", ErrProviderRejected, reference)
+	}
+	payment.status = StatusCaptured
+	payment.amount = amount
+	return nil
+}
+
+func (p *fakeProvider) Void(ctx context.Context, reference string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[reference]
+	if !ok || payment.status != StatusAuthorized {
+		return fmt.Errorf("%w: cannot void %s", ErrProviderRejected, reference)
+	}
+	payment.status = StatusVoided
+	return nil
+}
+
+func (p *fakeProvider) Refund(ctx context.Context, reference string, amount money.Amount) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[reference]
+	if !ok || payment.status != StatusCaptured || payment.refunded+amount > payment.amount {
+		return fmt.Errorf("%w: cannot refund %s of %s", ErrProviderRejected, amount, reference)
+	}
+	payment.refunded += amount
+	return nil
+}
+
+func (p *fakeProvider) ParseWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	if !validSignature(p.secret, payload, signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	return &event, nil
+}
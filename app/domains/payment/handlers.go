@@ -0,0 +1,224 @@
+package payment
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/p4xx07/order-service/app/domains/order"
+	http2 "github.com/p4xx07/order-service/internal/http"
+	"go.uber.org/zap"
+	"gopkg.in/validator.v2"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+// HeaderSignature carries the signature of webhook calls.
+const HeaderSignature = "X-Signature"
+
+type IHandler interface {
+	Post(ctx *fiber.Ctx) error
+	Get(ctx *fiber.Ctx) error
+	ListByOrder(ctx *fiber.Ctx) error
+	Capture(ctx *fiber.Ctx) error
+	Void(ctx *fiber.Ctx) error
+	Refund(ctx *fiber.Ctx) error
+	Webhook(ctx *fiber.Ctx) error
+}
+
+type handler struct {
+	service IService
+	logger  *zap.SugaredLogger
+}
+
+func NewHandler(service IService, logger *zap.SugaredLogger) IHandler {
+	return &handler{service: service, logger: logger}
+}
+
+func (h *handler) Post(c *fiber.Ctx) error {
+	var request PostRequest
+	if err := c.BodyParser(&request); err != nil {
+		h.logger.Errorf("bodyRequest error %v | %v", request, err.Error())
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	if errs := validator.Validate(request); errs != nil {
+		return c.Status(http.StatusBadRequest).JSON(errs)
+	}
+
+	response, err := h.service.Authorize(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, ErrPaymentDeclined) {
+			return http2.JSON(c, http.StatusPaymentRequired, response, err)
+		}
+		if errors.Is(err, ErrPaymentsDisabled) {
+			return http2.JSON(c, http.StatusServiceUnavailable, nil, err)
+		}
+		if errors.Is(err, ErrOrderNotPayable) || errors.Is(err, ErrAlreadyPaid) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) Get(c *fiber.Ctx) error {
+	paymentID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	response, err := h.service.Get(c.Context(), uint(paymentID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) ListByOrder(c *fiber.Ctx) error {
+	orderID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	response, err := h.service.ListByOrder(c.Context(), uint(orderID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) Capture(c *fiber.Ctx) error {
+	paymentID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	response, err := h.service.Capture(c.Context(), uint(paymentID))
+	if err != nil {
+		if errors.Is(err, ErrPaymentsDisabled) {
+			return http2.JSON(c, http.StatusServiceUnavailable, nil, err)
+		}
+		if errors.Is(err, ErrProviderRejected) {
+			return http2.JSON(c, http.StatusBadGateway, nil, err)
+		}
+		if errors.Is(err, ErrInvalidTransition) || errors.Is(err, order.ErrInvalidTransition) || errors.Is(err, ErrOrderNotPayable) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) Void(c *fiber.Ctx) error {
+	paymentID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	response, err := h.service.Void(c.Context(), uint(paymentID))
+	if err != nil {
+		if errors.Is(err, ErrPaymentsDisabled) {
+			return http2.JSON(c, http.StatusServiceUnavailable, nil, err)
+		}
+		if errors.Is(err, ErrProviderRejected) {
+			return http2.JSON(c, http.StatusBadGateway, nil, err)
+		}
+		if errors.Is(err, ErrInvalidTransition) || errors.Is(err, order.ErrInvalidTransition) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) Refund(c *fiber.Ctx) error {
+	paymentID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	var request RefundRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			h.logger.Errorf("bodyRequest error: %v", err.Error())
+			return c.Status(http.StatusBadRequest).JSON("Invalid request body")
+		}
+	}
+	request.ID = uint(paymentID)
+
+	response, err := h.service.Refund(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, ErrInvalidAmount) {
+			return http2.JSON(c, http.StatusUnprocessableEntity, nil, err)
+		}
+		if errors.Is(err, ErrPaymentsDisabled) {
+			return http2.JSON(c, http.StatusServiceUnavailable, nil, err)
+		}
+		if errors.Is(err, ErrProviderRejected) {
+			return http2.JSON(c, http.StatusBadGateway, nil, err)
+		}
+		if errors.Is(err, ErrInvalidTransition) || errors.Is(err, order.ErrInvalidTransition) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+// Webhook receives the payment results the provider calls back with; anything but a 2xx makes the provider retry.
+func (h *handler) Webhook(c *fiber.Ctx) error {
+	err := h.service.HandleWebhook(c.Context(), c.Body(), c.Get(HeaderSignature))
+	if err != nil {
+		if errors.Is(err, ErrInvalidSignature) {
+			return http2.JSON(c, http.StatusUnauthorized, nil, err)
+		}
+		if errors.Is(err, ErrInvalidEvent) {
+			return http2.JSON(c, http.StatusBadRequest, nil, err)
+		}
+		if errors.Is(err, ErrInvalidTransition) || errors.Is(err, order.ErrInvalidTransition) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
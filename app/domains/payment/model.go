@@ -0,0 +1,77 @@
+package payment
+
+import (
+	"github.com/p4xx07/order-service/internal/money"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusAuthorized        Status = "authorized"
+	StatusCaptured          Status = "captured"
+	StatusVoided            Status = "voided"
+	StatusPartiallyRefunded Status = "partially_refunded"
+	StatusRefunded          Status = "refunded"
+	StatusFailed            Status = "failed"
+)
+
+var transitions = map[Status][]Status{
+	StatusAuthorized:        {StatusCaptured, StatusVoided, StatusFailed},
+	StatusCaptured:          {StatusPartiallyRefunded, StatusRefunded},
+	StatusPartiallyRefunded: {StatusPartiallyRefunded, StatusRefunded},
+	StatusVoided:            {},
+	StatusRefunded:          {},
+	StatusFailed:            {},
+}
+
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive reports whether the payment holds or took the money of the order.
+func (s Status) IsActive() bool {
+	return s == StatusAuthorized || s == StatusCaptured || s == StatusPartiallyRefunded
+}
+
+// Payment is an attempt to pay for an order with a payment provider.
+type Payment struct {
+	ID      uint `gorm:"primaryKey;autoIncrement"`
+	OrderID uint `gorm:"index"`
+	// Provider and Reference identify the payment at the provider that processed it.
+	Provider  string `gorm:"type:varchar(30);not null;uniqueIndex:idx_payments_provider_reference"`
+	Reference string `gorm:"type:varchar(100);not null;uniqueIndex:idx_payments_provider_reference"`
+	Status    Status `gorm:"type:varchar(20);not null"`
+	// ActiveOrderID is the order while the payment is active and nil otherwise,
+	// its unique index keeps an order from having two active payments.
+	ActiveOrderID *uint          `gorm:"uniqueIndex"`
+	Amount        money.Amount   `gorm:"not null"`
+	Currency      money.Currency `gorm:"type:varchar(3);not null"`
+	Refunded      money.Amount   `gorm:"not null;default:0"`
+	// FailureReason is why the provider declined the payment.
+	FailureReason string    `gorm:"type:varchar(255)"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+// activeOrderID is what ActiveOrderID holds for the status of the payment.
+func (p *Payment) activeOrderID() *uint {
+	if !p.Status.IsActive() {
+		return nil
+	}
+	orderID := p.OrderID
+	return &orderID
+}
+
+// refundStatus is the status of the payment once amount more is refunded.
+func (p *Payment) refundStatus(amount money.Amount) Status {
+	if p.Refunded+amount >= p.Amount {
+		return StatusRefunded
+	}
+	return StatusPartiallyRefunded
+}
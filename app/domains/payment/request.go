@@ -0,0 +1,23 @@
+package payment
+
+import "github.com/p4xx07/order-service/internal/money"
+
+type PostRequest struct {
+	OrderID uint `json:"order_id,omitempty" validate:"min=1,nonnil" required:"true"`
+	// Token is the payment method the client collected from the provider, e.g. a card token.
+	Token string `json:"token,omitempty" validate:"nonzero,max=255" required:"true"`
+}
+
+// AuthorizeRequest is what a provider is asked to hold for an order.
+type AuthorizeRequest struct {
+	OrderID  uint
+	Amount   money.Amount
+	Currency money.Currency
+	Token    string
+}
+
+type RefundRequest struct {
+	ID uint `json:"id,omitempty" validate:"min=1,nonnil" required:"true"`
+	// Amount defaults to everything not refunded yet.
+	Amount *money.Amount `json:"amount,omitempty"`
+}
@@ -0,0 +1,38 @@
+package payment
+
+import (
+	"github.com/p4xx07/order-service/internal/money"
+	"time"
+)
+
+type PaymentResponse struct {
+	ID            uint           `json:"id"`
+	OrderID       uint           `json:"order_id"`
+	Provider      string         `json:"provider"`
+	Reference     string         `json:"reference"`
+	Status        Status         `json:"status"`
+	Amount        money.Amount   `json:"amount"`
+	Currency      money.Currency `json:"currency"`
+	Refunded      money.Amount   `json:"refunded"`
+	FailureReason string         `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+func (p *Payment) ToResponse() PaymentResponse {
+	return PaymentResponse{
+		ID:            p.ID,
+		OrderID:       p.OrderID,
+		Provider:      p.Provider,
+		Reference:     p.Reference,
+		Status:        p.Status,
+		Amount:        p.Amount,
+		Currency:      p.Currency,
+		Refunded:      p.Refunded,
+		FailureReason: p.FailureReason,
+		CreatedAt:     p.CreatedAt,
+	}
+}
+
+type ListPaymentsResponse struct {
+	Items []PaymentResponse `json:"items"`
+}
@@ -0,0 +1,17 @@
+package payment
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetRoutes(router fiber.Router, handler IHandler) {
+	g := router.Group("payment")
+	g.Post("/", handler.Post)
+	g.Post("/webhook", handler.Webhook)
+	g.Get("/:id", handler.Get)
+	g.Post("/:id/capture", handler.Capture)
+	g.Post("/:id/void", handler.Void)
+	g.Post("/:id/refund", handler.Refund)
+
+	router.Get("/order/:id/payments", handler.ListByOrder)
+}
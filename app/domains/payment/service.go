@@ -0,0 +1,318 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/db"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// IService pays for confirmed orders through the payment provider. Capturing a payment moves the order to paid,
+// voiding it cancels the order and refunding it in full refunds the order.
+type IService interface {
+	Authorize(ctx context.Context, request PostRequest) (*PaymentResponse, error)
+	Get(ctx context.Context, id uint) (*PaymentResponse, error)
+	ListByOrder(ctx context.Context, orderID uint) (*ListPaymentsResponse, error)
+	Capture(ctx context.Context, id uint) (*PaymentResponse, error)
+	Void(ctx context.Context, id uint) (*PaymentResponse, error)
+	Refund(ctx context.Context, request RefundRequest) (*PaymentResponse, error)
+	HandleWebhook(ctx context.Context, payload []byte, signature string) error
+}
+
+type service struct {
+	configuration *configuration.Configuration
+	logger        *zap.SugaredLogger
+	store         IStore
+	provider      PaymentProvider
+	orderService  order.IService
+	transactor    db.ITransactor
+}
+
+func NewService(store IStore, provider PaymentProvider, orderService order.IService, transactor db.ITransactor, configuration *configuration.Configuration, logger *zap.SugaredLogger) IService {
+	return &service{store: store, provider: provider, orderService: orderService, transactor: transactor, configuration: configuration, logger: logger}
+}
+
+// Authorize holds the order total with the provider. Declined payments are kept as failed so the attempts show up on the order.
+// The unique index on the active payments decides between concurrent authorizations; the one that loses is voided again.
+func (s *service) Authorize(ctx context.Context, request PostRequest) (*PaymentResponse, error) {
+	orderResponse, err := s.orderService.Get(ctx, request.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if orderResponse.Status != order.StatusConfirmed {
+		return nil, fmt.Errorf("%w: order is %s", ErrOrderNotPayable, orderResponse.Status)
+	}
+
+	payments, err := s.store.ListByOrder(ctx, request.OrderID)
+	if err != nil {
+		s.logger.Errorw("error listing payments", "error", err, "orderID", request.OrderID)
+		return nil, err
+	}
+	for _, existing := range payments {
+		if existing.Status.IsActive() {
+			return nil, ErrAlreadyPaid
+		}
+	}
+
+	authorization, err := s.provider.Authorize(ctx, AuthorizeRequest{
+		OrderID:  request.OrderID,
+		Amount:   orderResponse.Total,
+		Currency: orderResponse.Currency,
+		Token:    request.Token,
+	})
+	if err != nil {
+		s.logger.Errorw("error authorizing payment", "error", err, "orderID", request.OrderID, "provider", s.provider.Name())
+		return nil, err
+	}
+
+	payment := &Payment{
+		OrderID:   request.OrderID,
+		Provider:  s.provider.Name(),
+		Reference: authorization.Reference,
+		Status:    StatusAuthorized,
+		Amount:    orderResponse.Total,
+		Currency:  orderResponse.Currency,
+	}
+	if authorization.Declined {
+		payment.Status = StatusFailed
+		payment.FailureReason = authorization.Reason
+	}
+
+	if err := s.store.Create(ctx, payment); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			if err := s.provider.Void(ctx, authorization.Reference); err != nil {
+				s.logger.Errorw("error voiding duplicate authorization", "error", err, "orderID", request.OrderID, "reference", authorization.Reference)
+			}
+			return nil, ErrAlreadyPaid
+		}
+		s.logger.Errorw("failed to store payment", "error", err, "orderID", request.OrderID, "reference", authorization.Reference)
+		return nil, err
+	}
+
+	response := payment.ToResponse()
+	if authorization.Declined {
+		return &response, fmt.Errorf("%w: %s", ErrPaymentDeclined, authorization.Reason)
+	}
+	return &response, nil
+}
+
+func (s *service) Get(ctx context.Context, id uint) (*PaymentResponse, error) {
+	payment, err := s.store.Get(ctx, id)
+	if err != nil {
+		s.logger.Errorw("error getting payment", "error", err, "id", id)
+		return nil, err
+	}
+
+	response := payment.ToResponse()
+	return &response, nil
+}
+
+func (s *service) ListByOrder(ctx context.Context, orderID uint) (*ListPaymentsResponse, error) {
+	if _, err := s.orderService.Get(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	payments, err := s.store.ListByOrder(ctx, orderID)
+	if err != nil {
+		s.logger.Errorw("error listing payments", "error", err, "orderID", orderID)
+		return nil, err
+	}
+
+	items := make([]PaymentResponse, len(payments))
+	for i := range payments {
+		items[i] = payments[i].ToResponse()
+	}
+	return &ListPaymentsResponse{Items: items}, nil
+}
+
+// Capture takes the authorized money, only while the order is confirmed and waits for it.
+func (s *service) Capture(ctx context.Context, id uint) (*PaymentResponse, error) {
+	payment, err := s.transitionable(ctx, id, StatusCaptured)
+	if err != nil {
+		return nil, err
+	}
+
+	orderResponse, err := s.orderService.Get(ctx, payment.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if orderResponse.Status != order.StatusConfirmed {
+		return nil, fmt.Errorf("%w: order is %s", ErrOrderNotPayable, orderResponse.Status)
+	}
+
+	if err := s.provider.Capture(ctx, payment.Reference, payment.Amount); err != nil {
+		s.logger.Errorw("error capturing payment", "error", err, "id", id)
+		return nil, err
+	}
+
+	previous := *payment
+	payment.Status = StatusCaptured
+	if err := s.settle(ctx, payment, previous); err != nil {
+		return nil, err
+	}
+
+	response := payment.ToResponse()
+	return &response, nil
+}
+
+func (s *service) Void(ctx context.Context, id uint) (*PaymentResponse, error) {
+	payment, err := s.transitionable(ctx, id, StatusVoided)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.provider.Void(ctx, payment.Reference); err != nil {
+		s.logger.Errorw("error voiding payment", "error", err, "id", id)
+		return nil, err
+	}
+
+	previous := *payment
+	payment.Status = StatusVoided
+	if err := s.settle(ctx, payment, previous); err != nil {
+		return nil, err
+	}
+
+	response := payment.ToResponse()
+	return &response, nil
+}
+
+func (s *service) Refund(ctx context.Context, request RefundRequest) (*PaymentResponse, error) {
+	payment, err := s.store.Get(ctx, request.ID)
+	if err != nil {
+		s.logger.Errorw("error getting payment", "error", err, "id", request.ID)
+		return nil, err
+	}
+
+	remaining := payment.Amount - payment.Refunded
+	amount := remaining
+	if request.Amount != nil {
+		amount = *request.Amount
+	}
+
+	next := payment.refundStatus(amount)
+	if !payment.Status.CanTransitionTo(next) {
+		return nil, fmt.Errorf("%w: from %s to %s", ErrInvalidTransition, payment.Status, next)
+	}
+	if amount <= 0 || amount > remaining {
+		return nil, fmt.Errorf("%w: up to %s %s can be refunded", ErrInvalidAmount, remaining, payment.Currency)
+	}
+
+	if err := s.provider.Refund(ctx, payment.Reference, amount); err != nil {
+		s.logger.Errorw("error refunding payment", "error", err, "id", payment.ID)
+		return nil, err
+	}
+
+	previous := *payment
+	payment.Status = next
+	payment.Refunded += amount
+	if err := s.settle(ctx, payment, previous); err != nil {
+		return nil, err
+	}
+
+	response := payment.ToResponse()
+	return &response, nil
+}
+
+// HandleWebhook applies a payment result the provider reports on its own. Events that were applied already,
+// e.g. the capture of a payment captured through the API, change nothing.
+func (s *service) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	event, err := s.provider.ParseWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	payment, err := s.store.GetByReference(ctx, s.provider.Name(), event.Reference)
+	if err != nil {
+		s.logger.Errorw("error getting payment", "error", err, "provider", s.provider.Name(), "reference", event.Reference)
+		return err
+	}
+
+	previous := *payment
+	switch event.Type {
+	case EventCaptured:
+		payment.Status = StatusCaptured
+	case EventVoided:
+		payment.Status = StatusVoided
+	case EventFailed:
+		payment.Status = StatusFailed
+		payment.FailureReason = event.Reason
+	case EventRefunded:
+		if event.Amount <= payment.Refunded {
+			return nil
+		}
+		payment.Status = payment.refundStatus(event.Amount - payment.Refunded)
+		payment.Refunded = min(event.Amount, payment.Amount)
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidEvent, event.Type)
+	}
+
+	if payment.Status == previous.Status && payment.Refunded == previous.Refunded {
+		return nil
+	}
+	if !previous.Status.CanTransitionTo(payment.Status) {
+		return fmt.Errorf("%w: from %s to %s", ErrInvalidTransition, previous.Status, payment.Status)
+	}
+
+	return s.settle(ctx, payment, previous)
+}
+
+// transitionable gets a payment that can move to next.
+func (s *service) transitionable(ctx context.Context, id uint, next Status) (*Payment, error) {
+	payment, err := s.store.Get(ctx, id)
+	if err != nil {
+		s.logger.Errorw("error getting payment", "error", err, "id", id)
+		return nil, err
+	}
+
+	if !payment.Status.CanTransitionTo(next) {
+		return nil, fmt.Errorf("%w: from %s to %s", ErrInvalidTransition, payment.Status, next)
+	}
+	return payment, nil
+}
+
+// settle saves the payment and moves its order along in the same transaction.
+func (s *service) settle(ctx context.Context, payment *Payment, previous Payment) error {
+	orderResponse, err := s.orderService.Get(ctx, payment.OrderID)
+	if err != nil {
+		return err
+	}
+
+	next, move := orderStatus(payment.Status, orderResponse.Status)
+	if payment.Status == StatusCaptured && !move {
+		// the provider captured on its own or the order changed after the check, the payment is kept so it can be refunded
+		s.logger.Errorw("payment captured for an order that is not confirmed", "id", payment.ID, "orderID", payment.OrderID, "orderStatus", orderResponse.Status)
+	}
+
+	return s.transactor.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		if err := s.store.WithTx(tx).Update(ctx, payment, previous); err != nil {
+			s.logger.Errorw("error updating payment", "error", err, "id", payment.ID)
+			return err
+		}
+
+		if !move {
+			return nil
+		}
+
+		transition := order.TransitionRequest{ID: payment.OrderID, Status: next}
+		_, err := s.orderService.WithTx(tx).Transition(ctx, transition)
+		return err
+	})
+}
+
+// orderStatus is the status an order in status current moves to when its payment reaches status.
+func orderStatus(status Status, current order.Status) (order.Status, bool) {
+	switch status {
+	case StatusCaptured:
+		return order.StatusPaid, current == order.StatusConfirmed
+	case StatusVoided:
+		return order.StatusCancelled, current == order.StatusConfirmed
+	case StatusRefunded:
+		return order.StatusRefunded, current.CanTransitionTo(order.StatusRefunded)
+	}
+	return "", false
+}
@@ -0,0 +1,77 @@
+package payment
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+type IStore interface {
+	Create(ctx context.Context, payment *Payment) error
+	Get(ctx context.Context, id uint) (*Payment, error)
+	GetByReference(ctx context.Context, provider string, reference string) (*Payment, error)
+	ListByOrder(ctx context.Context, orderID uint) ([]Payment, error)
+	Update(ctx context.Context, payment *Payment, previous Payment) error
+	WithTx(tx *gorm.DB) IStore
+}
+
+type store struct {
+	db *gorm.DB
+}
+
+func NewStore(db *gorm.DB) IStore {
+	return &store{db: db}
+}
+
+func (s *store) WithTx(tx *gorm.DB) IStore {
+	return &store{db: tx}
+}
+
+func (s *store) Create(ctx context.Context, payment *Payment) error {
+	payment.ActiveOrderID = payment.activeOrderID()
+	return s.db.WithContext(ctx).Create(payment).Error
+}
+
+func (s *store) Get(ctx context.Context, id uint) (*Payment, error) {
+	var payment Payment
+	err := s.db.WithContext(ctx).Where("id = ?", id).First(&payment).Error
+	return &payment, err
+}
+
+func (s *store) GetByReference(ctx context.Context, provider string, reference string) (*Payment, error) {
+	var payment Payment
+	err := s.db.
+		WithContext(ctx).
+		Where("provider = ? AND reference = ?", provider, reference).
+		First(&payment).Error
+
+	return &payment, err
+}
+
+func (s *store) ListByOrder(ctx context.Context, orderID uint) ([]Payment, error) {
+	var payments []Payment
+	err := s.db.
+		WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("id").
+		Find(&payments).Error
+
+	return payments, err
+}
+
+// Update saves the status and amounts of a payment, as long as nobody changed it since it was read as previous.
+func (s *store) Update(ctx context.Context, payment *Payment, previous Payment) error {
+	payment.ActiveOrderID = payment.activeOrderID()
+	result := s.db.
+		WithContext(ctx).
+		Model(&Payment{}).
+		Where("id = ? AND status = ? AND refunded = ?", payment.ID, previous.Status, previous.Refunded).
+		Select("status", "active_order_id", "amount", "refunded", "failure_reason").
+		Updates(payment)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTransition
+	}
+	return nil
+}
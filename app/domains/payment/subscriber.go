@@ -0,0 +1,72 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/internal/event"
+	"go.uber.org/zap"
+)
+
+// SubscriberPayments is the payment subscriber as subscriber of the order events.
+const SubscriberPayments event.Subscriber = "payments"
+
+// ISubscriber voids the authorized payments of orders that are cancelled, expire or are deleted,
+// so that no money stays held for an order that will never ship.
+// It subscribes synchronously, so a void that fails is retried through the outbox.
+type ISubscriber interface {
+	Subscribe(bus event.IBus)
+}
+
+type subscriber struct {
+	logger   *zap.SugaredLogger
+	store    IStore
+	provider PaymentProvider
+}
+
+func NewSubscriber(store IStore, provider PaymentProvider, logger *zap.SugaredLogger) ISubscriber {
+	return &subscriber{store: store, provider: provider, logger: logger}
+}
+
+func (s *subscriber) Subscribe(bus event.IBus) {
+	bus.Subscribe(SubscriberPayments, order.EventOrderStatusChanged, func(ctx context.Context, e event.Event) error {
+		changed := e.(order.OrderStatusChanged)
+		if changed.To != order.StatusCancelled && changed.To != order.StatusExpired {
+			return nil
+		}
+		return s.voidAuthorized(ctx, changed.Order.ID)
+	})
+	bus.Subscribe(SubscriberPayments, order.EventOrderDeleted, func(ctx context.Context, e event.Event) error {
+		return s.voidAuthorized(ctx, e.(order.OrderDeleted).OrderID)
+	})
+}
+
+func (s *subscriber) voidAuthorized(ctx context.Context, orderID uint) error {
+	payments, err := s.store.ListByOrder(ctx, orderID)
+	if err != nil {
+		s.logger.Errorw("error listing payments", "error", err, "orderID", orderID)
+		return err
+	}
+
+	var errs []error
+	for i := range payments {
+		payment := &payments[i]
+		if payment.Status != StatusAuthorized {
+			continue
+		}
+
+		if err := s.provider.Void(ctx, payment.Reference); err != nil {
+			s.logger.Errorw("error voiding payment", "error", err, "id", payment.ID, "orderID", orderID)
+			errs = append(errs, err)
+			continue
+		}
+
+		previous := *payment
+		payment.Status = StatusVoided
+		if err := s.store.Update(ctx, payment, previous); err != nil {
+			s.logger.Errorw("error updating payment", "error", err, "id", payment.ID)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -1,12 +1,18 @@
 package product
 
-import "time"
+import (
+	"github.com/p4xx07/order-service/internal/money"
+	"gorm.io/gorm"
+	"time"
+)
 
 type Product struct {
-	ID          uint      `gorm:"primaryKey;autoIncrement"`
-	Name        string    `gorm:"type:varchar(100);not null"`
-	Description string    `gorm:"type:text"`
-	Price       float64   `gorm:"type:decimal(10,2);not null"`
-	CreatedAt   time.Time `gorm:"autoCreateTime"`
-	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+	ID          uint           `gorm:"primaryKey;autoIncrement"`
+	Name        string         `gorm:"type:varchar(100);not null"`
+	Description string         `gorm:"type:text"`
+	Price       money.Amount   `gorm:"not null"`
+	Category    string         `gorm:"type:varchar(50);index"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
 }
@@ -0,0 +1,34 @@
+package product
+
+import "github.com/p4xx07/order-service/internal/money"
+
+type ListRequest struct {
+	Input    string `json:"input,omitempty"`
+	Category string `json:"category,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+	Offset   int    `json:"offset,omitempty"`
+}
+
+type PostRequest struct {
+	Name        string       `json:"name,omitempty" validate:"nonzero,max=100" required:"true"`
+	Description string       `json:"description,omitempty"`
+	Price       money.Amount `json:"price,omitempty" validate:"min=0" required:"true"`
+	Category    string       `json:"category,omitempty" validate:"max=50"`
+}
+
+type PutRequest struct {
+	ID          uint         `json:"id,omitempty" validate:"min=1,nonnil" required:"true"`
+	Name        string       `json:"name,omitempty" validate:"nonzero,max=100" required:"true"`
+	Description string       `json:"description,omitempty"`
+	Price       money.Amount `json:"price,omitempty" validate:"min=0" required:"true"`
+	Category    string       `json:"category,omitempty" validate:"max=50"`
+}
+
+func (r PostRequest) ToStore() *Product {
+	return &Product{
+		Name:        r.Name,
+		Description: r.Description,
+		Price:       r.Price,
+		Category:    r.Category,
+	}
+}
@@ -1,13 +1,21 @@
 package product
 
-import "time"
+import (
+	"github.com/p4xx07/order-service/internal/money"
+	"time"
+)
+
+type CreateProductResponse struct {
+	ID uint `json:"id"`
+}
 
 type ProductResponse struct {
-	ID          uint      `json:"id,omitempty"`
-	Name        string    `json:"name,omitempty"`
-	Description string    `json:"description,omitempty"`
-	Price       float64   `json:"price,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          uint         `json:"id,omitempty"`
+	Name        string       `json:"name,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Price       money.Amount `json:"price,omitempty"`
+	Category    string       `json:"category,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
 }
 
 func (p *Product) ToResponse() ProductResponse {
@@ -16,6 +24,14 @@ func (p *Product) ToResponse() ProductResponse {
 		Name:        p.Name,
 		Description: p.Description,
 		Price:       p.Price,
+		Category:    p.Category,
 		CreatedAt:   p.CreatedAt,
 	}
 }
+
+type ListProductsResponse struct {
+	Items  []ProductResponse `json:"items"`
+	Total  int64             `json:"total"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+}
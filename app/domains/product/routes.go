@@ -0,0 +1,14 @@
+package product
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetRoutes(router fiber.Router, handler IHandler) {
+	g := router.Group("product")
+	g.Get("/", handler.List)
+	g.Post("/", handler.Post)
+	g.Get("/:id", handler.Get)
+	g.Put("/:id", handler.Put)
+	g.Delete("/:id", handler.Delete)
+}
@@ -0,0 +1,110 @@
+package product
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/configuration"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+type IService interface {
+	List(ctx context.Context, request ListRequest) (*ListProductsResponse, error)
+	Get(ctx context.Context, id uint) (*ProductResponse, error)
+	Create(ctx context.Context, request PostRequest) (*CreateProductResponse, error)
+	Update(ctx context.Context, request PutRequest) (*ProductResponse, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+type service struct {
+	configuration *configuration.Configuration
+	logger        *zap.SugaredLogger
+	store         IStore
+}
+
+func NewService(store IStore, configuration *configuration.Configuration, logger *zap.SugaredLogger) IService {
+	return &service{store: store, configuration: configuration, logger: logger}
+}
+
+func (s *service) List(ctx context.Context, request ListRequest) (*ListProductsResponse, error) {
+	if request.Limit <= 0 {
+		request.Limit = defaultListLimit
+	}
+	if request.Limit > maxListLimit {
+		request.Limit = maxListLimit
+	}
+	if request.Offset < 0 {
+		request.Offset = 0
+	}
+
+	products, total, err := s.store.List(ctx, request)
+	if err != nil {
+		s.logger.Errorw("error listing products", "error", err)
+		return nil, err
+	}
+
+	items := make([]ProductResponse, len(products))
+	for i := range products {
+		items[i] = products[i].ToResponse()
+	}
+
+	return &ListProductsResponse{
+		Items:  items,
+		Total:  total,
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}, nil
+}
+
+func (s *service) Get(ctx context.Context, id uint) (*ProductResponse, error) {
+	product, err := s.store.Get(ctx, id)
+	if err != nil {
+		s.logger.Errorw("error getting product", "error", err, "id", id)
+		return nil, err
+	}
+
+	response := product.ToResponse()
+	return &response, nil
+}
+
+func (s *service) Create(ctx context.Context, request PostRequest) (*CreateProductResponse, error) {
+	product := request.ToStore()
+	if err := s.store.Create(ctx, product); err != nil {
+		s.logger.Errorw("failed to store product", "error", err)
+		return nil, err
+	}
+
+	return &CreateProductResponse{ID: product.ID}, nil
+}
+
+func (s *service) Update(ctx context.Context, request PutRequest) (*ProductResponse, error) {
+	product, err := s.store.Get(ctx, request.ID)
+	if err != nil {
+		s.logger.Errorw("error getting product", "error", err, "id", request.ID)
+		return nil, err
+	}
+
+	product.Name = request.Name
+	product.Description = request.Description
+	product.Price = request.Price
+	product.Category = request.Category
+
+	if err := s.store.Update(ctx, product); err != nil {
+		s.logger.Errorw("error updating product", "error", err, "id", request.ID)
+		return nil, err
+	}
+
+	response := product.ToResponse()
+	return &response, nil
+}
+
+func (s *service) Delete(ctx context.Context, id uint) error {
+	if err := s.store.Delete(ctx, id); err != nil {
+		s.logger.Errorw("error deleting product", "error", err, "id", id)
+		return err
+	}
+	return nil
+}
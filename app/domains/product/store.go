@@ -0,0 +1,79 @@
+package product
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+type IStore interface {
+	Create(ctx context.Context, product *Product) error
+	Get(ctx context.Context, id uint) (*Product, error)
+	List(ctx context.Context, request ListRequest) ([]Product, int64, error)
+	Update(ctx context.Context, product *Product) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type store struct {
+	db *gorm.DB
+}
+
+func NewStore(db *gorm.DB) IStore {
+	return &store{db: db}
+}
+
+func (s *store) Create(ctx context.Context, product *Product) error {
+	return s.db.WithContext(ctx).Create(product).Error
+}
+
+func (s *store) Get(ctx context.Context, id uint) (*Product, error) {
+	var product Product
+	err := s.db.
+		WithContext(ctx).
+		Where("id = ?", id).
+		First(&product).Error
+
+	return &product, err
+}
+
+func (s *store) List(ctx context.Context, request ListRequest) ([]Product, int64, error) {
+	query := s.db.WithContext(ctx).Model(&Product{})
+	if request.Input != "" {
+		like := "%" + request.Input + "%"
+		query = query.Where("name LIKE ? OR description LIKE ?", like, like)
+	}
+	if request.Category != "" {
+		query = query.Where("category = ?", request.Category)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var products []Product
+	err := query.
+		Order("id").
+		Limit(request.Limit).
+		Offset(request.Offset).
+		Find(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return products, total, nil
+}
+
+func (s *store) Update(ctx context.Context, product *Product) error {
+	return s.db.WithContext(ctx).Save(product).Error
+}
+
+func (s *store) Delete(ctx context.Context, id uint) error {
+	result := s.db.WithContext(ctx).Where("id = ?", id).Delete(&Product{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package promotion
+
+import "errors"
+
+var (
+	ErrInvalidPromotion       = errors.New("invalid promotion")
+	ErrCodeTaken              = errors.New("promotion code already in use")
+	ErrPromotionNotFound      = errors.New("promotion code not found")
+	ErrPromotionNotActive     = errors.New("promotion is not active")
+	ErrPromotionExhausted     = errors.New("promotion usage limit reached")
+	ErrPromotionNotApplicable = errors.New("promotion does not apply to the order")
+)
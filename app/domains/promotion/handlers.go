@@ -0,0 +1,104 @@
+package promotion
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	http2 "github.com/p4xx07/order-service/internal/http"
+	"go.uber.org/zap"
+	"gopkg.in/validator.v2"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+type IHandler interface {
+	List(ctx *fiber.Ctx) error
+	Post(ctx *fiber.Ctx) error
+	Get(ctx *fiber.Ctx) error
+	Delete(ctx *fiber.Ctx) error
+}
+
+type handler struct {
+	service IService
+	logger  *zap.SugaredLogger
+}
+
+func NewHandler(service IService, logger *zap.SugaredLogger) IHandler {
+	return &handler{service: service, logger: logger}
+}
+
+func (h *handler) Post(c *fiber.Ctx) error {
+	var request PostRequest
+	if err := c.BodyParser(&request); err != nil {
+		h.logger.Errorf("bodyRequest error %v | %v", request, err.Error())
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	if errs := validator.Validate(request); errs != nil {
+		return c.Status(http.StatusBadRequest).JSON(errs)
+	}
+
+	response, err := h.service.Create(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, ErrInvalidPromotion) {
+			return http2.JSON(c, http.StatusBadRequest, nil, err)
+		}
+		if errors.Is(err, ErrCodeTaken) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) List(c *fiber.Ctx) error {
+	request := ListRequest{
+		Limit:  c.QueryInt("limit"),
+		Offset: c.QueryInt("offset"),
+	}
+
+	response, err := h.service.List(c.Context(), request)
+	if err != nil {
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) Get(c *fiber.Ctx) error {
+	promotionID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	response, err := h.service.Get(c.Context(), uint(promotionID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) Delete(c *fiber.Ctx) error {
+	promotionID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	if err := h.service.Delete(c.Context(), uint(promotionID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
@@ -0,0 +1,129 @@
+package promotion
+
+import (
+	"github.com/p4xx07/order-service/internal/money"
+	"gorm.io/gorm"
+	"time"
+)
+
+type Type string
+
+const (
+	// TypePercentage takes Percent percent off the order or the product.
+	TypePercentage Type = "percentage"
+	// TypeFixed takes Amount off the order, or off every unit of the product.
+	TypeFixed Type = "fixed"
+	// TypeBuyXGetY gives GetQuantity units of the product for free for every BuyQuantity units paid for.
+	TypeBuyXGetY Type = "buy_x_get_y"
+)
+
+func (t Type) IsValid() bool {
+	return t == TypePercentage || t == TypeFixed || t == TypeBuyXGetY
+}
+
+type Scope string
+
+const (
+	ScopeOrder   Scope = "order"
+	ScopeProduct Scope = "product"
+)
+
+func (s Scope) IsValid() bool {
+	return s == ScopeOrder || s == ScopeProduct
+}
+
+type Promotion struct {
+	ID          uint         `gorm:"primaryKey;autoIncrement"`
+	Code        string       `gorm:"type:varchar(50);not null;uniqueIndex"`
+	Type        Type         `gorm:"type:varchar(20);not null"`
+	Scope       Scope        `gorm:"type:varchar(20);not null"`
+	ProductID   *uint        `gorm:"index"`
+	Percent     int          `gorm:"not null;default:0"`
+	Amount      money.Amount `gorm:"not null;default:0"`
+	BuyQuantity int          `gorm:"not null;default:0"`
+	GetQuantity int          `gorm:"not null;default:0"`
+	// UsageLimit is how many orders can redeem the promotion, 0 means no limit.
+	UsageLimit int `gorm:"not null;default:0"`
+	UsageCount int `gorm:"not null;default:0"`
+	// StartsAt and EndsAt bound when the code can be applied to an order, either can be left open.
+	StartsAt  *time.Time
+	EndsAt    *time.Time
+	CreatedAt time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// activeAt reports whether the promotion can be applied at the given time.
+func (p *Promotion) activeAt(now time.Time) bool {
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	return p.EndsAt == nil || now.Before(*p.EndsAt)
+}
+
+func (p *Promotion) exhausted() bool {
+	return p.UsageLimit > 0 && p.UsageCount >= p.UsageLimit
+}
+
+// discount is how much the promotion takes off the cart, before the cap on the cart subtotal.
+// Fixed amounts are in the base currency and converted at the cart exchange rate.
+func (p *Promotion) discount(cart Cart) money.Amount {
+	if p.Scope == ScopeOrder {
+		switch p.Type {
+		case TypePercentage:
+			return cart.subtotal().Percent(p.Percent)
+		case TypeFixed:
+			return p.Amount.Convert(cart.ExchangeRate)
+		}
+		return 0
+	}
+
+	var discount money.Amount
+	for _, line := range cart.Lines {
+		if p.ProductID == nil || line.ProductID != *p.ProductID {
+			continue
+		}
+
+		switch p.Type {
+		case TypePercentage:
+			discount += line.total().Percent(p.Percent)
+		case TypeFixed:
+			discount += min(p.Amount.Convert(cart.ExchangeRate), line.Price).Times(line.Quantity)
+		case TypeBuyXGetY:
+			free := line.Quantity / (p.BuyQuantity + p.GetQuantity) * p.GetQuantity
+			discount += line.Price.Times(free)
+		}
+	}
+	return discount
+}
+
+// Cart is what an order is made of when its promotions are worked out, priced in the order currency.
+type Cart struct {
+	ExchangeRate float64
+	Lines        []Line
+}
+
+type Line struct {
+	ProductID uint
+	Quantity  int
+	Price     money.Amount
+}
+
+func (l Line) total() money.Amount {
+	return l.Price.Times(l.Quantity)
+}
+
+func (c Cart) subtotal() money.Amount {
+	var subtotal money.Amount
+	for _, line := range c.Lines {
+		subtotal += line.total()
+	}
+	return subtotal
+}
+
+// Discount is a promotion applied to a cart and the amount it takes off.
+type Discount struct {
+	PromotionID uint
+	Code        string
+	Amount      money.Amount
+}
@@ -0,0 +1,94 @@
+package promotion
+
+import (
+	"fmt"
+	"github.com/p4xx07/order-service/internal/money"
+	"strings"
+	"time"
+)
+
+type ListRequest struct {
+	Limit  int `json:"limit,omitempty"`
+	Offset int `json:"offset,omitempty"`
+}
+
+type PostRequest struct {
+	Code        string       `json:"code,omitempty" validate:"nonzero,max=50" required:"true"`
+	Type        Type         `json:"type,omitempty" validate:"nonzero" required:"true"`
+	Scope       Scope        `json:"scope,omitempty" validate:"nonzero" required:"true"`
+	ProductID   *uint        `json:"product_id,omitempty"`
+	Percent     int          `json:"percent,omitempty"`
+	Amount      money.Amount `json:"amount,omitempty"`
+	BuyQuantity int          `json:"buy_quantity,omitempty"`
+	GetQuantity int          `json:"get_quantity,omitempty"`
+	UsageLimit  int          `json:"usage_limit,omitempty" validate:"min=0"`
+	StartsAt    *time.Time   `json:"starts_at,omitempty"`
+	EndsAt      *time.Time   `json:"ends_at,omitempty"`
+}
+
+func (r PostRequest) Validate() error {
+	if !r.Type.IsValid() {
+		return fmt.Errorf("%w: unknown type %s", ErrInvalidPromotion, r.Type)
+	}
+	if !r.Scope.IsValid() {
+		return fmt.Errorf("%w: unknown scope %s", ErrInvalidPromotion, r.Scope)
+	}
+	if r.Scope == ScopeProduct && r.ProductID == nil {
+		return fmt.Errorf("%w: product scoped promotions need a product_id", ErrInvalidPromotion)
+	}
+	if r.Scope == ScopeOrder && r.ProductID != nil {
+		return fmt.Errorf("%w: order scoped promotions cannot have a product_id", ErrInvalidPromotion)
+	}
+
+	switch r.Type {
+	case TypePercentage:
+		if r.Percent < 1 || r.Percent > 100 {
+			return fmt.Errorf("%w: percent must be between 1 and 100", ErrInvalidPromotion)
+		}
+	case TypeFixed:
+		if r.Amount <= 0 {
+			return fmt.Errorf("%w: amount must be positive", ErrInvalidPromotion)
+		}
+	case TypeBuyXGetY:
+		if r.Scope != ScopeProduct {
+			return fmt.Errorf("%w: buy_x_get_y promotions must be product scoped", ErrInvalidPromotion)
+		}
+		if r.BuyQuantity < 1 || r.GetQuantity < 1 {
+			return fmt.Errorf("%w: buy_quantity and get_quantity must be positive", ErrInvalidPromotion)
+		}
+	}
+
+	if r.StartsAt != nil && r.EndsAt != nil && !r.StartsAt.Before(*r.EndsAt) {
+		return fmt.Errorf("%w: starts_at must be before ends_at", ErrInvalidPromotion)
+	}
+	return nil
+}
+
+func (r PostRequest) ToStore() *Promotion {
+	return &Promotion{
+		Code:        NormalizeCode(r.Code),
+		Type:        r.Type,
+		Scope:       r.Scope,
+		ProductID:   r.ProductID,
+		Percent:     r.Percent,
+		Amount:      r.Amount,
+		BuyQuantity: r.BuyQuantity,
+		GetQuantity: r.GetQuantity,
+		UsageLimit:  r.UsageLimit,
+		StartsAt:    r.StartsAt,
+		EndsAt:      r.EndsAt,
+	}
+}
+
+// NormalizeCode makes coupon codes case insensitive.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ApplyRequest asks for the discounts of the given codes on a cart.
+// Redeemed are codes the order already redeemed: they keep applying even once the promotion ended or ran out.
+type ApplyRequest struct {
+	Codes    []string
+	Redeemed []string
+	Cart     Cart
+}
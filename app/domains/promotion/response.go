@@ -0,0 +1,53 @@
+package promotion
+
+import (
+	"github.com/p4xx07/order-service/internal/money"
+	"time"
+)
+
+type CreatePromotionResponse struct {
+	ID uint `json:"id"`
+}
+
+type PromotionResponse struct {
+	ID          uint         `json:"id"`
+	Code        string       `json:"code"`
+	Type        Type         `json:"type"`
+	Scope       Scope        `json:"scope"`
+	ProductID   *uint        `json:"product_id,omitempty"`
+	Percent     int          `json:"percent,omitempty"`
+	Amount      money.Amount `json:"amount,omitempty"`
+	BuyQuantity int          `json:"buy_quantity,omitempty"`
+	GetQuantity int          `json:"get_quantity,omitempty"`
+	UsageLimit  int          `json:"usage_limit"`
+	UsageCount  int          `json:"usage_count"`
+	StartsAt    *time.Time   `json:"starts_at,omitempty"`
+	EndsAt      *time.Time   `json:"ends_at,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+func (p *Promotion) ToResponse() PromotionResponse {
+	return PromotionResponse{
+		ID:          p.ID,
+		Code:        p.Code,
+		Type:        p.Type,
+		Scope:       p.Scope,
+		ProductID:   p.ProductID,
+		Percent:     p.Percent,
+		Amount:      p.Amount,
+		BuyQuantity: p.BuyQuantity,
+		GetQuantity: p.GetQuantity,
+		UsageLimit:  p.UsageLimit,
+		UsageCount:  p.UsageCount,
+		StartsAt:    p.StartsAt,
+		EndsAt:      p.EndsAt,
+		CreatedAt:   p.CreatedAt,
+	}
+}
+
+type ListPromotionsResponse struct {
+	Items  []PromotionResponse `json:"items"`
+	Total  int64               `json:"total"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+}
@@ -0,0 +1,13 @@
+package promotion
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetAdminRoutes(router fiber.Router, handler IHandler) {
+	g := router.Group("promotion")
+	g.Get("/", handler.List)
+	g.Post("/", handler.Post)
+	g.Get("/:id", handler.Get)
+	g.Delete("/:id", handler.Delete)
+}
@@ -0,0 +1,190 @@
+package promotion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/p4xx07/order-service/configuration"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+type IService interface {
+	List(ctx context.Context, request ListRequest) (*ListPromotionsResponse, error)
+	Get(ctx context.Context, id uint) (*PromotionResponse, error)
+	Create(ctx context.Context, request PostRequest) (*CreatePromotionResponse, error)
+	Delete(ctx context.Context, id uint) error
+	Apply(ctx context.Context, request ApplyRequest) ([]Discount, error)
+	Redeem(ctx context.Context, promotionIDs []uint) error
+	Release(ctx context.Context, promotionIDs []uint) error
+	WithTx(tx *gorm.DB) IService
+}
+
+type service struct {
+	configuration *configuration.Configuration
+	logger        *zap.SugaredLogger
+	store         IStore
+}
+
+func NewService(store IStore, configuration *configuration.Configuration, logger *zap.SugaredLogger) IService {
+	return &service{store: store, configuration: configuration, logger: logger}
+}
+
+// WithTx returns a copy of the service whose store runs on the given transaction.
+func (s *service) WithTx(tx *gorm.DB) IService {
+	return &service{store: s.store.WithTx(tx), configuration: s.configuration, logger: s.logger}
+}
+
+func (s *service) List(ctx context.Context, request ListRequest) (*ListPromotionsResponse, error) {
+	if request.Limit <= 0 {
+		request.Limit = defaultListLimit
+	}
+	if request.Limit > maxListLimit {
+		request.Limit = maxListLimit
+	}
+	if request.Offset < 0 {
+		request.Offset = 0
+	}
+
+	promotions, total, err := s.store.List(ctx, request)
+	if err != nil {
+		s.logger.Errorw("error listing promotions", "error", err)
+		return nil, err
+	}
+
+	items := make([]PromotionResponse, len(promotions))
+	for i := range promotions {
+		items[i] = promotions[i].ToResponse()
+	}
+
+	return &ListPromotionsResponse{
+		Items:  items,
+		Total:  total,
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}, nil
+}
+
+func (s *service) Get(ctx context.Context, id uint) (*PromotionResponse, error) {
+	promotion, err := s.store.Get(ctx, id)
+	if err != nil {
+		s.logger.Errorw("error getting promotion", "error", err, "id", id)
+		return nil, err
+	}
+
+	response := promotion.ToResponse()
+	return &response, nil
+}
+
+func (s *service) Create(ctx context.Context, request PostRequest) (*CreatePromotionResponse, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	promotion := request.ToStore()
+	if err := s.store.Create(ctx, promotion); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrCodeTaken
+		}
+		s.logger.Errorw("failed to store promotion", "error", err)
+		return nil, err
+	}
+
+	return &CreatePromotionResponse{ID: promotion.ID}, nil
+}
+
+// Delete retires a promotion: it can no longer be applied, but orders that redeemed it keep their discount.
+func (s *service) Delete(ctx context.Context, id uint) error {
+	if err := s.store.Delete(ctx, id); err != nil {
+		s.logger.Errorw("error deleting promotion", "error", err, "id", id)
+		return err
+	}
+	return nil
+}
+
+// Apply works out the discount of every code on the cart, in the order the codes are given.
+// Together the discounts never take more than the cart subtotal.
+func (s *service) Apply(ctx context.Context, request ApplyRequest) ([]Discount, error) {
+	var codes []string
+	seen := map[string]bool{}
+	for _, code := range request.Codes {
+		code = NormalizeCode(code)
+		if code != "" && !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		return nil, nil
+	}
+
+	redeemed := map[string]bool{}
+	for _, code := range request.Redeemed {
+		redeemed[NormalizeCode(code)] = true
+	}
+
+	promotions, err := s.store.GetByCodes(ctx, codes)
+	if err != nil {
+		s.logger.Errorw("error getting promotions", "error", err, "codes", codes)
+		return nil, err
+	}
+
+	now := time.Now()
+	remaining := request.Cart.subtotal()
+	discounts := make([]Discount, 0, len(codes))
+	for _, code := range codes {
+		promotion, ok := promotions[code]
+		if !ok || (promotion.DeletedAt.Valid && !redeemed[code]) {
+			return nil, fmt.Errorf("%w: %s", ErrPromotionNotFound, code)
+		}
+
+		if !redeemed[code] {
+			if !promotion.activeAt(now) {
+				return nil, fmt.Errorf("%w: %s", ErrPromotionNotActive, code)
+			}
+			if promotion.exhausted() {
+				return nil, fmt.Errorf("%w: %s", ErrPromotionExhausted, code)
+			}
+		}
+
+		amount := min(promotion.discount(request.Cart), remaining)
+		if amount <= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrPromotionNotApplicable, code)
+		}
+		remaining -= amount
+
+		discounts = append(discounts, Discount{PromotionID: promotion.ID, Code: promotion.Code, Amount: amount})
+	}
+
+	return discounts, nil
+}
+
+// Redeem counts the use of the promotions by an order; run it in the transaction storing the order.
+func (s *service) Redeem(ctx context.Context, promotionIDs []uint) error {
+	for _, promotionID := range promotionIDs {
+		if err := s.store.Redeem(ctx, promotionID); err != nil {
+			s.logger.Errorw("error redeeming promotion", "error", err, "id", promotionID)
+			return err
+		}
+	}
+	return nil
+}
+
+// Release gives back the uses of promotions an order no longer applies.
+func (s *service) Release(ctx context.Context, promotionIDs []uint) error {
+	if len(promotionIDs) == 0 {
+		return nil
+	}
+
+	if err := s.store.Release(ctx, promotionIDs); err != nil {
+		s.logger.Errorw("error releasing promotions", "error", err, "ids", promotionIDs)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package promotion
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+type IStore interface {
+	Create(ctx context.Context, promotion *Promotion) error
+	Get(ctx context.Context, id uint) (*Promotion, error)
+	GetByCodes(ctx context.Context, codes []string) (map[string]Promotion, error)
+	List(ctx context.Context, request ListRequest) ([]Promotion, int64, error)
+	Delete(ctx context.Context, id uint) error
+	Redeem(ctx context.Context, id uint) error
+	Release(ctx context.Context, ids []uint) error
+	WithTx(tx *gorm.DB) IStore
+}
+
+type store struct {
+	db *gorm.DB
+}
+
+func NewStore(db *gorm.DB) IStore {
+	return &store{db: db}
+}
+
+func (s *store) WithTx(tx *gorm.DB) IStore {
+	return &store{db: tx}
+}
+
+func (s *store) Create(ctx context.Context, promotion *Promotion) error {
+	return s.db.WithContext(ctx).Create(promotion).Error
+}
+
+func (s *store) Get(ctx context.Context, id uint) (*Promotion, error) {
+	var promotion Promotion
+	err := s.db.
+		WithContext(ctx).
+		Where("id = ?", id).
+		First(&promotion).Error
+
+	return &promotion, err
+}
+
+// GetByCodes includes deleted promotions, which orders that already redeemed them keep.
+func (s *store) GetByCodes(ctx context.Context, codes []string) (map[string]Promotion, error) {
+	var promotions []Promotion
+	err := s.db.
+		WithContext(ctx).
+		Unscoped().
+		Where("code IN (?)", codes).
+		Find(&promotions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byCode := make(map[string]Promotion, len(promotions))
+	for _, promotion := range promotions {
+		byCode[promotion.Code] = promotion
+	}
+	return byCode, nil
+}
+
+func (s *store) List(ctx context.Context, request ListRequest) ([]Promotion, int64, error) {
+	query := s.db.WithContext(ctx).Model(&Promotion{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var promotions []Promotion
+	err := query.
+		Order("id").
+		Limit(request.Limit).
+		Offset(request.Offset).
+		Find(&promotions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return promotions, total, nil
+}
+
+func (s *store) Delete(ctx context.Context, id uint) error {
+	result := s.db.WithContext(ctx).Where("id = ?", id).Delete(&Promotion{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Redeem counts one more use of the promotion, unless that goes over its usage limit.
+// The check is part of the update so that concurrent orders cannot redeem more than the limit.
+func (s *store) Redeem(ctx context.Context, id uint) error {
+	result := s.db.
+		WithContext(ctx).
+		Model(&Promotion{}).
+		Where("id = ? AND (usage_limit = 0 OR usage_count < usage_limit)", id).
+		Update("usage_count", gorm.Expr("usage_count + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPromotionExhausted
+	}
+	return nil
+}
+
+func (s *store) Release(ctx context.Context, ids []uint) error {
+	return s.db.
+		WithContext(ctx).
+		Unscoped().
+		Model(&Promotion{}).
+		Where("id IN (?) AND usage_count > 0", ids).
+		Update("usage_count", gorm.Expr("usage_count - 1")).Error
+}
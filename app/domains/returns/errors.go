@@ -0,0 +1,10 @@
+package returns
+
+import "errors"
+
+var (
+	ErrOrderNotReturnable = errors.New("order cannot be returned")
+	ErrInvalidItems       = errors.New("invalid return items")
+	ErrInvalidTransition  = errors.New("invalid return status transition")
+	ErrInvalidRefund      = errors.New("invalid refund amount")
+)
@@ -0,0 +1,215 @@
+package returns
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/p4xx07/order-service/app/domains/order"
+	http2 "github.com/p4xx07/order-service/internal/http"
+	"go.uber.org/zap"
+	"gopkg.in/validator.v2"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+type IHandler interface {
+	Post(ctx *fiber.Ctx) error
+	Get(ctx *fiber.Ctx) error
+	ListByOrder(ctx *fiber.Ctx) error
+	Approve(ctx *fiber.Ctx) error
+	Reject(ctx *fiber.Ctx) error
+	Receive(ctx *fiber.Ctx) error
+	Refund(ctx *fiber.Ctx) error
+}
+
+type handler struct {
+	service IService
+	logger  *zap.SugaredLogger
+}
+
+func NewHandler(service IService, logger *zap.SugaredLogger) IHandler {
+	return &handler{service: service, logger: logger}
+}
+
+func (h *handler) Post(c *fiber.Ctx) error {
+	var request PostRequest
+	if err := c.BodyParser(&request); err != nil {
+		h.logger.Errorf("bodyRequest error %v | %v", request, err.Error())
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	if errs := validator.Validate(request); errs != nil {
+		return c.Status(http.StatusBadRequest).JSON(errs)
+	}
+
+	response, err := h.service.Create(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, ErrInvalidItems) {
+			return http2.JSON(c, http.StatusUnprocessableEntity, nil, err)
+		}
+		if errors.Is(err, ErrOrderNotReturnable) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) Get(c *fiber.Ctx) error {
+	returnID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	response, err := h.service.Get(c.Context(), uint(returnID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) ListByOrder(c *fiber.Ctx) error {
+	orderID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	response, err := h.service.ListByOrder(c.Context(), uint(orderID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) Approve(c *fiber.Ctx) error {
+	returnID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	response, err := h.service.Approve(c.Context(), uint(returnID))
+	if err != nil {
+		if errors.Is(err, ErrInvalidTransition) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) Reject(c *fiber.Ctx) error {
+	returnID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	var request RejectRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			h.logger.Errorf("bodyRequest error: %v", err.Error())
+			return c.Status(http.StatusBadRequest).JSON("Invalid request body")
+		}
+	}
+	request.ID = uint(returnID)
+
+	if errs := validator.Validate(request); errs != nil {
+		return c.Status(http.StatusBadRequest).JSON(errs)
+	}
+
+	response, err := h.service.Reject(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, ErrInvalidTransition) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) Receive(c *fiber.Ctx) error {
+	returnID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	var request ReceiveRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			h.logger.Errorf("bodyRequest error: %v", err.Error())
+			return c.Status(http.StatusBadRequest).JSON("Invalid request body")
+		}
+	}
+	request.ID = uint(returnID)
+
+	response, err := h.service.Receive(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, ErrInvalidTransition) || errors.Is(err, order.ErrInvalidTransition) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) Refund(c *fiber.Ctx) error {
+	returnID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	var request RefundRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			h.logger.Errorf("bodyRequest error: %v", err.Error())
+			return c.Status(http.StatusBadRequest).JSON("Invalid request body")
+		}
+	}
+	request.ID = uint(returnID)
+
+	response, err := h.service.Refund(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRefund) {
+			return http2.JSON(c, http.StatusUnprocessableEntity, nil, err)
+		}
+		if errors.Is(err, ErrInvalidTransition) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
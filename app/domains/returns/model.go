@@ -0,0 +1,80 @@
+package returns
+
+import (
+	"github.com/p4xx07/order-service/internal/money"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusRequested Status = "requested"
+	StatusApproved  Status = "approved"
+	StatusRejected  Status = "rejected"
+	StatusReceived  Status = "received"
+	StatusRefunded  Status = "refunded"
+)
+
+var transitions = map[Status][]Status{
+	StatusRequested: {StatusApproved, StatusRejected},
+	StatusApproved:  {StatusReceived},
+	StatusReceived:  {StatusRefunded},
+	StatusRejected:  {},
+	StatusRefunded:  {},
+}
+
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// HasGoods reports whether the goods of the return came back.
+func (s Status) HasGoods() bool {
+	return s == StatusReceived || s == StatusRefunded
+}
+
+// Return is a request to send back some units of the items of an order, and what came of it.
+type Return struct {
+	ID      uint   `gorm:"primaryKey;autoIncrement"`
+	OrderID uint   `gorm:"index"`
+	Status  Status `gorm:"type:varchar(20);not null"`
+	Reason  string `gorm:"type:varchar(500)"`
+	// Note is why the return was rejected.
+	Note string `gorm:"type:varchar(500)"`
+	// Restock tells whether the received goods went back into the inventory.
+	Restock    bool       `gorm:"not null;default:false"`
+	ReceivedAt *time.Time `gorm:""`
+	// RefundAmount is what was paid back, in minor units of the order Currency.
+	RefundAmount money.Amount   `gorm:"not null;default:0"`
+	Currency     money.Currency `gorm:"type:varchar(3);not null;default:''"`
+	RefundedAt   *time.Time     `gorm:""`
+	CreatedAt    time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time      `gorm:"autoUpdateTime"`
+	Items        []ReturnItem   `gorm:"foreignKey:ReturnID;constraint:OnDelete:CASCADE"`
+}
+
+// ReturnItem is how many units of an order item are sent back.
+type ReturnItem struct {
+	ID          uint `gorm:"primaryKey;autoIncrement"`
+	ReturnID    uint `gorm:"index"`
+	OrderItemID uint `gorm:"index"`
+	Quantity    int  `gorm:"not null"`
+}
+
+// returnedQuantities sums the units of every order item in the returns that match.
+func returnedQuantities(returns []Return, match func(Status) bool) map[uint]int {
+	returned := map[uint]int{}
+	for _, r := range returns {
+		if !match(r.Status) {
+			continue
+		}
+		for _, item := range r.Items {
+			returned[item.OrderItemID] += item.Quantity
+		}
+	}
+	return returned
+}
@@ -0,0 +1,31 @@
+package returns
+
+import "github.com/p4xx07/order-service/internal/money"
+
+type PostRequest struct {
+	OrderID uint          `json:"order_id,omitempty" validate:"min=1,nonnil" required:"true"`
+	Reason  string        `json:"reason,omitempty" validate:"max=500"`
+	Items   []ItemRequest `json:"items,omitempty" validate:"min=1" required:"true"`
+}
+
+type ItemRequest struct {
+	OrderItemID uint `json:"order_item_id,omitempty" validate:"min=1,nonnil" required:"true"`
+	Quantity    int  `json:"quantity,omitempty" validate:"min=1,nonnil" required:"true"`
+}
+
+type RejectRequest struct {
+	ID   uint   `json:"id,omitempty" validate:"min=1,nonnil" required:"true"`
+	Note string `json:"note,omitempty" validate:"max=500"`
+}
+
+type ReceiveRequest struct {
+	ID uint `json:"id,omitempty" validate:"min=1,nonnil" required:"true"`
+	// Restock puts the returned units back into the inventory.
+	Restock bool `json:"restock,omitempty"`
+}
+
+type RefundRequest struct {
+	ID uint `json:"id,omitempty" validate:"min=1,nonnil" required:"true"`
+	// Amount defaults to what was paid for the returned units.
+	Amount *money.Amount `json:"amount,omitempty"`
+}
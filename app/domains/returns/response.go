@@ -0,0 +1,51 @@
+package returns
+
+import (
+	"github.com/p4xx07/order-service/internal/money"
+	"time"
+)
+
+type ReturnResponse struct {
+	ID           uint                 `json:"id"`
+	OrderID      uint                 `json:"order_id"`
+	Status       Status               `json:"status"`
+	Reason       string               `json:"reason,omitempty"`
+	Note         string               `json:"note,omitempty"`
+	Restock      bool                 `json:"restock"`
+	ReceivedAt   *time.Time           `json:"received_at,omitempty"`
+	RefundAmount money.Amount         `json:"refund_amount"`
+	Currency     money.Currency       `json:"currency"`
+	RefundedAt   *time.Time           `json:"refunded_at,omitempty"`
+	CreatedAt    time.Time            `json:"created_at"`
+	Items        []ReturnItemResponse `json:"items"`
+}
+
+func (r *Return) ToResponse() ReturnResponse {
+	items := make([]ReturnItemResponse, len(r.Items))
+	for i, item := range r.Items {
+		items[i] = ReturnItemResponse{OrderItemID: item.OrderItemID, Quantity: item.Quantity}
+	}
+	return ReturnResponse{
+		ID:           r.ID,
+		OrderID:      r.OrderID,
+		Status:       r.Status,
+		Reason:       r.Reason,
+		Note:         r.Note,
+		Restock:      r.Restock,
+		ReceivedAt:   r.ReceivedAt,
+		RefundAmount: r.RefundAmount,
+		Currency:     r.Currency,
+		RefundedAt:   r.RefundedAt,
+		CreatedAt:    r.CreatedAt,
+		Items:        items,
+	}
+}
+
+type ReturnItemResponse struct {
+	OrderItemID uint `json:"order_item_id"`
+	Quantity    int  `json:"quantity"`
+}
+
+type ListReturnsResponse struct {
+	Items []ReturnResponse `json:"items"`
+}
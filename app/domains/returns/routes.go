@@ -0,0 +1,17 @@
+package returns
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetRoutes(router fiber.Router, handler IHandler) {
+	g := router.Group("return")
+	g.Post("/", handler.Post)
+	g.Get("/:id", handler.Get)
+	g.Post("/:id/approval", handler.Approve)
+	g.Post("/:id/rejection", handler.Reject)
+	g.Post("/:id/receipt", handler.Receive)
+	g.Post("/:id/refund", handler.Refund)
+
+	router.Get("/order/:id/returns", handler.ListByOrder)
+}
@@ -0,0 +1,366 @@
+package returns
+
+import (
+	"context"
+	"fmt"
+	"github.com/p4xx07/order-service/app/domains/inventory"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/db"
+	"github.com/p4xx07/order-service/internal/money"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"math"
+	"time"
+)
+
+// IService runs returns from the request through approval and receipt of the goods to the refund.
+// Receiving the goods moves the order to partially_returned or returned.
+type IService interface {
+	Create(ctx context.Context, request PostRequest) (*ReturnResponse, error)
+	Get(ctx context.Context, id uint) (*ReturnResponse, error)
+	ListByOrder(ctx context.Context, orderID uint) (*ListReturnsResponse, error)
+	Approve(ctx context.Context, id uint) (*ReturnResponse, error)
+	Reject(ctx context.Context, request RejectRequest) (*ReturnResponse, error)
+	Receive(ctx context.Context, request ReceiveRequest) (*ReturnResponse, error)
+	Refund(ctx context.Context, request RefundRequest) (*ReturnResponse, error)
+}
+
+type service struct {
+	configuration    *configuration.Configuration
+	logger           *zap.SugaredLogger
+	store            IStore
+	orderService     order.IService
+	inventoryService inventory.IService
+	transactor       db.ITransactor
+}
+
+func NewService(store IStore, orderService order.IService, inventoryService inventory.IService, transactor db.ITransactor, configuration *configuration.Configuration, logger *zap.SugaredLogger) IService {
+	return &service{store: store, orderService: orderService, inventoryService: inventoryService, transactor: transactor, configuration: configuration, logger: logger}
+}
+
+// Create asks back units of a delivered order. The returns of the order stay locked while the return is stored,
+// so that concurrent requests cannot both ask back the same units.
+func (s *service) Create(ctx context.Context, request PostRequest) (*ReturnResponse, error) {
+	orderResponse, err := s.orderService.Get(ctx, request.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if orderResponse.Status != order.StatusDelivered && orderResponse.Status != order.StatusPartiallyReturned {
+		return nil, fmt.Errorf("%w: order is %s", ErrOrderNotReturnable, orderResponse.Status)
+	}
+
+	r := &Return{
+		OrderID:  request.OrderID,
+		Status:   StatusRequested,
+		Reason:   request.Reason,
+		Currency: orderResponse.Currency,
+	}
+
+	err = s.transactor.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		store := s.store.WithTx(tx)
+
+		returns, err := store.ListByOrderForUpdate(ctx, request.OrderID)
+		if err != nil {
+			s.logger.Errorw("error listing returns", "error", err, "orderID", request.OrderID)
+			return err
+		}
+
+		// units already asked back count against the order quantities unless their return was turned down
+		requested := returnedQuantities(returns, func(status Status) bool { return status != StatusRejected })
+		remaining := map[uint]int{}
+		for _, item := range orderResponse.Items {
+			remaining[item.ID] = item.Quantity - requested[item.ID]
+		}
+
+		r.Items = make([]ReturnItem, len(request.Items))
+		for i, item := range request.Items {
+			if item.Quantity <= 0 || item.Quantity > remaining[item.OrderItemID] {
+				return fmt.Errorf("%w: %d units of order item %d can be returned", ErrInvalidItems, max(remaining[item.OrderItemID], 0), item.OrderItemID)
+			}
+			remaining[item.OrderItemID] -= item.Quantity
+			r.Items[i] = ReturnItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity}
+		}
+
+		if err := store.Create(ctx, r); err != nil {
+			s.logger.Errorw("failed to store return", "error", err, "orderID", request.OrderID)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := r.ToResponse()
+	return &response, nil
+}
+
+func (s *service) Get(ctx context.Context, id uint) (*ReturnResponse, error) {
+	r, err := s.store.Get(ctx, id)
+	if err != nil {
+		s.logger.Errorw("error getting return", "error", err, "id", id)
+		return nil, err
+	}
+
+	response := r.ToResponse()
+	return &response, nil
+}
+
+func (s *service) ListByOrder(ctx context.Context, orderID uint) (*ListReturnsResponse, error) {
+	if _, err := s.orderService.Get(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	returns, err := s.store.ListByOrder(ctx, orderID)
+	if err != nil {
+		s.logger.Errorw("error listing returns", "error", err, "orderID", orderID)
+		return nil, err
+	}
+
+	items := make([]ReturnResponse, len(returns))
+	for i := range returns {
+		items[i] = returns[i].ToResponse()
+	}
+	return &ListReturnsResponse{Items: items}, nil
+}
+
+func (s *service) Approve(ctx context.Context, id uint) (*ReturnResponse, error) {
+	return s.transition(ctx, id, StatusApproved, func(r *Return) {})
+}
+
+func (s *service) Reject(ctx context.Context, request RejectRequest) (*ReturnResponse, error) {
+	return s.transition(ctx, request.ID, StatusRejected, func(r *Return) {
+		r.Note = request.Note
+	})
+}
+
+// transition moves a return that needs nothing besides its own record to the next status.
+func (s *service) transition(ctx context.Context, id uint, next Status, apply func(r *Return)) (*ReturnResponse, error) {
+	r, err := s.store.Get(ctx, id)
+	if err != nil {
+		s.logger.Errorw("error getting return", "error", err, "id", id)
+		return nil, err
+	}
+
+	from := r.Status
+	if !from.CanTransitionTo(next) {
+		return nil, fmt.Errorf("%w: from %s to %s", ErrInvalidTransition, from, next)
+	}
+
+	r.Status = next
+	apply(r)
+	if err := s.store.Update(ctx, r, from); err != nil {
+		s.logger.Errorw("error updating return", "error", err, "id", id)
+		return nil, err
+	}
+
+	response := r.ToResponse()
+	return &response, nil
+}
+
+// Receive records the goods of an approved return as back, restocks them when asked to,
+// and moves the order to returned once every unit it had came back.
+// The returns of the order stay locked while it does, so that the order status is worked out from the receipts as committed.
+func (s *service) Receive(ctx context.Context, request ReceiveRequest) (*ReturnResponse, error) {
+	r, err := s.store.Get(ctx, request.ID)
+	if err != nil {
+		s.logger.Errorw("error getting return", "error", err, "id", request.ID)
+		return nil, err
+	}
+
+	if !r.Status.CanTransitionTo(StatusReceived) {
+		return nil, fmt.Errorf("%w: from %s to %s", ErrInvalidTransition, r.Status, StatusReceived)
+	}
+
+	err = s.transactor.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		store := s.store.WithTx(tx)
+
+		returns, err := store.ListByOrderForUpdate(ctx, r.OrderID)
+		if err != nil {
+			s.logger.Errorw("error listing returns", "error", err, "orderID", r.OrderID)
+			return err
+		}
+
+		locked := findReturn(returns, r.ID)
+		if locked == nil {
+			return gorm.ErrRecordNotFound
+		}
+		from := locked.Status
+		if !from.CanTransitionTo(StatusReceived) {
+			return fmt.Errorf("%w: from %s to %s", ErrInvalidTransition, from, StatusReceived)
+		}
+
+		// read after the lock, so the status reflects the receipts committed before this one
+		orderResponse, err := s.orderService.WithTx(tx).Get(ctx, r.OrderID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		*r = *locked
+		r.Status = StatusReceived
+		r.Restock = request.Restock
+		r.ReceivedAt = &now
+
+		if err := store.Update(ctx, r, from); err != nil {
+			s.logger.Errorw("error updating return", "error", err, "id", r.ID)
+			return err
+		}
+
+		if r.Restock {
+			reference := inventory.Reference{Reason: inventory.ReasonOrderReturned, OrderID: r.OrderID}
+			if err := s.inventoryService.WithTx(tx).IncreaseStockBulk(ctx, restockQuantities(orderResponse.Items, r.Items), reference); err != nil {
+				s.logger.Errorw("error increasing stock", "error", err, "id", r.ID)
+				return err
+			}
+		}
+
+		next := returnedStatus(orderResponse.Items, returns, r)
+		if orderResponse.Status == next {
+			return nil
+		}
+
+		transition := order.TransitionRequest{ID: r.OrderID, Status: next}
+		_, err = s.orderService.WithTx(tx).Transition(ctx, transition)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := r.ToResponse()
+	return &response, nil
+}
+
+// findReturn picks the return with the given id out of returns.
+func findReturn(returns []Return, id uint) *Return {
+	for i := range returns {
+		if returns[i].ID == id {
+			return &returns[i]
+		}
+	}
+	return nil
+}
+
+// returnedStatus is the order status once the goods of received came back as well.
+func returnedStatus(orderItems []order.OrderItemResponse, returns []Return, received *Return) order.Status {
+	returned := returnedQuantities(returns, func(status Status) bool { return status.HasGoods() })
+	for _, item := range received.Items {
+		returned[item.OrderItemID] += item.Quantity
+	}
+
+	for _, item := range orderItems {
+		if returned[item.ID] < item.Quantity {
+			return order.StatusPartiallyReturned
+		}
+	}
+	return order.StatusReturned
+}
+
+// restockQuantities maps the returned units to the products of the order items.
+func restockQuantities(orderItems []order.OrderItemResponse, items []ReturnItem) map[uint]int {
+	products := map[uint]uint{}
+	for _, item := range orderItems {
+		products[item.ID] = item.Product.ID
+	}
+
+	quantities := map[uint]int{}
+	for _, item := range items {
+		quantities[products[item.OrderItemID]] += item.Quantity
+	}
+	return quantities
+}
+
+// Refund records the money paid back for a received return. It defaults to what was paid for the returned units
+// and, across all the returns of an order, can never exceed the order total.
+// The returns of the order stay locked while the refund is recorded, so that concurrent refunds cannot both use up the rest.
+func (s *service) Refund(ctx context.Context, request RefundRequest) (*ReturnResponse, error) {
+	r, err := s.store.Get(ctx, request.ID)
+	if err != nil {
+		s.logger.Errorw("error getting return", "error", err, "id", request.ID)
+		return nil, err
+	}
+
+	from := r.Status
+	if !from.CanTransitionTo(StatusRefunded) {
+		return nil, fmt.Errorf("%w: from %s to %s", ErrInvalidTransition, from, StatusRefunded)
+	}
+
+	orderResponse, err := s.orderService.Get(ctx, r.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.transactor.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		store := s.store.WithTx(tx)
+
+		returns, err := store.ListByOrderForUpdate(ctx, r.OrderID)
+		if err != nil {
+			s.logger.Errorw("error listing returns", "error", err, "orderID", r.OrderID)
+			return err
+		}
+
+		refundable := orderResponse.Total
+		for _, other := range returns {
+			if other.ID != r.ID {
+				refundable -= other.RefundAmount
+			}
+		}
+
+		amount := min(paid(orderResponse, r.Items), refundable)
+		if request.Amount != nil {
+			amount = *request.Amount
+		}
+		if request.Amount != nil && amount <= 0 {
+			return fmt.Errorf("%w: the amount must be greater than zero", ErrInvalidRefund)
+		}
+		if amount <= 0 || amount > refundable {
+			return fmt.Errorf("%w: up to %s %s can be refunded", ErrInvalidRefund, max(refundable, 0), orderResponse.Currency)
+		}
+
+		now := time.Now()
+		r.Status = StatusRefunded
+		r.RefundAmount = amount
+		r.RefundedAt = &now
+		if err := store.Update(ctx, r, from); err != nil {
+			s.logger.Errorw("error updating return", "error", err, "id", r.ID)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := r.ToResponse()
+	return &response, nil
+}
+
+// paid is what the customer paid for the returned units: the item totals less their share of the order discount,
+// plus the taxes that were added on top.
+func paid(o *order.OrderResponse, items []ReturnItem) money.Amount {
+	orderItems := map[uint]order.OrderItemResponse{}
+	for _, item := range o.Items {
+		orderItems[item.ID] = item
+	}
+
+	var amount float64
+	for _, returned := range items {
+		item, ok := orderItems[returned.OrderItemID]
+		if !ok || item.Quantity == 0 {
+			continue
+		}
+
+		line := float64(item.Total)
+		if o.Subtotal > 0 {
+			line -= float64(o.Discount) * float64(item.Total) / float64(o.Subtotal)
+		}
+		if !item.TaxInclusive {
+			line += float64(item.Tax)
+		}
+		amount += line * float64(returned.Quantity) / float64(item.Quantity)
+	}
+	return money.Amount(math.Round(amount))
+}
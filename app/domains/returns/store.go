@@ -0,0 +1,86 @@
+package returns
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IStore interface {
+	Create(ctx context.Context, r *Return) error
+	Get(ctx context.Context, id uint) (*Return, error)
+	ListByOrder(ctx context.Context, orderID uint) ([]Return, error)
+	ListByOrderForUpdate(ctx context.Context, orderID uint) ([]Return, error)
+	Update(ctx context.Context, r *Return, from Status) error
+	WithTx(tx *gorm.DB) IStore
+}
+
+type store struct {
+	db *gorm.DB
+}
+
+func NewStore(db *gorm.DB) IStore {
+	return &store{db: db}
+}
+
+func (s *store) WithTx(tx *gorm.DB) IStore {
+	return &store{db: tx}
+}
+
+func (s *store) Create(ctx context.Context, r *Return) error {
+	return s.db.WithContext(ctx).Create(r).Error
+}
+
+func (s *store) Get(ctx context.Context, id uint) (*Return, error) {
+	var r Return
+	err := s.db.
+		WithContext(ctx).
+		Preload("Items").
+		Where("id = ?", id).
+		First(&r).Error
+
+	return &r, err
+}
+
+func (s *store) ListByOrder(ctx context.Context, orderID uint) ([]Return, error) {
+	var returns []Return
+	err := s.db.
+		WithContext(ctx).
+		Preload("Items").
+		Where("order_id = ?", orderID).
+		Order("id").
+		Find(&returns).Error
+
+	return returns, err
+}
+
+// ListByOrderForUpdate lists the returns of the order and locks their rows until the transaction ends.
+func (s *store) ListByOrderForUpdate(ctx context.Context, orderID uint) ([]Return, error) {
+	var returns []Return
+	err := s.db.
+		WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Items").
+		Where("order_id = ?", orderID).
+		Order("id").
+		Find(&returns).Error
+
+	return returns, err
+}
+
+// Update saves the status and outcome of a return, as long as it is still in status from.
+func (s *store) Update(ctx context.Context, r *Return, from Status) error {
+	result := s.db.
+		WithContext(ctx).
+		Model(&Return{}).
+		Where("id = ? AND status = ?", r.ID, from).
+		Select("status", "note", "restock", "received_at", "refund_amount", "refunded_at").
+		Updates(r)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTransition
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package shipment
+
+import "errors"
+
+var (
+	ErrOrderNotShippable = errors.New("order cannot be shipped")
+	ErrInvalidItems      = errors.New("invalid shipment items")
+	ErrNothingToShip     = errors.New("all order items are already shipped")
+	ErrAlreadyDelivered  = errors.New("shipment already delivered")
+)
@@ -0,0 +1,126 @@
+package shipment
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/p4xx07/order-service/app/domains/order"
+	http2 "github.com/p4xx07/order-service/internal/http"
+	"go.uber.org/zap"
+	"gopkg.in/validator.v2"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+type IHandler interface {
+	Post(ctx *fiber.Ctx) error
+	Get(ctx *fiber.Ctx) error
+	ListByOrder(ctx *fiber.Ctx) error
+	Deliver(ctx *fiber.Ctx) error
+}
+
+type handler struct {
+	service IService
+	logger  *zap.SugaredLogger
+}
+
+func NewHandler(service IService, logger *zap.SugaredLogger) IHandler {
+	return &handler{service: service, logger: logger}
+}
+
+func (h *handler) Post(c *fiber.Ctx) error {
+	var request PostRequest
+	if err := c.BodyParser(&request); err != nil {
+		h.logger.Errorf("bodyRequest error %v | %v", request, err.Error())
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	if errs := validator.Validate(request); errs != nil {
+		return c.Status(http.StatusBadRequest).JSON(errs)
+	}
+
+	response, err := h.service.Create(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, ErrInvalidItems) {
+			return http2.JSON(c, http.StatusUnprocessableEntity, nil, err)
+		}
+		if errors.Is(err, ErrOrderNotShippable) || errors.Is(err, ErrNothingToShip) || errors.Is(err, order.ErrInvalidTransition) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) Get(c *fiber.Ctx) error {
+	shipmentID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	response, err := h.service.Get(c.Context(), uint(shipmentID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) ListByOrder(c *fiber.Ctx) error {
+	orderID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	response, err := h.service.ListByOrder(c.Context(), uint(orderID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
+
+func (h *handler) Deliver(c *fiber.Ctx) error {
+	shipmentID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(err)
+	}
+
+	var request DeliverRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			h.logger.Errorf("bodyRequest error: %v", err.Error())
+			return c.Status(http.StatusBadRequest).JSON("Invalid request body")
+		}
+	}
+	request.ID = uint(shipmentID)
+
+	response, err := h.service.Deliver(c.Context(), request)
+	if err != nil {
+		if errors.Is(err, ErrAlreadyDelivered) || errors.Is(err, order.ErrInvalidTransition) {
+			return http2.JSON(c, http.StatusConflict, nil, err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return http2.JSON(c, http.StatusNotFound, nil, err)
+		}
+
+		h.logger.Error(err)
+		return c.SendStatus(http.StatusInternalServerError)
+	}
+
+	return http2.JSON(c, http.StatusOK, response, nil)
+}
@@ -0,0 +1,45 @@
+package shipment
+
+import (
+	"time"
+)
+
+type Status string
+
+const (
+	StatusShipped   Status = "shipped"
+	StatusDelivered Status = "delivered"
+)
+
+// Shipment is a parcel handed to a carrier with some or all of the items of an order.
+type Shipment struct {
+	ID             uint           `gorm:"primaryKey;autoIncrement"`
+	OrderID        uint           `gorm:"index"`
+	Carrier        string         `gorm:"type:varchar(50);not null"`
+	TrackingNumber string         `gorm:"type:varchar(100);not null;index"`
+	Status         Status         `gorm:"type:varchar(20);not null"`
+	ShippedAt      time.Time      `gorm:"not null"`
+	DeliveredAt    *time.Time     `gorm:""`
+	CreatedAt      time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime"`
+	Items          []ShipmentItem `gorm:"foreignKey:ShipmentID;constraint:OnDelete:CASCADE"`
+}
+
+// ShipmentItem is how many units of an order item went out with a shipment.
+type ShipmentItem struct {
+	ID          uint `gorm:"primaryKey;autoIncrement"`
+	ShipmentID  uint `gorm:"index"`
+	OrderItemID uint `gorm:"index"`
+	Quantity    int  `gorm:"not null"`
+}
+
+// shippedQuantities sums the units of every order item the shipments sent.
+func shippedQuantities(shipments []Shipment) map[uint]int {
+	shipped := map[uint]int{}
+	for _, shipment := range shipments {
+		for _, item := range shipment.Items {
+			shipped[item.OrderItemID] += item.Quantity
+		}
+	}
+	return shipped
+}
@@ -0,0 +1,22 @@
+package shipment
+
+import "time"
+
+type PostRequest struct {
+	OrderID        uint       `json:"order_id,omitempty" validate:"min=1,nonnil" required:"true"`
+	Carrier        string     `json:"carrier,omitempty" validate:"nonzero,max=50" required:"true"`
+	TrackingNumber string     `json:"tracking_number,omitempty" validate:"nonzero,max=100" required:"true"`
+	ShippedAt      *time.Time `json:"shipped_at,omitempty"`
+	// Items are the order items in the shipment; left out, everything not shipped yet goes.
+	Items []ItemRequest `json:"items,omitempty"`
+}
+
+type ItemRequest struct {
+	OrderItemID uint `json:"order_item_id,omitempty" validate:"min=1,nonnil" required:"true"`
+	Quantity    int  `json:"quantity,omitempty" validate:"min=1,nonnil" required:"true"`
+}
+
+type DeliverRequest struct {
+	ID          uint       `json:"id,omitempty" validate:"min=1,nonnil" required:"true"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+}
@@ -0,0 +1,40 @@
+package shipment
+
+import "time"
+
+type ShipmentResponse struct {
+	ID             uint                   `json:"id"`
+	OrderID        uint                   `json:"order_id"`
+	Carrier        string                 `json:"carrier"`
+	TrackingNumber string                 `json:"tracking_number"`
+	Status         Status                 `json:"status"`
+	ShippedAt      time.Time              `json:"shipped_at"`
+	DeliveredAt    *time.Time             `json:"delivered_at,omitempty"`
+	Items          []ShipmentItemResponse `json:"items"`
+}
+
+func (s *Shipment) ToResponse() ShipmentResponse {
+	items := make([]ShipmentItemResponse, len(s.Items))
+	for i, item := range s.Items {
+		items[i] = ShipmentItemResponse{OrderItemID: item.OrderItemID, Quantity: item.Quantity}
+	}
+	return ShipmentResponse{
+		ID:             s.ID,
+		OrderID:        s.OrderID,
+		Carrier:        s.Carrier,
+		TrackingNumber: s.TrackingNumber,
+		Status:         s.Status,
+		ShippedAt:      s.ShippedAt,
+		DeliveredAt:    s.DeliveredAt,
+		Items:          items,
+	}
+}
+
+type ShipmentItemResponse struct {
+	OrderItemID uint `json:"order_item_id"`
+	Quantity    int  `json:"quantity"`
+}
+
+type ListShipmentsResponse struct {
+	Items []ShipmentResponse `json:"items"`
+}
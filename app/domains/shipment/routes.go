@@ -0,0 +1,14 @@
+package shipment
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetRoutes(router fiber.Router, handler IHandler) {
+	g := router.Group("shipment")
+	g.Post("/", handler.Post)
+	g.Get("/:id", handler.Get)
+	g.Post("/:id/delivery", handler.Deliver)
+
+	router.Get("/order/:id/shipments", handler.ListByOrder)
+}
@@ -0,0 +1,219 @@
+package shipment
+
+import (
+	"context"
+	"fmt"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/db"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"time"
+)
+
+// IService ships orders and drives them to shipped with their first shipment
+// and to delivered once every item went out and every shipment arrived.
+type IService interface {
+	Create(ctx context.Context, request PostRequest) (*ShipmentResponse, error)
+	Get(ctx context.Context, id uint) (*ShipmentResponse, error)
+	ListByOrder(ctx context.Context, orderID uint) (*ListShipmentsResponse, error)
+	Deliver(ctx context.Context, request DeliverRequest) (*ShipmentResponse, error)
+}
+
+type service struct {
+	configuration *configuration.Configuration
+	logger        *zap.SugaredLogger
+	store         IStore
+	orderService  order.IService
+	transactor    db.ITransactor
+}
+
+func NewService(store IStore, orderService order.IService, transactor db.ITransactor, configuration *configuration.Configuration, logger *zap.SugaredLogger) IService {
+	return &service{store: store, orderService: orderService, transactor: transactor, configuration: configuration, logger: logger}
+}
+
+func (s *service) Create(ctx context.Context, request PostRequest) (*ShipmentResponse, error) {
+	orderResponse, err := s.orderService.Get(ctx, request.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if orderResponse.Status != order.StatusPaid && orderResponse.Status != order.StatusShipped {
+		return nil, fmt.Errorf("%w: order is %s", ErrOrderNotShippable, orderResponse.Status)
+	}
+
+	shipments, err := s.store.ListByOrder(ctx, request.OrderID)
+	if err != nil {
+		s.logger.Errorw("error listing shipments", "error", err, "orderID", request.OrderID)
+		return nil, err
+	}
+
+	items, err := shipmentItems(orderResponse.Items, shippedQuantities(shipments), request.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	shippedAt := time.Now()
+	if request.ShippedAt != nil {
+		shippedAt = *request.ShippedAt
+	}
+
+	shipment := &Shipment{
+		OrderID:        request.OrderID,
+		Carrier:        request.Carrier,
+		TrackingNumber: request.TrackingNumber,
+		Status:         StatusShipped,
+		ShippedAt:      shippedAt,
+		Items:          items,
+	}
+
+	err = s.transactor.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		if err := s.store.WithTx(tx).Create(ctx, shipment); err != nil {
+			s.logger.Errorw("failed to store shipment", "error", err, "orderID", request.OrderID)
+			return err
+		}
+
+		if orderResponse.Status != order.StatusPaid {
+			return nil
+		}
+
+		transition := order.TransitionRequest{ID: request.OrderID, Status: order.StatusShipped}
+		_, err := s.orderService.WithTx(tx).Transition(ctx, transition)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := shipment.ToResponse()
+	return &response, nil
+}
+
+// shipmentItems checks the requested items against what is left to ship of the order,
+// or ships everything left when no items are requested.
+func shipmentItems(orderItems []order.OrderItemResponse, shipped map[uint]int, requested []ItemRequest) ([]ShipmentItem, error) {
+	remaining := map[uint]int{}
+	for _, item := range orderItems {
+		remaining[item.ID] = item.Quantity - shipped[item.ID]
+	}
+
+	var items []ShipmentItem
+	if len(requested) == 0 {
+		for _, item := range orderItems {
+			if remaining[item.ID] > 0 {
+				items = append(items, ShipmentItem{OrderItemID: item.ID, Quantity: remaining[item.ID]})
+			}
+		}
+		if len(items) == 0 {
+			return nil, ErrNothingToShip
+		}
+		return items, nil
+	}
+
+	for _, item := range requested {
+		if item.Quantity <= 0 || item.Quantity > remaining[item.OrderItemID] {
+			return nil, fmt.Errorf("%w: %d units of order item %d are left to ship", ErrInvalidItems, max(remaining[item.OrderItemID], 0), item.OrderItemID)
+		}
+		remaining[item.OrderItemID] -= item.Quantity
+		items = append(items, ShipmentItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity})
+	}
+	return items, nil
+}
+
+func (s *service) Get(ctx context.Context, id uint) (*ShipmentResponse, error) {
+	shipment, err := s.store.Get(ctx, id)
+	if err != nil {
+		s.logger.Errorw("error getting shipment", "error", err, "id", id)
+		return nil, err
+	}
+
+	response := shipment.ToResponse()
+	return &response, nil
+}
+
+func (s *service) ListByOrder(ctx context.Context, orderID uint) (*ListShipmentsResponse, error) {
+	if _, err := s.orderService.Get(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	shipments, err := s.store.ListByOrder(ctx, orderID)
+	if err != nil {
+		s.logger.Errorw("error listing shipments", "error", err, "orderID", orderID)
+		return nil, err
+	}
+
+	items := make([]ShipmentResponse, len(shipments))
+	for i := range shipments {
+		items[i] = shipments[i].ToResponse()
+	}
+	return &ListShipmentsResponse{Items: items}, nil
+}
+
+// Deliver marks a shipment as delivered, and the order as well when nothing else is left to arrive.
+func (s *service) Deliver(ctx context.Context, request DeliverRequest) (*ShipmentResponse, error) {
+	shipment, err := s.store.Get(ctx, request.ID)
+	if err != nil {
+		s.logger.Errorw("error getting shipment", "error", err, "id", request.ID)
+		return nil, err
+	}
+	if shipment.Status == StatusDelivered {
+		return nil, ErrAlreadyDelivered
+	}
+
+	orderResponse, err := s.orderService.Get(ctx, shipment.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	shipments, err := s.store.ListByOrder(ctx, shipment.OrderID)
+	if err != nil {
+		s.logger.Errorw("error listing shipments", "error", err, "orderID", shipment.OrderID)
+		return nil, err
+	}
+
+	deliveredAt := time.Now()
+	if request.DeliveredAt != nil {
+		deliveredAt = *request.DeliveredAt
+	}
+
+	err = s.transactor.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		if err := s.store.WithTx(tx).MarkDelivered(ctx, shipment.ID, deliveredAt); err != nil {
+			s.logger.Errorw("error marking shipment delivered", "error", err, "id", shipment.ID)
+			return err
+		}
+
+		if orderResponse.Status != order.StatusShipped || !allDelivered(orderResponse.Items, shipments, shipment.ID) {
+			return nil
+		}
+
+		transition := order.TransitionRequest{ID: shipment.OrderID, Status: order.StatusDelivered}
+		_, err := s.orderService.WithTx(tx).Transition(ctx, transition)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	shipment.Status = StatusDelivered
+	shipment.DeliveredAt = &deliveredAt
+
+	response := shipment.ToResponse()
+	return &response, nil
+}
+
+// allDelivered reports whether every order item was shipped and every shipment, besides the one being delivered, arrived.
+func allDelivered(orderItems []order.OrderItemResponse, shipments []Shipment, delivering uint) bool {
+	for _, shipment := range shipments {
+		if shipment.ID != delivering && shipment.Status != StatusDelivered {
+			return false
+		}
+	}
+
+	shipped := shippedQuantities(shipments)
+	for _, item := range orderItems {
+		if shipped[item.ID] < item.Quantity {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package shipment
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"time"
+)
+
+type IStore interface {
+	Create(ctx context.Context, shipment *Shipment) error
+	Get(ctx context.Context, id uint) (*Shipment, error)
+	ListByOrder(ctx context.Context, orderID uint) ([]Shipment, error)
+	MarkDelivered(ctx context.Context, id uint, deliveredAt time.Time) error
+	WithTx(tx *gorm.DB) IStore
+}
+
+type store struct {
+	db *gorm.DB
+}
+
+func NewStore(db *gorm.DB) IStore {
+	return &store{db: db}
+}
+
+func (s *store) WithTx(tx *gorm.DB) IStore {
+	return &store{db: tx}
+}
+
+func (s *store) Create(ctx context.Context, shipment *Shipment) error {
+	return s.db.WithContext(ctx).Create(shipment).Error
+}
+
+func (s *store) Get(ctx context.Context, id uint) (*Shipment, error) {
+	var shipment Shipment
+	err := s.db.
+		WithContext(ctx).
+		Preload("Items").
+		Where("id = ?", id).
+		First(&shipment).Error
+
+	return &shipment, err
+}
+
+func (s *store) ListByOrder(ctx context.Context, orderID uint) ([]Shipment, error) {
+	var shipments []Shipment
+	err := s.db.
+		WithContext(ctx).
+		Preload("Items").
+		Where("order_id = ?", orderID).
+		Order("id").
+		Find(&shipments).Error
+
+	return shipments, err
+}
+
+// MarkDelivered only moves shipments that are still on their way, so a shipment is delivered once.
+func (s *store) MarkDelivered(ctx context.Context, id uint, deliveredAt time.Time) error {
+	result := s.db.
+		WithContext(ctx).
+		Model(&Shipment{}).
+		Where("id = ? AND status = ?", id, StatusShipped).
+		Updates(map[string]any{"status": StatusDelivered, "delivered_at": deliveredAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAlreadyDelivered
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package tax
+
+import (
+	"context"
+	"go.uber.org/zap"
+)
+
+// TaxCalculator works out the tax of the lines of an order shipped to a region.
+// It returns one LineTax per line, in the same order.
+type TaxCalculator interface {
+	Calculate(ctx context.Context, request CalculateRequest) ([]LineTax, error)
+}
+
+type ruleCalculator struct {
+	logger *zap.SugaredLogger
+	store  IStore
+}
+
+// NewRuleCalculator taxes each line at the most specific rate of the tax_rates table matching its category and region.
+// Lines no rate matches are not taxed.
+func NewRuleCalculator(store IStore, logger *zap.SugaredLogger) TaxCalculator {
+	return &ruleCalculator{store: store, logger: logger}
+}
+
+func (c *ruleCalculator) Calculate(ctx context.Context, request CalculateRequest) ([]LineTax, error) {
+	rates, err := c.store.List(ctx)
+	if err != nil {
+		c.logger.Errorw("error listing tax rates", "error", err)
+		return nil, err
+	}
+
+	taxes := make([]LineTax, len(request.Lines))
+	for i, line := range request.Lines {
+		var rate *Rate
+		for j := range rates {
+			if rates[j].matches(line.Category, request.Region) && (rate == nil || rates[j].specificity() > rate.specificity()) {
+				rate = &rates[j]
+			}
+		}
+		if rate == nil {
+			continue
+		}
+
+		taxes[i] = LineTax{Percent: rate.Percent, Inclusive: rate.Inclusive, Amount: rate.tax(line.Amount)}
+	}
+
+	return taxes, nil
+}
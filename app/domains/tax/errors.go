@@ -0,0 +1,7 @@
+package tax
+
+import "errors"
+
+var (
+	ErrInvalidRate = errors.New("invalid tax rate")
+)
@@ -0,0 +1,57 @@
+package tax
+
+import (
+	"github.com/p4xx07/order-service/internal/money"
+	"math"
+	"time"
+)
+
+// Rate is the tax charged on products of Category shipped to Region.
+// An empty Category or Region matches any, so a rule with neither is the default rate.
+type Rate struct {
+	ID       uint    `gorm:"primaryKey;autoIncrement"`
+	Category string  `gorm:"type:varchar(50);not null;default:'';uniqueIndex:idx_tax_rates_category_region"`
+	Region   string  `gorm:"type:varchar(20);not null;default:'';uniqueIndex:idx_tax_rates_category_region"`
+	Percent  float64 `gorm:"type:decimal(7,4);not null"`
+	// Inclusive rates are already part of the product prices, exclusive ones are added on top.
+	Inclusive bool      `gorm:"not null;default:false"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// specificity ranks the rules matching a line: a category and region rule beats a region rule,
+// which beats a category rule, which beats the default rate.
+func (r *Rate) specificity() int {
+	specificity := 0
+	if r.Region != "" {
+		specificity += 2
+	}
+	if r.Category != "" {
+		specificity++
+	}
+	return specificity
+}
+
+func (r *Rate) matches(category string, region string) bool {
+	return (r.Category == "" || r.Category == category) && (r.Region == "" || r.Region == region)
+}
+
+// tax is the tax due on amount, which already includes it for inclusive rates.
+func (r *Rate) tax(amount money.Amount) money.Amount {
+	if r.Inclusive {
+		return amount - money.Amount(math.Round(float64(amount)/(1+r.Percent/100)))
+	}
+	return money.Amount(math.Round(float64(amount) * r.Percent / 100))
+}
+
+// Line is an order line to be taxed, Amount being what is paid for it after discounts.
+type Line struct {
+	Category string
+	Amount   money.Amount
+}
+
+// LineTax is the tax of a line and the rate it was charged at.
+type LineTax struct {
+	Percent   float64
+	Inclusive bool
+	Amount    money.Amount
+}
@@ -0,0 +1,26 @@
+package tax
+
+type CalculateRequest struct {
+	Region string
+	Lines  []Line
+}
+
+type PutRatesRequest struct {
+	Rates []RateRequest `json:"rates"`
+}
+
+type RateRequest struct {
+	Category  string  `json:"category"`
+	Region    string  `json:"region"`
+	Percent   float64 `json:"percent"`
+	Inclusive bool    `json:"inclusive"`
+}
+
+func (r RateRequest) ToStore() Rate {
+	return Rate{
+		Category:  r.Category,
+		Region:    r.Region,
+		Percent:   r.Percent,
+		Inclusive: r.Inclusive,
+	}
+}
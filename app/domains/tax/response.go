@@ -0,0 +1,27 @@
+package tax
+
+import "time"
+
+type RateResponse struct {
+	ID        uint      `json:"id"`
+	Category  string    `json:"category"`
+	Region    string    `json:"region"`
+	Percent   float64   `json:"percent"`
+	Inclusive bool      `json:"inclusive"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (r *Rate) ToResponse() RateResponse {
+	return RateResponse{
+		ID:        r.ID,
+		Category:  r.Category,
+		Region:    r.Region,
+		Percent:   r.Percent,
+		Inclusive: r.Inclusive,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+type ListRatesResponse struct {
+	Items []RateResponse `json:"items"`
+}
@@ -0,0 +1,12 @@
+package tax
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetAdminRoutes(router fiber.Router, handler IHandler) {
+	g := router.Group("tax")
+	g.Get("/rates", handler.ListRates)
+	g.Put("/rates", handler.PutRates)
+	g.Delete("/rates/:id", handler.DeleteRate)
+}
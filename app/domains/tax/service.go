@@ -0,0 +1,68 @@
+package tax
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/configuration"
+	"go.uber.org/zap"
+)
+
+// IService manages the tax rates the rule based TaxCalculator charges.
+type IService interface {
+	ListRates(ctx context.Context) (*ListRatesResponse, error)
+	SetRates(ctx context.Context, request PutRatesRequest) (*ListRatesResponse, error)
+	DeleteRate(ctx context.Context, id uint) error
+}
+
+type service struct {
+	configuration *configuration.Configuration
+	logger        *zap.SugaredLogger
+	store         IStore
+}
+
+func NewService(store IStore, configuration *configuration.Configuration, logger *zap.SugaredLogger) IService {
+	return &service{store: store, configuration: configuration, logger: logger}
+}
+
+func (s *service) ListRates(ctx context.Context) (*ListRatesResponse, error) {
+	rates, err := s.store.List(ctx)
+	if err != nil {
+		s.logger.Errorw("error listing tax rates", "error", err)
+		return nil, err
+	}
+
+	items := make([]RateResponse, len(rates))
+	for i := range rates {
+		items[i] = rates[i].ToResponse()
+	}
+	return &ListRatesResponse{Items: items}, nil
+}
+
+// SetRates loads the given tax rates; rates of other categories and regions are left alone.
+func (s *service) SetRates(ctx context.Context, request PutRatesRequest) (*ListRatesResponse, error) {
+	if len(request.Rates) == 0 {
+		return nil, ErrInvalidRate
+	}
+
+	rates := make([]Rate, len(request.Rates))
+	for i, rate := range request.Rates {
+		if rate.Percent < 0 || rate.Percent >= 100 || len(rate.Category) > 50 || len(rate.Region) > 20 {
+			return nil, ErrInvalidRate
+		}
+		rates[i] = rate.ToStore()
+	}
+
+	if err := s.store.Save(ctx, rates); err != nil {
+		s.logger.Errorw("error saving tax rates", "error", err)
+		return nil, err
+	}
+
+	return s.ListRates(ctx)
+}
+
+func (s *service) DeleteRate(ctx context.Context, id uint) error {
+	if err := s.store.Delete(ctx, id); err != nil {
+		s.logger.Errorw("error deleting tax rate", "error", err, "id", id)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package tax
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IStore interface {
+	List(ctx context.Context) ([]Rate, error)
+	Save(ctx context.Context, rates []Rate) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type store struct {
+	db *gorm.DB
+}
+
+func NewStore(db *gorm.DB) IStore {
+	return &store{db: db}
+}
+
+func (s *store) List(ctx context.Context) ([]Rate, error) {
+	var rates []Rate
+	if err := s.db.WithContext(ctx).Order("region, category").Find(&rates).Error; err != nil {
+		return nil, fmt.Errorf("failed to list tax rates: %w", err)
+	}
+	return rates, nil
+}
+
+// Save inserts the rates, replacing those already set for the same category and region.
+func (s *store) Save(ctx context.Context, rates []Rate) error {
+	return s.db.
+		WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "category"}, {Name: "region"}},
+			DoUpdates: clause.AssignmentColumns([]string{"percent", "inclusive", "updated_at"}),
+		}).
+		Create(&rates).Error
+}
+
+func (s *store) Delete(ctx context.Context, id uint) error {
+	result := s.db.WithContext(ctx).Where("id = ?", id).Delete(&Rate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+package user
+
+import "errors"
+
+var (
+	ErrEmailTaken     = errors.New("email already in use")
+	ErrInvalidCountry = errors.New("invalid country code")
+)
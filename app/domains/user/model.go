@@ -1,10 +1,32 @@
 package user
 
-import "time"
+import (
+	"gorm.io/gorm"
+	"time"
+)
 
 type User struct {
 	ID        uint   `gorm:"primaryKey"`
 	Name      string `gorm:"type:varchar(100)"`
 	Email     string `gorm:"uniqueIndex;type:varchar(100)"`
 	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// Address is a shipping address of a user. Orders keep a copy of it, so editing or deleting it leaves them alone.
+type Address struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"index"`
+	Name       string `gorm:"type:varchar(100);not null"`
+	Line1      string `gorm:"type:varchar(200);not null"`
+	Line2      string `gorm:"type:varchar(200)"`
+	City       string `gorm:"type:varchar(100);not null"`
+	PostalCode string `gorm:"type:varchar(20)"`
+	// Region is the state or province, Country the ISO 3166-1 alpha-2 code.
+	Region    string `gorm:"type:varchar(100)"`
+	Country   string `gorm:"type:varchar(2);not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
@@ -0,0 +1,50 @@
+package user
+
+import "strings"
+
+type ListRequest struct {
+	Limit  int `json:"limit,omitempty"`
+	Offset int `json:"offset,omitempty"`
+}
+
+type PostRequest struct {
+	Name  string `json:"name,omitempty" validate:"nonzero,max=100" required:"true"`
+	Email string `json:"email,omitempty" validate:"nonzero,max=100,regexp=^[^@ ]+@[^@ ]+$" required:"true"`
+}
+
+type PutRequest struct {
+	ID    uint   `json:"id,omitempty" validate:"min=1,nonnil" required:"true"`
+	Name  string `json:"name,omitempty" validate:"nonzero,max=100" required:"true"`
+	Email string `json:"email,omitempty" validate:"nonzero,max=100,regexp=^[^@ ]+@[^@ ]+$" required:"true"`
+}
+
+type AddressRequest struct {
+	UserID     uint   `json:"user_id,omitempty" validate:"min=1,nonnil" required:"true"`
+	Name       string `json:"name,omitempty" validate:"nonzero,max=100" required:"true"`
+	Line1      string `json:"line1,omitempty" validate:"nonzero,max=200" required:"true"`
+	Line2      string `json:"line2,omitempty" validate:"max=200"`
+	City       string `json:"city,omitempty" validate:"nonzero,max=100" required:"true"`
+	PostalCode string `json:"postal_code,omitempty" validate:"max=20"`
+	Region     string `json:"region,omitempty" validate:"max=100"`
+	Country    string `json:"country,omitempty" validate:"nonzero" required:"true"`
+}
+
+func (r AddressRequest) ToStore() *Address {
+	return &Address{
+		UserID:     r.UserID,
+		Name:       r.Name,
+		Line1:      r.Line1,
+		Line2:      r.Line2,
+		City:       r.City,
+		PostalCode: r.PostalCode,
+		Region:     r.Region,
+		Country:    strings.ToUpper(r.Country),
+	}
+}
+
+func (r PostRequest) ToStore() *User {
+	return &User{
+		Name:  r.Name,
+		Email: r.Email,
+	}
+}
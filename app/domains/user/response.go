@@ -0,0 +1,60 @@
+package user
+
+import "time"
+
+type CreateUserResponse struct {
+	ID uint `json:"id"`
+}
+
+type UserResponse struct {
+	ID        uint      `json:"id,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (u *User) ToResponse() UserResponse {
+	return UserResponse{
+		ID:        u.ID,
+		Name:      u.Name,
+		Email:     u.Email,
+		CreatedAt: u.CreatedAt,
+	}
+}
+
+type ListUsersResponse struct {
+	Items  []UserResponse `json:"items"`
+	Total  int64          `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+type AddressResponse struct {
+	ID         uint   `json:"id"`
+	UserID     uint   `json:"user_id"`
+	Name       string `json:"name"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Region     string `json:"region,omitempty"`
+	Country    string `json:"country"`
+}
+
+func (a *Address) ToResponse() AddressResponse {
+	return AddressResponse{
+		ID:         a.ID,
+		UserID:     a.UserID,
+		Name:       a.Name,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		PostalCode: a.PostalCode,
+		Region:     a.Region,
+		Country:    a.Country,
+	}
+}
+
+type ListAddressesResponse struct {
+	Items []AddressResponse `json:"items"`
+}
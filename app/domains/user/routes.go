@@ -0,0 +1,18 @@
+package user
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetRoutes(router fiber.Router, handler IHandler) {
+	g := router.Group("user")
+	g.Get("/", handler.List)
+	g.Post("/", handler.Post)
+	g.Get("/:id", handler.Get)
+	g.Put("/:id", handler.Put)
+	g.Delete("/:id", handler.Delete)
+	g.Get("/:id/addresses", handler.ListAddresses)
+	g.Post("/:id/addresses", handler.PostAddress)
+	g.Get("/:id/addresses/:addressID", handler.GetAddress)
+	g.Delete("/:id/addresses/:addressID", handler.DeleteAddress)
+}
@@ -0,0 +1,190 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"github.com/p4xx07/order-service/configuration"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+type IService interface {
+	List(ctx context.Context, request ListRequest) (*ListUsersResponse, error)
+	Get(ctx context.Context, id uint) (*UserResponse, error)
+	Create(ctx context.Context, request PostRequest) (*CreateUserResponse, error)
+	Update(ctx context.Context, request PutRequest) (*UserResponse, error)
+	Delete(ctx context.Context, id uint) error
+	CreateAddress(ctx context.Context, request AddressRequest) (*AddressResponse, error)
+	GetAddress(ctx context.Context, userID uint, addressID uint) (*AddressResponse, error)
+	ListAddresses(ctx context.Context, userID uint) (*ListAddressesResponse, error)
+	DeleteAddress(ctx context.Context, userID uint, addressID uint) error
+}
+
+type service struct {
+	configuration *configuration.Configuration
+	logger        *zap.SugaredLogger
+	store         IStore
+}
+
+func NewService(store IStore, configuration *configuration.Configuration, logger *zap.SugaredLogger) IService {
+	return &service{store: store, configuration: configuration, logger: logger}
+}
+
+func (s *service) List(ctx context.Context, request ListRequest) (*ListUsersResponse, error) {
+	if request.Limit <= 0 {
+		request.Limit = defaultListLimit
+	}
+	if request.Limit > maxListLimit {
+		request.Limit = maxListLimit
+	}
+	if request.Offset < 0 {
+		request.Offset = 0
+	}
+
+	users, total, err := s.store.List(ctx, request)
+	if err != nil {
+		s.logger.Errorw("error listing users", "error", err)
+		return nil, err
+	}
+
+	items := make([]UserResponse, len(users))
+	for i := range users {
+		items[i] = users[i].ToResponse()
+	}
+
+	return &ListUsersResponse{
+		Items:  items,
+		Total:  total,
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}, nil
+}
+
+func (s *service) Get(ctx context.Context, id uint) (*UserResponse, error) {
+	user, err := s.store.Get(ctx, id)
+	if err != nil {
+		s.logger.Errorw("error getting user", "error", err, "id", id)
+		return nil, err
+	}
+
+	response := user.ToResponse()
+	return &response, nil
+}
+
+func (s *service) Create(ctx context.Context, request PostRequest) (*CreateUserResponse, error) {
+	user := request.ToStore()
+	if err := s.store.Create(ctx, user); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrEmailTaken
+		}
+		s.logger.Errorw("failed to store user", "error", err)
+		return nil, err
+	}
+
+	return &CreateUserResponse{ID: user.ID}, nil
+}
+
+func (s *service) Update(ctx context.Context, request PutRequest) (*UserResponse, error) {
+	user, err := s.store.Get(ctx, request.ID)
+	if err != nil {
+		s.logger.Errorw("error getting user", "error", err, "id", request.ID)
+		return nil, err
+	}
+
+	user.Name = request.Name
+	user.Email = request.Email
+
+	if err := s.store.Update(ctx, user); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrEmailTaken
+		}
+		s.logger.Errorw("error updating user", "error", err, "id", request.ID)
+		return nil, err
+	}
+
+	response := user.ToResponse()
+	return &response, nil
+}
+
+func (s *service) Delete(ctx context.Context, id uint) error {
+	if err := s.store.Delete(ctx, id); err != nil {
+		s.logger.Errorw("error deleting user", "error", err, "id", id)
+		return err
+	}
+	return nil
+}
+
+func (s *service) CreateAddress(ctx context.Context, request AddressRequest) (*AddressResponse, error) {
+	address := request.ToStore()
+	if !isCountryCode(address.Country) {
+		return nil, ErrInvalidCountry
+	}
+
+	if _, err := s.store.Get(ctx, request.UserID); err != nil {
+		s.logger.Errorw("error getting user", "error", err, "id", request.UserID)
+		return nil, err
+	}
+
+	if err := s.store.CreateAddress(ctx, address); err != nil {
+		s.logger.Errorw("failed to store address", "error", err, "userID", request.UserID)
+		return nil, err
+	}
+
+	response := address.ToResponse()
+	return &response, nil
+}
+
+func (s *service) GetAddress(ctx context.Context, userID uint, addressID uint) (*AddressResponse, error) {
+	address, err := s.store.GetAddress(ctx, userID, addressID)
+	if err != nil {
+		s.logger.Errorw("error getting address", "error", err, "userID", userID, "id", addressID)
+		return nil, err
+	}
+
+	response := address.ToResponse()
+	return &response, nil
+}
+
+func (s *service) ListAddresses(ctx context.Context, userID uint) (*ListAddressesResponse, error) {
+	if _, err := s.store.Get(ctx, userID); err != nil {
+		s.logger.Errorw("error getting user", "error", err, "id", userID)
+		return nil, err
+	}
+
+	addresses, err := s.store.ListAddresses(ctx, userID)
+	if err != nil {
+		s.logger.Errorw("error listing addresses", "error", err, "userID", userID)
+		return nil, err
+	}
+
+	items := make([]AddressResponse, len(addresses))
+	for i := range addresses {
+		items[i] = addresses[i].ToResponse()
+	}
+	return &ListAddressesResponse{Items: items}, nil
+}
+
+func (s *service) DeleteAddress(ctx context.Context, userID uint, addressID uint) error {
+	if err := s.store.DeleteAddress(ctx, userID, addressID); err != nil {
+		s.logger.Errorw("error deleting address", "error", err, "userID", userID, "id", addressID)
+		return err
+	}
+	return nil
+}
+
+func isCountryCode(country string) bool {
+	if len(country) != 2 {
+		return false
+	}
+	for _, r := range country {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+package user
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+type IStore interface {
+	Create(ctx context.Context, user *User) error
+	Get(ctx context.Context, id uint) (*User, error)
+	List(ctx context.Context, request ListRequest) ([]User, int64, error)
+	Update(ctx context.Context, user *User) error
+	Delete(ctx context.Context, id uint) error
+	CreateAddress(ctx context.Context, address *Address) error
+	GetAddress(ctx context.Context, userID uint, addressID uint) (*Address, error)
+	ListAddresses(ctx context.Context, userID uint) ([]Address, error)
+	DeleteAddress(ctx context.Context, userID uint, addressID uint) error
+}
+
+type store struct {
+	db *gorm.DB
+}
+
+func NewStore(db *gorm.DB) IStore {
+	return &store{db: db}
+}
+
+func (s *store) Create(ctx context.Context, user *User) error {
+	return s.db.WithContext(ctx).Create(user).Error
+}
+
+func (s *store) Get(ctx context.Context, id uint) (*User, error) {
+	var user User
+	err := s.db.
+		WithContext(ctx).
+		Where("id = ?", id).
+		First(&user).Error
+
+	return &user, err
+}
+
+func (s *store) List(ctx context.Context, request ListRequest) ([]User, int64, error) {
+	query := s.db.WithContext(ctx).Model(&User{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []User
+	err := query.
+		Order("id").
+		Limit(request.Limit).
+		Offset(request.Offset).
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (s *store) Update(ctx context.Context, user *User) error {
+	return s.db.WithContext(ctx).Save(user).Error
+}
+
+func (s *store) Delete(ctx context.Context, id uint) error {
+	result := s.db.WithContext(ctx).Where("id = ?", id).Delete(&User{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *store) CreateAddress(ctx context.Context, address *Address) error {
+	return s.db.WithContext(ctx).Create(address).Error
+}
+
+func (s *store) GetAddress(ctx context.Context, userID uint, addressID uint) (*Address, error) {
+	var address Address
+	err := s.db.
+		WithContext(ctx).
+		Where("id = ? AND user_id = ?", addressID, userID).
+		First(&address).Error
+
+	return &address, err
+}
+
+func (s *store) ListAddresses(ctx context.Context, userID uint) ([]Address, error) {
+	var addresses []Address
+	err := s.db.
+		WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("id").
+		Find(&addresses).Error
+
+	return addresses, err
+}
+
+func (s *store) DeleteAddress(ctx context.Context, userID uint, addressID uint) error {
+	result := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", addressID, userID).Delete(&Address{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/db"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	dispatchBatchSize = 20
+	maxRetryDelay     = time.Hour
+	maxLastErrorSize  = 1000
+
+	// leaseMargin is added to the time a batch takes when every receiver times out.
+	leaseMargin = time.Minute
+)
+
+var errSubscriptionInactive = errors.New("subscription is inactive")
+
+// IDispatcher sends the pending deliveries to their subscriptions.
+// Failed deliveries are retried with exponential backoff until they run out of attempts and are marked dead.
+type IDispatcher interface {
+	Run(ctx context.Context)
+	Dispatch(ctx context.Context) (int, error)
+}
+
+type dispatcher struct {
+	configuration *configuration.Configuration
+	logger        *zap.SugaredLogger
+	store         IStore
+	transactor    db.ITransactor
+	client        *http.Client
+}
+
+func NewDispatcher(store IStore, transactor db.ITransactor, configuration *configuration.Configuration, logger *zap.SugaredLogger) IDispatcher {
+	client := &http.Client{Timeout: configuration.WebhookTimeout}
+	return &dispatcher{store: store, transactor: transactor, client: client, configuration: configuration, logger: logger}
+}
+
+func (d *dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.configuration.WebhookDispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.Dispatch(ctx); err != nil {
+				d.logger.Errorw("error dispatching webhooks", "error", err)
+			}
+		}
+	}
+}
+
+// Dispatch attempts one batch of due deliveries and returns how many were delivered.
+// The deliveries are claimed in a transaction of their own and sent outside of any,
+// so slow receivers hold neither row locks nor a database connection; the outcomes are recorded afterwards.
+func (d *dispatcher) Dispatch(ctx context.Context) (int, error) {
+	now := time.Now()
+	leaseUntil := now.Add(dispatchBatchSize*d.configuration.WebhookTimeout + leaseMargin)
+	deliveries, err := d.store.ClaimDue(ctx, now, dispatchBatchSize, leaseUntil)
+	if err != nil || len(deliveries) == 0 {
+		return 0, err
+	}
+
+	ids := make([]uint, len(deliveries))
+	for i := range deliveries {
+		ids[i] = deliveries[i].SubscriptionID
+	}
+	subscriptions, err := d.store.GetSubscriptions(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok || !subscription.Active {
+			// a paused subscription gets nothing; the delivery can be sent again once it is resumed
+			delivery.Status = StatusDead
+			delivery.LastError = errSubscriptionInactive.Error()
+		} else if err := d.send(ctx, &subscription, delivery); err != nil {
+			d.fail(delivery, err)
+		} else {
+			deliveredAt := time.Now()
+			delivery.Status = StatusDelivered
+			delivery.Attempts++
+			delivery.DeliveredAt = &deliveredAt
+			delivery.LastError = ""
+			delivered++
+		}
+	}
+
+	err = d.transactor.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		store := d.store.WithTx(tx)
+		for i := range deliveries {
+			if err := store.UpdateDelivery(ctx, &deliveries[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return delivered, err
+}
+
+// send posts the payload signed with the subscription secret. Any 2xx answer counts as delivered.
+func (d *dispatcher) send(ctx context.Context, subscription *Subscription, delivery *Delivery) error {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderEvent, string(delivery.EventType))
+	request.Header.Set(HeaderID, delivery.EventID)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, body))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		delivery.ResponseStatus = 0
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	delivery.ResponseStatus = response.StatusCode
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("receiver answered %d", response.StatusCode)
+	}
+	return nil
+}
+
+func (d *dispatcher) fail(delivery *Delivery, err error) {
+	delivery.Attempts++
+	delivery.LastError = err.Error()
+	if len(delivery.LastError) > maxLastErrorSize {
+		delivery.LastError = delivery.LastError[:maxLastErrorSize]
+	}
+
+	if delivery.Attempts >= d.configuration.WebhookMaxAttempts {
+		d.logger.Errorw("webhook delivery is dead", "error", err, "id", delivery.ID, "subscriptionID", delivery.SubscriptionID, "attempts", delivery.Attempts)
+		delivery.Status = StatusDead
+		return
+	}
+
+	d.logger.Warnw("error delivering webhook", "error", err, "id", delivery.ID, "subscriptionID", delivery.SubscriptionID, "attempts", delivery.Attempts)
+	delivery.NextAttemptAt = time.Now().Add(d.backoff(delivery.Attempts))
+}
+
+func (d *dispatcher) backoff(attempts int) time.Duration {
+	delay := d.configuration.WebhookRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
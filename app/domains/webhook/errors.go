@@ -0,0 +1,8 @@
+package webhook
+
+import "errors"
+
+var (
+	ErrInvalidSubscription   = errors.New("invalid webhook subscription")
+	ErrInvalidDeliveryStatus = errors.New("invalid delivery status")
+)
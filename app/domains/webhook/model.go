@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"github.com/p4xx07/order-service/app/domains/inventory"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/internal/event"
+	"slices"
+	"strings"
+	"time"
+)
+
+// EventTypes are the events a subscription can receive.
+var EventTypes = []event.Name{
+	order.EventOrderCreated,
+	order.EventOrderUpdated,
+	order.EventOrderDeleted,
+	order.EventOrderStatusChanged,
+	inventory.EventStockLow,
+}
+
+// Events is a list of event types, stored comma separated.
+type Events []event.Name
+
+func (e Events) Value() (driver.Value, error) {
+	names := make([]string, len(e))
+	for i, name := range e {
+		names[i] = string(name)
+	}
+	return strings.Join(names, ","), nil
+}
+
+func (e *Events) Scan(value interface{}) error {
+	var names string
+	switch v := value.(type) {
+	case []byte:
+		names = string(v)
+	case string:
+		names = v
+	case nil:
+	default:
+		return fmt.Errorf("cannot scan %T into webhook events", value)
+	}
+
+	*e = nil
+	for _, name := range strings.Split(names, ",") {
+		if name != "" {
+			*e = append(*e, event.Name(name))
+		}
+	}
+	return nil
+}
+
+type Subscription struct {
+	ID         uint   `gorm:"primaryKey;autoIncrement"`
+	URL        string `gorm:"type:varchar(2048);not null"`
+	EventTypes Events `gorm:"type:varchar(255);not null"`
+	// Secret is the key the deliveries to the subscription are signed with.
+	Secret    string    `gorm:"type:varchar(100);not null"`
+	Active    bool      `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (Subscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+func (s *Subscription) subscribes(name event.Name) bool {
+	return s.Active && slices.Contains(s.EventTypes, name)
+}
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusDelivered Status = "delivered"
+	StatusDead      Status = "dead"
+)
+
+func (s Status) IsValid() bool {
+	return s == StatusPending || s == StatusDelivered || s == StatusDead
+}
+
+// Delivery is one event sent to one subscription, along with how the attempts to send it went.
+// EventID is unique per subscription, so an event published again is not delivered twice.
+type Delivery struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement"`
+	SubscriptionID uint       `gorm:"not null;uniqueIndex:idx_webhook_deliveries_subscription_event"`
+	EventID        string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_webhook_deliveries_subscription_event"`
+	EventType      event.Name `gorm:"type:varchar(50);not null"`
+	Payload        string     `gorm:"type:text;not null"`
+	Status         Status     `gorm:"type:varchar(20);not null;index:idx_webhook_deliveries_status_next_attempt_at"`
+	Attempts       int        `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time  `gorm:"not null;index:idx_webhook_deliveries_status_next_attempt_at"`
+	// ResponseStatus is the HTTP status the receiver answered the last attempt with, 0 when it could not be reached.
+	ResponseStatus int    `gorm:"not null;default:0"`
+	LastError      string `gorm:"type:varchar(1000)"`
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/p4xx07/order-service/internal/event"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderID        = "X-Webhook-Id"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Payload is the body of a delivery. ID identifies the event, receivers can use it to drop events they already handled.
+type Payload struct {
+	ID        string      `json:"id"`
+	Type      event.Name  `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// sentEvent is an event as sent to other services.
+type sentEvent interface {
+	event.Event
+	EventID() string
+	Data() interface{}
+}
+
+// newPayload builds the payload of an event, or reports false for events that are not sent as webhooks.
+func newPayload(e event.Event, now time.Time) (Payload, bool) {
+	sent, ok := e.(sentEvent)
+	if !ok {
+		return Payload{}, false
+	}
+
+	return Payload{
+		ID:        sent.EventID(),
+		Type:      sent.EventName(),
+		CreatedAt: now,
+		Data:      sent.Data(),
+	}, true
+}
+
+// Sign is the hex encoded HMAC-SHA256 of the timestamp and the body joined by a dot, keyed with the subscription secret.
+// Receivers recompute it to check a delivery came from us, and reject old timestamps to stop replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"fmt"
+	"github.com/p4xx07/order-service/internal/event"
+	"net/url"
+	"slices"
+)
+
+const minSecretLength = 16
+
+type ListRequest struct {
+	Limit  int `json:"limit,omitempty"`
+	Offset int `json:"offset,omitempty"`
+}
+
+type PostRequest struct {
+	URL        string       `json:"url,omitempty" validate:"nonzero,max=2048" required:"true"`
+	EventTypes []event.Name `json:"event_types,omitempty" validate:"nonzero" required:"true"`
+	// Secret defaults to a random one, returned once in the response.
+	Secret string `json:"secret,omitempty" validate:"max=100"`
+}
+
+func (r PostRequest) Validate() error {
+	if r.Secret != "" && len(r.Secret) < minSecretLength {
+		return fmt.Errorf("%w: secret must be at least %d characters", ErrInvalidSubscription, minSecretLength)
+	}
+	return validateSubscription(r.URL, r.EventTypes)
+}
+
+type PutRequest struct {
+	ID         uint         `json:"id,omitempty" validate:"min=1,nonnil" required:"true"`
+	URL        string       `json:"url,omitempty" validate:"nonzero,max=2048" required:"true"`
+	EventTypes []event.Name `json:"event_types,omitempty" validate:"nonzero" required:"true"`
+	// Active pauses or resumes the subscription, it is left as is when omitted.
+	Active *bool `json:"active,omitempty"`
+}
+
+func (r PutRequest) Validate() error {
+	return validateSubscription(r.URL, r.EventTypes)
+}
+
+func validateSubscription(rawURL string, eventTypes []event.Name) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https url", ErrInvalidSubscription)
+	}
+
+	for _, eventType := range eventTypes {
+		if !slices.Contains(EventTypes, eventType) {
+			return fmt.Errorf("%w: unknown event type %s", ErrInvalidSubscription, eventType)
+		}
+	}
+	return nil
+}
+
+type ListDeliveriesRequest struct {
+	SubscriptionID uint   `json:"subscription_id,omitempty"`
+	Status         Status `json:"status,omitempty"`
+	Limit          int    `json:"limit,omitempty"`
+	Offset         int    `json:"offset,omitempty"`
+}
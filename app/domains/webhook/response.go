@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"encoding/json"
+	"github.com/p4xx07/order-service/internal/event"
+	"time"
+)
+
+type SubscriptionResponse struct {
+	ID         uint         `json:"id"`
+	URL        string       `json:"url"`
+	EventTypes []event.Name `json:"event_types"`
+	Active     bool         `json:"active"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+func (s *Subscription) ToResponse() SubscriptionResponse {
+	return SubscriptionResponse{
+		ID:         s.ID,
+		URL:        s.URL,
+		EventTypes: s.EventTypes,
+		Active:     s.Active,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
+
+// CreateSubscriptionResponse is the only response carrying the secret.
+type CreateSubscriptionResponse struct {
+	SubscriptionResponse
+	Secret string `json:"secret"`
+}
+
+type ListSubscriptionsResponse struct {
+	Items  []SubscriptionResponse `json:"items"`
+	Total  int64                  `json:"total"`
+	Limit  int                    `json:"limit"`
+	Offset int                    `json:"offset"`
+}
+
+type DeliveryResponse struct {
+	ID             uint            `json:"id"`
+	SubscriptionID uint            `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      event.Name      `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         Status          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func (d *Delivery) ToResponse() DeliveryResponse {
+	return DeliveryResponse{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        json.RawMessage(d.Payload),
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+}
+
+type ListDeliveriesResponse struct {
+	Items  []DeliveryResponse `json:"items"`
+	Total  int64              `json:"total"`
+	Limit  int                `json:"limit"`
+	Offset int                `json:"offset"`
+}
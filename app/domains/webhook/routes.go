@@ -0,0 +1,16 @@
+package webhook
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetAdminRoutes(router fiber.Router, handler IHandler) {
+	g := router.Group("webhook")
+	g.Get("/", handler.List)
+	g.Post("/", handler.Post)
+	g.Get("/:id", handler.Get)
+	g.Put("/:id", handler.Put)
+	g.Delete("/:id", handler.Delete)
+	g.Get("/:id/deliveries", handler.ListDeliveries)
+	g.Post("/delivery/:id/redelivery", handler.Redeliver)
+}
@@ -0,0 +1,198 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/p4xx07/order-service/configuration"
+	"go.uber.org/zap"
+	"slices"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+	secretPrefix     = "whsec_"
+)
+
+// IService manages the webhook subscriptions and their delivery log.
+type IService interface {
+	Create(ctx context.Context, request PostRequest) (*CreateSubscriptionResponse, error)
+	Get(ctx context.Context, id uint) (*SubscriptionResponse, error)
+	List(ctx context.Context, request ListRequest) (*ListSubscriptionsResponse, error)
+	Update(ctx context.Context, request PutRequest) (*SubscriptionResponse, error)
+	Delete(ctx context.Context, id uint) error
+	ListDeliveries(ctx context.Context, request ListDeliveriesRequest) (*ListDeliveriesResponse, error)
+	Redeliver(ctx context.Context, id uint) (*DeliveryResponse, error)
+}
+
+type service struct {
+	configuration *configuration.Configuration
+	logger        *zap.SugaredLogger
+	store         IStore
+}
+
+func NewService(store IStore, configuration *configuration.Configuration, logger *zap.SugaredLogger) IService {
+	return &service{store: store, configuration: configuration, logger: logger}
+}
+
+func (s *service) Create(ctx context.Context, request PostRequest) (*CreateSubscriptionResponse, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	secret := request.Secret
+	if secret == "" {
+		generated, err := newSecret()
+		if err != nil {
+			return nil, err
+		}
+		secret = generated
+	}
+
+	subscription := &Subscription{
+		URL:        request.URL,
+		EventTypes: compact(request.EventTypes),
+		Secret:     secret,
+		Active:     true,
+	}
+	if err := s.store.CreateSubscription(ctx, subscription); err != nil {
+		s.logger.Errorw("failed to store webhook subscription", "error", err, "url", request.URL)
+		return nil, err
+	}
+
+	return &CreateSubscriptionResponse{SubscriptionResponse: subscription.ToResponse(), Secret: secret}, nil
+}
+
+func (s *service) Get(ctx context.Context, id uint) (*SubscriptionResponse, error) {
+	subscription, err := s.store.GetSubscription(ctx, id)
+	if err != nil {
+		s.logger.Errorw("error getting webhook subscription", "error", err, "id", id)
+		return nil, err
+	}
+
+	response := subscription.ToResponse()
+	return &response, nil
+}
+
+func (s *service) List(ctx context.Context, request ListRequest) (*ListSubscriptionsResponse, error) {
+	request.Limit, request.Offset = page(request.Limit, request.Offset)
+
+	subscriptions, total, err := s.store.ListSubscriptions(ctx, request)
+	if err != nil {
+		s.logger.Errorw("error listing webhook subscriptions", "error", err)
+		return nil, err
+	}
+
+	items := make([]SubscriptionResponse, len(subscriptions))
+	for i := range subscriptions {
+		items[i] = subscriptions[i].ToResponse()
+	}
+
+	return &ListSubscriptionsResponse{
+		Items:  items,
+		Total:  total,
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}, nil
+}
+
+func (s *service) Update(ctx context.Context, request PutRequest) (*SubscriptionResponse, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	subscription, err := s.store.GetSubscription(ctx, request.ID)
+	if err != nil {
+		s.logger.Errorw("error getting webhook subscription", "error", err, "id", request.ID)
+		return nil, err
+	}
+
+	subscription.URL = request.URL
+	subscription.EventTypes = compact(request.EventTypes)
+	if request.Active != nil {
+		subscription.Active = *request.Active
+	}
+
+	if err := s.store.UpdateSubscription(ctx, subscription); err != nil {
+		s.logger.Errorw("error updating webhook subscription", "error", err, "id", request.ID)
+		return nil, err
+	}
+
+	response := subscription.ToResponse()
+	return &response, nil
+}
+
+func (s *service) Delete(ctx context.Context, id uint) error {
+	if err := s.store.DeleteSubscription(ctx, id); err != nil {
+		s.logger.Errorw("error deleting webhook subscription", "error", err, "id", id)
+		return err
+	}
+	return nil
+}
+
+func (s *service) ListDeliveries(ctx context.Context, request ListDeliveriesRequest) (*ListDeliveriesResponse, error) {
+	if request.Status != "" && !request.Status.IsValid() {
+		return nil, ErrInvalidDeliveryStatus
+	}
+	request.Limit, request.Offset = page(request.Limit, request.Offset)
+
+	if _, err := s.store.GetSubscription(ctx, request.SubscriptionID); err != nil {
+		s.logger.Errorw("error getting webhook subscription", "error", err, "id", request.SubscriptionID)
+		return nil, err
+	}
+
+	deliveries, total, err := s.store.ListDeliveries(ctx, request)
+	if err != nil {
+		s.logger.Errorw("error listing webhook deliveries", "error", err, "subscriptionID", request.SubscriptionID)
+		return nil, err
+	}
+
+	items := make([]DeliveryResponse, len(deliveries))
+	for i := range deliveries {
+		items[i] = deliveries[i].ToResponse()
+	}
+
+	return &ListDeliveriesResponse{
+		Items:  items,
+		Total:  total,
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}, nil
+}
+
+// Redeliver sends a delivery again, e.g. once the receiver that made it go dead is fixed.
+func (s *service) Redeliver(ctx context.Context, id uint) (*DeliveryResponse, error) {
+	delivery, err := s.store.Redeliver(ctx, id)
+	if err != nil {
+		s.logger.Errorw("error redelivering webhook", "error", err, "id", id)
+		return nil, err
+	}
+
+	response := delivery.ToResponse()
+	return &response, nil
+}
+
+func page(limit int, offset int) (int, int) {
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	return limit, max(offset, 0)
+}
+
+func compact(eventTypes Events) Events {
+	eventTypes = slices.Clone(eventTypes)
+	slices.Sort(eventTypes)
+	return slices.Compact(eventTypes)
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,198 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type IStore interface {
+	CreateSubscription(ctx context.Context, subscription *Subscription) error
+	GetSubscription(ctx context.Context, id uint) (*Subscription, error)
+	GetSubscriptions(ctx context.Context, ids []uint) (map[uint]Subscription, error)
+	ListSubscriptions(ctx context.Context, request ListRequest) ([]Subscription, int64, error)
+	ListActiveSubscriptions(ctx context.Context) ([]Subscription, error)
+	UpdateSubscription(ctx context.Context, subscription *Subscription) error
+	DeleteSubscription(ctx context.Context, id uint) error
+	CreateDeliveries(ctx context.Context, deliveries []Delivery) error
+	ClaimDue(ctx context.Context, now time.Time, limit int, leaseUntil time.Time) ([]Delivery, error)
+	ListDeliveries(ctx context.Context, request ListDeliveriesRequest) ([]Delivery, int64, error)
+	UpdateDelivery(ctx context.Context, delivery *Delivery) error
+	Redeliver(ctx context.Context, id uint) (*Delivery, error)
+	WithTx(tx *gorm.DB) IStore
+}
+
+type store struct {
+	db *gorm.DB
+}
+
+func NewStore(db *gorm.DB) IStore {
+	return &store{db: db}
+}
+
+func (s *store) WithTx(tx *gorm.DB) IStore {
+	return &store{db: tx}
+}
+
+func (s *store) CreateSubscription(ctx context.Context, subscription *Subscription) error {
+	return s.db.WithContext(ctx).Create(subscription).Error
+}
+
+func (s *store) GetSubscription(ctx context.Context, id uint) (*Subscription, error) {
+	var subscription Subscription
+	err := s.db.WithContext(ctx).Where("id = ?", id).First(&subscription).Error
+	return &subscription, err
+}
+
+func (s *store) GetSubscriptions(ctx context.Context, ids []uint) (map[uint]Subscription, error) {
+	var subscriptions []Subscription
+	if err := s.db.WithContext(ctx).Where("id IN (?)", ids).Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]Subscription, len(subscriptions))
+	for _, subscription := range subscriptions {
+		byID[subscription.ID] = subscription
+	}
+	return byID, nil
+}
+
+func (s *store) ListSubscriptions(ctx context.Context, request ListRequest) ([]Subscription, int64, error) {
+	query := s.db.WithContext(ctx).Model(&Subscription{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook subscriptions: %w", err)
+	}
+
+	var subscriptions []Subscription
+	err := query.
+		Order("id").
+		Limit(request.Limit).
+		Offset(request.Offset).
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	return subscriptions, total, nil
+}
+
+func (s *store) ListActiveSubscriptions(ctx context.Context) ([]Subscription, error) {
+	var subscriptions []Subscription
+	err := s.db.WithContext(ctx).Where("active = ?", true).Order("id").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (s *store) UpdateSubscription(ctx context.Context, subscription *Subscription) error {
+	return s.db.
+		WithContext(ctx).
+		Model(subscription).
+		Select("url", "event_types", "active").
+		Updates(subscription).Error
+}
+
+// DeleteSubscription deletes the subscription along with its delivery log.
+func (s *store) DeleteSubscription(ctx context.Context, id uint) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", id).Delete(&Delivery{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&Subscription{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// CreateDeliveries skips the deliveries of events the subscriptions already got.
+func (s *store) CreateDeliveries(ctx context.Context, deliveries []Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
+
+// ClaimDue leases the pending deliveries that are ready to be sent until leaseUntil, and commits before returning,
+// so no rows stay locked while the requests are sent. Rows locked by another dispatcher are skipped, so several instances
+// can dispatch side by side; the deliveries of a dispatcher that died before recording the outcome are due again once the lease ran out.
+func (s *store) ClaimDue(ctx context.Context, now time.Time, limit int, leaseUntil time.Time) ([]Delivery, error) {
+	var deliveries []Delivery
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", StatusPending, now).
+			Order("id").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+		}
+		return tx.Model(&Delivery{}).
+			Where("id IN (?)", ids).
+			Update("next_attempt_at", leaseUntil).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (s *store) ListDeliveries(ctx context.Context, request ListDeliveriesRequest) ([]Delivery, int64, error) {
+	query := s.db.WithContext(ctx).Model(&Delivery{}).Where("subscription_id = ?", request.SubscriptionID)
+	if request.Status != "" {
+		query = query.Where("status = ?", request.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	var deliveries []Delivery
+	err := query.
+		Order("id DESC").
+		Limit(request.Limit).
+		Offset(request.Offset).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, total, nil
+}
+
+func (s *store) UpdateDelivery(ctx context.Context, delivery *Delivery) error {
+	return s.db.WithContext(ctx).Save(delivery).Error
+}
+
+// Redeliver queues a delivery to be sent again right away with a fresh set of attempts, whatever became of it before.
+func (s *store) Redeliver(ctx context.Context, id uint) (*Delivery, error) {
+	result := s.db.WithContext(ctx).
+		Model(&Delivery{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          StatusPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var delivery Delivery
+	err := s.db.WithContext(ctx).Where("id = ?", id).First(&delivery).Error
+	return &delivery, err
+}
@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/event"
+	"go.uber.org/zap"
+	"time"
+)
+
+// SubscriberWebhooks is the webhook subscriber as subscriber of the order and low-stock events.
+const SubscriberWebhooks event.Subscriber = "webhooks"
+
+// ISubscriber turns the order and low-stock events into deliveries for the subscriptions that want them.
+// It subscribes synchronously, so the deliveries are recorded before the outbox event counts as delivered;
+// an event published again finds its deliveries already there and adds none.
+type ISubscriber interface {
+	Subscribe(bus event.IBus)
+}
+
+type subscriber struct {
+	configuration *configuration.Configuration
+	logger        *zap.SugaredLogger
+	store         IStore
+}
+
+func NewSubscriber(store IStore, configuration *configuration.Configuration, logger *zap.SugaredLogger) ISubscriber {
+	return &subscriber{store: store, configuration: configuration, logger: logger}
+}
+
+func (s *subscriber) Subscribe(bus event.IBus) {
+	for _, eventType := range EventTypes {
+		bus.Subscribe(SubscriberWebhooks, eventType, s.handle)
+	}
+}
+
+func (s *subscriber) handle(ctx context.Context, e event.Event) error {
+	now := time.Now()
+	payload, ok := newPayload(e, now)
+	if !ok {
+		return nil
+	}
+
+	subscriptions, err := s.store.ListActiveSubscriptions(ctx)
+	if err != nil {
+		s.logger.Errorw("error listing webhook subscriptions", "error", err)
+		return err
+	}
+
+	// the payload is rendered once, so every attempt sends the same body
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []Delivery
+	for i := range subscriptions {
+		if !subscriptions[i].subscribes(payload.Type) {
+			continue
+		}
+		deliveries = append(deliveries, Delivery{
+			SubscriptionID: subscriptions[i].ID,
+			EventID:        payload.ID,
+			EventType:      payload.Type,
+			Payload:        string(body),
+			Status:         StatusPending,
+			NextAttemptAt:  now,
+		})
+	}
+
+	if err := s.store.CreateDeliveries(ctx, deliveries); err != nil {
+		s.logger.Errorw("error creating webhook deliveries", "error", err, "event", payload.ID)
+		return err
+	}
+	return nil
+}
@@ -5,9 +5,11 @@ import (
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
 	"os"
+	"time"
 )
 
 type Configuration struct {
+	Environment      string `env:"ENVIRONMENT"`
 	LogLevel         string `env:"LOG_LEVEL"`
 	DatabaseUsername string `env:"DATABASE_USERNAME"`
 	DatabasePassword string `env:"DATABASE_PASSWORD"`
@@ -22,6 +24,38 @@ type Configuration struct {
 	MeiliSearchHost      string `env:"MEILISEARCH_HOST"`
 	MeiliSearchPort      int    `env:"MEILISEARCH_PORT"`
 	MeiliSearchMasterKey string `env:"MEILISEARCH_MASTER_KEY"`
+
+	SearchFallbackCooldown time.Duration `env:"SEARCH_FALLBACK_COOLDOWN"`
+
+	BaseCurrency string `env:"BASE_CURRENCY"`
+
+	LockTTL         time.Duration `env:"LOCK_TTL"`
+	LockWaitTimeout time.Duration `env:"LOCK_WAIT_TIMEOUT"`
+	LockRetryDelay  time.Duration `env:"LOCK_RETRY_DELAY"`
+
+	IdempotencyTTL        time.Duration `env:"IDEMPOTENCY_TTL"`
+	IdempotencyPendingTTL time.Duration `env:"IDEMPOTENCY_PENDING_TTL"`
+
+	OutboxRelayInterval time.Duration `env:"OUTBOX_RELAY_INTERVAL"`
+	OutboxRetryDelay    time.Duration `env:"OUTBOX_RETRY_DELAY"`
+	OutboxMaxAttempts   int           `env:"OUTBOX_MAX_ATTEMPTS"`
+
+	ReservationTTL           time.Duration `env:"RESERVATION_TTL"`
+	ReservationSweepInterval time.Duration `env:"RESERVATION_SWEEP_INTERVAL"`
+
+	PaymentProvider      string `env:"PAYMENT_PROVIDER"`
+	PaymentWebhookSecret string `env:"PAYMENT_WEBHOOK_SECRET"`
+
+	EventWorkers   int `env:"EVENT_WORKERS"`
+	EventQueueSize int `env:"EVENT_QUEUE_SIZE"`
+
+	EventStream       string `env:"EVENT_STREAM"`
+	EventStreamMaxLen int64  `env:"EVENT_STREAM_MAX_LEN"`
+
+	WebhookDispatchInterval time.Duration `env:"WEBHOOK_DISPATCH_INTERVAL"`
+	WebhookRetryDelay       time.Duration `env:"WEBHOOK_RETRY_DELAY"`
+	WebhookMaxAttempts      int           `env:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookTimeout          time.Duration `env:"WEBHOOK_TIMEOUT"`
 }
 
 func GetEnvConfig() (*Configuration, error) {
@@ -34,7 +68,27 @@ func GetEnvConfig() (*Configuration, error) {
 	}
 
 	cfg := Configuration{
-		LogLevel: "info",
+		LogLevel:                 "info",
+		BaseCurrency:             "EUR",
+		LockTTL:                  5 * time.Second,
+		LockWaitTimeout:          2 * time.Second,
+		LockRetryDelay:           50 * time.Millisecond,
+		IdempotencyTTL:           24 * time.Hour,
+		IdempotencyPendingTTL:    30 * time.Second,
+		SearchFallbackCooldown:   30 * time.Second,
+		OutboxRelayInterval:      time.Second,
+		OutboxRetryDelay:         time.Second,
+		OutboxMaxAttempts:        10,
+		ReservationTTL:           15 * time.Minute,
+		ReservationSweepInterval: time.Minute,
+		EventWorkers:             4,
+		EventQueueSize:           1024,
+		EventStream:              "order-service:events",
+		EventStreamMaxLen:        100000,
+		WebhookDispatchInterval:  time.Second,
+		WebhookRetryDelay:        10 * time.Second,
+		WebhookMaxAttempts:       10,
+		WebhookTimeout:           5 * time.Second,
 	}
 
 	if err := env.Parse(&cfg); err != nil {
@@ -9,12 +9,24 @@ import (
 	"github.com/google/wire"
 	"github.com/meilisearch/meilisearch-go"
 	"github.com/p4xx07/order-service/app"
+	"github.com/p4xx07/order-service/app/domains/currency"
 	"github.com/p4xx07/order-service/app/domains/inventory"
 	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/app/domains/payment"
 	"github.com/p4xx07/order-service/app/domains/product"
+	"github.com/p4xx07/order-service/app/domains/promotion"
+	"github.com/p4xx07/order-service/app/domains/returns"
+	"github.com/p4xx07/order-service/app/domains/shipment"
+	"github.com/p4xx07/order-service/app/domains/tax"
 	"github.com/p4xx07/order-service/app/domains/user"
+	"github.com/p4xx07/order-service/app/domains/webhook"
 	"github.com/p4xx07/order-service/configuration"
 	"github.com/p4xx07/order-service/internal/db"
+	"github.com/p4xx07/order-service/internal/event"
+	"github.com/p4xx07/order-service/internal/idempotency"
+	"github.com/p4xx07/order-service/internal/lock"
+	"github.com/p4xx07/order-service/internal/money"
+	"github.com/p4xx07/order-service/pkg/eventstream"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -25,19 +37,67 @@ func InjectApp(config *configuration.Configuration, logger *zap.SugaredLogger) (
 	wire.Build(
 		InitMeiliSearchClient,
 		InitRedisClient,
+		InitEventStreamPublisher,
+		lock.NewLocker,
+		idempotency.NewStore,
+		idempotency.NewMiddleware,
+		event.NewBus,
 
 		// handlers
 		order.NewHandler,
+		order.NewAdminHandler,
+		product.NewHandler,
+		inventory.NewHandler,
+		user.NewHandler,
+		currency.NewHandler,
+		promotion.NewHandler,
+		tax.NewHandler,
+		shipment.NewHandler,
+		returns.NewHandler,
+		payment.NewHandler,
+		webhook.NewHandler,
 
 		// services
 		order.NewService,
 		order.NewMeilisearchService,
+		order.NewAdminService,
+		order.NewReservationSweeper,
+		order.NewOutboxRelay,
+		order.NewReindexer,
+		order.NewSearchIndexer,
+		order.NewStreamPublisher,
 		inventory.NewService,
+		product.NewService,
+		user.NewService,
+		currency.NewService,
+		promotion.NewService,
+		tax.NewService,
+		tax.NewRuleCalculator,
+		shipment.NewService,
+		returns.NewService,
+		payment.NewService,
+		payment.NewProvider,
+		payment.NewSubscriber,
+		webhook.NewService,
+		webhook.NewSubscriber,
+		webhook.NewDispatcher,
 
 		// stores
 		ConnectDB,
+		db.NewTransactor,
 		order.NewStore,
+		order.NewOutboxStore,
+		order.NewWatermarkStore,
 		inventory.NewStore,
+		product.NewStore,
+		user.NewStore,
+		currency.NewStore,
+		promotion.NewStore,
+		tax.NewStore,
+		shipment.NewStore,
+		returns.NewStore,
+		payment.NewStore,
+		webhook.NewStore,
 
 		wire.Struct(new(app.App), "*"),
 	)
@@ -51,12 +111,35 @@ func ConnectDB(configuration *configuration.Configuration) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	for _, table := range []string{"products", "order_items"} {
+		if err := db.ToMinorUnits(database, table, "price"); err != nil {
+			return nil, err
+		}
+	}
+
 	err = database.AutoMigrate(
 		user.User{},
+		user.Address{},
 		product.Product{},
 		inventory.Inventory{},
+		inventory.InventoryMovement{},
+		inventory.Reservation{},
+		inventory.LowStockAlert{},
 		order.Order{},
 		order.OrderItem{},
+		order.OrderPromotion{},
+		order.OutboxEvent{},
+		order.ReindexWatermark{},
+		currency.ExchangeRate{},
+		promotion.Promotion{},
+		tax.Rate{},
+		shipment.Shipment{},
+		shipment.ShipmentItem{},
+		returns.Return{},
+		returns.ReturnItem{},
+		payment.Payment{},
+		webhook.Subscription{},
+		webhook.Delivery{},
 	)
 
 	if err != nil {
@@ -65,6 +148,14 @@ func ConnectDB(configuration *configuration.Configuration) (*gorm.DB, error) {
 		}
 	}
 
+	if err := order.BackfillTotals(database); err != nil {
+		return nil, err
+	}
+
+	if err := order.BackfillCurrency(database, money.Currency(configuration.BaseCurrency)); err != nil {
+		return nil, err
+	}
+
 	return database, nil
 }
 
@@ -84,17 +175,15 @@ func InitRedisClient(configuration *configuration.Configuration) (*redis.Client,
 	return client, nil
 }
 
+func InitEventStreamPublisher(configuration *configuration.Configuration, client *redis.Client) *eventstream.Publisher {
+	return eventstream.NewPublisher(client, configuration.EventStream, configuration.EventStreamMaxLen)
+}
+
 func InitMeiliSearchClient(configuration *configuration.Configuration) (meilisearch.ServiceManager, error) {
 	host := fmt.Sprintf("%s:%d", configuration.MeiliSearchHost, configuration.MeiliSearchPort)
 	client := meilisearch.New(
 		host,
 		meilisearch.WithAPIKey(configuration.MeiliSearchMasterKey),
 	)
-	index := client.Index("orders")
-	sortable := []string{"created_at"}
-	_, err := index.UpdateSortableAttributes(&sortable)
-	if err != nil {
-		return nil, err
-	}
 	return client, nil
 }
@@ -11,12 +11,24 @@ import (
 	"fmt"
 	"github.com/meilisearch/meilisearch-go"
 	"github.com/p4xx07/order-service/app"
+	"github.com/p4xx07/order-service/app/domains/currency"
 	"github.com/p4xx07/order-service/app/domains/inventory"
 	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/app/domains/payment"
 	"github.com/p4xx07/order-service/app/domains/product"
+	"github.com/p4xx07/order-service/app/domains/promotion"
+	"github.com/p4xx07/order-service/app/domains/returns"
+	"github.com/p4xx07/order-service/app/domains/shipment"
+	"github.com/p4xx07/order-service/app/domains/tax"
 	"github.com/p4xx07/order-service/app/domains/user"
+	"github.com/p4xx07/order-service/app/domains/webhook"
 	"github.com/p4xx07/order-service/configuration"
 	"github.com/p4xx07/order-service/internal/db"
+	"github.com/p4xx07/order-service/internal/event"
+	"github.com/p4xx07/order-service/internal/idempotency"
+	"github.com/p4xx07/order-service/internal/lock"
+	"github.com/p4xx07/order-service/internal/money"
+	"github.com/p4xx07/order-service/pkg/eventstream"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -30,22 +42,94 @@ func InjectApp(config *configuration.Configuration, logger *zap.SugaredLogger) (
 	if err != nil {
 		return nil, err
 	}
-	db, err := ConnectDB(config)
+	iMeilisearchService := order.NewMeilisearchService(serviceManager, config, logger)
+	client, err := InitRedisClient(config)
 	if err != nil {
 		return nil, err
 	}
-	iStore := order.NewStore(db)
-	iMeilisearchService := order.NewMeilisearchService(serviceManager, config, logger, iStore)
-	client, err := InitRedisClient(config)
+	iLocker := lock.NewLocker(client, config, logger)
+	gormDB, err := ConnectDB(config)
 	if err != nil {
 		return nil, err
 	}
-	inventoryIStore := inventory.NewStore(db)
-	iService := inventory.NewService(inventoryIStore, config, logger)
-	orderIService := order.NewService(iMeilisearchService, client, config, logger, iStore, iService)
+	iStore := order.NewStore(gormDB)
+	inventoryIStore := inventory.NewStore(gormDB)
+	iBus := event.NewBus(config, logger)
+	iService := inventory.NewService(inventoryIStore, iBus, config, logger)
+	userIStore := user.NewStore(gormDB)
+	userIService := user.NewService(userIStore, config, logger)
+	iTransactor := db.NewTransactor(gormDB)
+	iOutboxStore := order.NewOutboxStore(gormDB)
+	currencyIStore := currency.NewStore(gormDB)
+	currencyIService := currency.NewService(currencyIStore, config, logger)
+	promotionIStore := promotion.NewStore(gormDB)
+	promotionIService := promotion.NewService(promotionIStore, config, logger)
+	taxIStore := tax.NewStore(gormDB)
+	taxCalculator := tax.NewRuleCalculator(taxIStore, logger)
+	orderIService := order.NewService(iMeilisearchService, iLocker, config, logger, iStore, iService, userIService, iTransactor, iOutboxStore, currencyIService, promotionIService, taxCalculator)
 	iHandler := order.NewHandler(orderIService, logger)
+	productIStore := product.NewStore(gormDB)
+	productIService := product.NewService(productIStore, config, logger)
+	productIHandler := product.NewHandler(productIService, logger)
+	inventoryIHandler := inventory.NewHandler(iService, logger)
+	userIHandler := user.NewHandler(userIService, logger)
+	currencyIHandler := currency.NewHandler(currencyIService, logger)
+	promotionIHandler := promotion.NewHandler(promotionIService, logger)
+	taxIService := tax.NewService(taxIStore, config, logger)
+	taxIHandler := tax.NewHandler(taxIService, logger)
+	shipmentIStore := shipment.NewStore(gormDB)
+	shipmentIService := shipment.NewService(shipmentIStore, orderIService, iTransactor, config, logger)
+	shipmentIHandler := shipment.NewHandler(shipmentIService, logger)
+	returnsIStore := returns.NewStore(gormDB)
+	returnsIService := returns.NewService(returnsIStore, orderIService, iService, iTransactor, config, logger)
+	returnsIHandler := returns.NewHandler(returnsIService, logger)
+	paymentIStore := payment.NewStore(gormDB)
+	paymentProvider, err := payment.NewProvider(config)
+	if err != nil {
+		return nil, err
+	}
+	paymentIService := payment.NewService(paymentIStore, paymentProvider, orderIService, iTransactor, config, logger)
+	paymentIHandler := payment.NewHandler(paymentIService, logger)
+	webhookIStore := webhook.NewStore(gormDB)
+	webhookIService := webhook.NewService(webhookIStore, config, logger)
+	webhookIHandler := webhook.NewHandler(webhookIService, logger)
+	iWatermarkStore := order.NewWatermarkStore(gormDB)
+	iReindexer := order.NewReindexer(iStore, iOutboxStore, iWatermarkStore, iMeilisearchService, iLocker, config, logger)
+	iAdminService := order.NewAdminService(iOutboxStore, iReindexer, config, logger)
+	iAdminHandler := order.NewAdminHandler(iAdminService, logger)
+	idempotencyIStore := idempotency.NewStore(client, config)
+	middleware := idempotency.NewMiddleware(idempotencyIStore, config, logger)
+	iSearchIndexer := order.NewSearchIndexer(iMeilisearchService)
+	iSubscriber := webhook.NewSubscriber(webhookIStore, config, logger)
+	publisher := InitEventStreamPublisher(config, client)
+	iStreamPublisher := order.NewStreamPublisher(publisher, config, logger)
+	paymentISubscriber := payment.NewSubscriber(paymentIStore, paymentProvider, logger)
+	iReservationSweeper := order.NewReservationSweeper(iStore, iService, iTransactor, iOutboxStore, config, logger)
+	iOutboxRelay := order.NewOutboxRelay(iOutboxStore, iStore, iTransactor, iBus, config, logger)
+	iDispatcher := webhook.NewDispatcher(webhookIStore, iTransactor, config, logger)
 	appApp := &app.App{
-		OrderHandler: iHandler,
+		OrderHandler:       iHandler,
+		ProductHandler:     productIHandler,
+		InventoryHandler:   inventoryIHandler,
+		UserHandler:        userIHandler,
+		CurrencyHandler:    currencyIHandler,
+		PromotionHandler:   promotionIHandler,
+		TaxHandler:         taxIHandler,
+		ShipmentHandler:    shipmentIHandler,
+		ReturnHandler:      returnsIHandler,
+		PaymentHandler:     paymentIHandler,
+		WebhookHandler:     webhookIHandler,
+		OrderAdminHandler:  iAdminHandler,
+		Idempotency:        middleware,
+		EventBus:           iBus,
+		SearchIndexer:      iSearchIndexer,
+		WebhookSubscriber:  iSubscriber,
+		StreamPublisher:    iStreamPublisher,
+		PaymentSubscriber:  paymentISubscriber,
+		ReservationSweeper: iReservationSweeper,
+		OutboxRelay:        iOutboxRelay,
+		Reindexer:          iReindexer,
+		WebhookDispatcher:  iDispatcher,
 	}
 	return appApp, nil
 }
@@ -58,7 +142,13 @@ func ConnectDB(configuration2 *configuration.Configuration) (*gorm.DB, error) {
 		return nil, err
 	}
 
-	err = database.AutoMigrate(user.User{}, product.Product{}, inventory.Inventory{}, order.Order{}, order.OrderItem{})
+	for _, table := range []string{"products", "order_items"} {
+		if err := db.ToMinorUnits(database, table, "price"); err != nil {
+			return nil, err
+		}
+	}
+
+	err = database.AutoMigrate(user.User{}, user.Address{}, product.Product{}, inventory.Inventory{}, inventory.InventoryMovement{}, inventory.Reservation{}, inventory.LowStockAlert{}, order.Order{}, order.OrderItem{}, order.OrderPromotion{}, order.OutboxEvent{}, order.ReindexWatermark{}, currency.ExchangeRate{}, promotion.Promotion{}, tax.Rate{}, shipment.Shipment{}, shipment.ShipmentItem{}, returns.Return{}, returns.ReturnItem{}, payment.Payment{}, webhook.Subscription{}, webhook.Delivery{})
 
 	if err != nil {
 		if !strings.Contains(err.Error(), "already exists") {
@@ -66,6 +156,14 @@ func ConnectDB(configuration2 *configuration.Configuration) (*gorm.DB, error) {
 		}
 	}
 
+	if err := order.BackfillTotals(database); err != nil {
+		return nil, err
+	}
+
+	if err := order.BackfillCurrency(database, money.Currency(configuration2.BaseCurrency)); err != nil {
+		return nil, err
+	}
+
 	return database, nil
 }
 
@@ -84,6 +182,10 @@ func InitRedisClient(configuration2 *configuration.Configuration) (*redis.Client
 	return client, nil
 }
 
+func InitEventStreamPublisher(configuration2 *configuration.Configuration, client *redis.Client) *eventstream.Publisher {
+	return eventstream.NewPublisher(client, configuration2.EventStream, configuration2.EventStreamMaxLen)
+}
+
 func InitMeiliSearchClient(configuration2 *configuration.Configuration) (meilisearch.ServiceManager, error) {
 	host := fmt.Sprintf("%s:%d", configuration2.MeiliSearchHost, configuration2.MeiliSearchPort)
 	client := meilisearch.New(
@@ -0,0 +1,78 @@
+// stream-consumer reads the order service event stream through a consumer group and logs every event,
+// as a starting point for services that react to orders and stock changes.
+//
+//	go run ./examples/stream-consumer -group audit -consumer audit-1
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/log"
+	"github.com/p4xx07/order-service/pkg/eventstream"
+	"github.com/redis/go-redis/v9"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// stockChanged is the data of the stock.decreased and stock.increased events.
+type stockChanged struct {
+	Items []struct {
+		ProductID uint `json:"product_id"`
+		Quantity  int  `json:"quantity"`
+	} `json:"items"`
+	Reason string `json:"reason"`
+}
+
+func main() {
+	group := flag.String("group", "example", "consumer group, every group gets every event")
+	consumer := flag.String("consumer", "example-1", "consumer name, unique within the group")
+	flag.Parse()
+
+	c, err := configuration.GetEnvConfig()
+	if err != nil {
+		panic(err)
+	}
+	logger := log.NewLogger(c.LogLevel)
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     c.RedisHost + ":" + c.RedisPort,
+		Password: c.RedisPassword,
+		DB:       c.RedisDatabase,
+	})
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stream := eventstream.NewConsumer(client, eventstream.ConsumerOptions{
+		Stream:        c.EventStream,
+		Group:         *group,
+		Consumer:      *consumer,
+		MaxDeliveries: 5,
+	}, logger)
+
+	logger.Infow("consuming events", "stream", c.EventStream, "group", *group, "consumer", *consumer)
+	err = stream.Run(ctx, func(ctx context.Context, envelope eventstream.Envelope) error {
+		if envelope.Version != eventstream.Version {
+			logger.Warnw("skipping event of an unknown version", "id", envelope.ID, "version", envelope.Version)
+			return nil
+		}
+
+		switch envelope.Type {
+		case "stock.decreased", "stock.increased":
+			var data stockChanged
+			if err := envelope.Decode(&data); err != nil {
+				return err
+			}
+			logger.Infow("stock changed", "id", envelope.ID, "type", envelope.Type, "reason", data.Reason, "items", data.Items)
+		default:
+			logger.Infow("order event", "id", envelope.ID, "type", envelope.Type, "data", string(envelope.Data))
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		logger.Fatal(err)
+	}
+}
@@ -6,7 +6,10 @@ CREATE TABLE IF NOT EXISTS users (
     id INT AUTO_INCREMENT PRIMARY KEY,
     name VARCHAR(100) NOT NULL,
     email VARCHAR(100) NOT NULL UNIQUE,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at datetime NULL,
+    INDEX idx_users_deleted_at (deleted_at)
 );
 
 -- Inserting sample user data
@@ -17,33 +20,61 @@ INSERT INTO users (name, email) VALUES
 ('Michael Brown', 'michael.brown@example.com'),
 ('Sarah Davis', 'sarah.davis@example.com');
 
+-- Creating the addresses table, the addresses saved by each user
+CREATE TABLE IF NOT EXISTS addresses (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED,
+    name VARCHAR(100) NOT NULL,
+    line1 VARCHAR(200) NOT NULL,
+    line2 VARCHAR(200),
+    city VARCHAR(100) NOT NULL,
+    postal_code VARCHAR(20),
+    region VARCHAR(100),
+    country VARCHAR(2) NOT NULL,
+    created_at datetime DEFAULT current_timestamp(),
+    updated_at datetime DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+    deleted_at datetime NULL,
+    INDEX idx_addresses_user_id (user_id),
+    INDEX idx_addresses_deleted_at (deleted_at)
+);
+
+-- Inserting sample address data
+INSERT INTO addresses (user_id, name, line1, city, postal_code, country) VALUES
+(1, 'John Doe', '350 Fifth Avenue', 'New York', '10118', 'US'),
+(2, 'Jane Smith', 'Via Roma 1', 'Milano', '20121', 'IT');
+
 -- Creating the products table
 CREATE TABLE IF NOT EXISTS products (
     id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
     name VARCHAR(100) NOT NULL,
     description TEXT,
-    price DECIMAL(10, 2) NOT NULL,
+    price BIGINT NOT NULL,
     category VARCHAR(50),
     created_at datetime DEFAULT current_timestamp(),
-    updated_at datetime DEFAULT current_timestamp() ON UPDATE current_timestamp()
+    updated_at datetime DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+    deleted_at datetime NULL,
+    INDEX idx_products_category (category),
+    INDEX idx_products_deleted_at (deleted_at)
 );
 
 -- Inserting sample product data
 INSERT INTO products (name, description, price, category) VALUES
-('Laptop', 'High-performance laptop for gaming and development', 1200.00, 'Electronics'),
-('Smartphone', 'Latest model with powerful camera and fast performance', 800.00, 'Electronics'),
-('Wireless Mouse', 'Ergonomic wireless mouse with USB receiver', 25.50, 'Accessories'),
-('Headphones', 'Noise-canceling headphones for immersive sound', 150.00, 'Accessories'),
-('Keyboard', 'Mechanical keyboard with RGB lighting', 100.00, 'Accessories'),
-('Coffee Mug', 'Ceramic mug with a funny quote', 15.00, 'Home & Kitchen'),
-('Blender', 'High-speed blender for smoothies and shakes', 60.00, 'Home & Kitchen'),
-('Desk Chair', 'Comfortable ergonomic chair for home office', 200.00, 'Furniture');
+('Laptop', 'High-performance laptop for gaming and development', 120000, 'Electronics'),
+('Smartphone', 'Latest model with powerful camera and fast performance', 80000, 'Electronics'),
+('Wireless Mouse', 'Ergonomic wireless mouse with USB receiver', 2550, 'Accessories'),
+('Headphones', 'Noise-canceling headphones for immersive sound', 15000, 'Accessories'),
+('Keyboard', 'Mechanical keyboard with RGB lighting', 10000, 'Accessories'),
+('Coffee Mug', 'Ceramic mug with a funny quote', 1500, 'Home & Kitchen'),
+('Blender', 'High-speed blender for smoothies and shakes', 6000, 'Home & Kitchen'),
+('Desk Chair', 'Comfortable ergonomic chair for home office', 20000, 'Furniture');
 
 -- Creating the inventory table
 CREATE TABLE IF NOT EXISTS inventories (
     id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
     product_id BIGINT UNSIGNED,
     stock BIGINT NOT NULL,
+    reorder_point INT NULL,
+    reorder_quantity INT NOT NULL DEFAULT 0,
     FOREIGN KEY (product_id) REFERENCES products(id)
 );
 
@@ -58,22 +89,85 @@ INSERT INTO inventories (product_id, stock) VALUES
 (7, 80),  -- Blender
 (8, 60);  -- Desk Chair
 
+-- Creating the inventory movements ledger
+CREATE TABLE IF NOT EXISTS inventory_movements (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    product_id BIGINT UNSIGNED NOT NULL,
+    quantity INT NOT NULL,
+    reason VARCHAR(30) NOT NULL,
+    order_id BIGINT UNSIGNED NULL,
+    note VARCHAR(255),
+    created_at datetime DEFAULT current_timestamp(),
+    INDEX idx_inventory_movements_product_id (product_id),
+    INDEX idx_inventory_movements_order_id (order_id),
+    INDEX idx_inventory_movements_created_at (created_at)
+);
+
+-- Creating the reservations table
+CREATE TABLE IF NOT EXISTS reservations (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    order_id BIGINT UNSIGNED NOT NULL,
+    product_id BIGINT UNSIGNED NOT NULL,
+    quantity INT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    created_at datetime DEFAULT current_timestamp(),
+    updated_at datetime DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+    INDEX idx_reservations_order_id (order_id),
+    INDEX idx_reservations_product_status (product_id, status)
+);
+
+-- Creating the low-stock alerts table
+CREATE TABLE IF NOT EXISTS low_stock_alerts (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    product_id BIGINT UNSIGNED NOT NULL,
+    stock INT NOT NULL,
+    reorder_point INT NOT NULL,
+    reorder_quantity INT NOT NULL,
+    reason VARCHAR(30) NOT NULL,
+    order_id BIGINT UNSIGNED NULL,
+    status VARCHAR(20) NOT NULL,
+    created_at datetime DEFAULT current_timestamp(),
+    resolved_at datetime NULL,
+    INDEX idx_low_stock_alerts_product_status (product_id, status),
+    INDEX idx_low_stock_alerts_order_id (order_id),
+    INDEX idx_low_stock_alerts_created_at (created_at)
+);
+
 -- Creating the orders table
 CREATE TABLE IF NOT EXISTS orders (
     id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
     user_id BIGINT UNSIGNED NOT NULL,
-    status VARCHAR(50) DEFAULT 'Pending',
+    status VARCHAR(20) DEFAULT 'pending',
+    reserved_until datetime NULL,
     created_at datetime DEFAULT current_timestamp(),
-    updated_at datetime DEFAULT current_timestamp() ON UPDATE current_timestamp()
+    updated_at datetime DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+    currency VARCHAR(3) NOT NULL DEFAULT '',
+    exchange_rate DECIMAL(18, 8) NOT NULL DEFAULT 1,
+    region VARCHAR(20) NOT NULL DEFAULT '',
+    subtotal BIGINT NOT NULL DEFAULT 0,
+    discount BIGINT NOT NULL DEFAULT 0,
+    tax BIGINT NOT NULL DEFAULT 0,
+    total BIGINT NOT NULL DEFAULT 0,
+    shipping_name VARCHAR(100),
+    shipping_line1 VARCHAR(200),
+    shipping_line2 VARCHAR(200),
+    shipping_city VARCHAR(100),
+    shipping_postal_code VARCHAR(20),
+    shipping_region VARCHAR(100),
+    shipping_country VARCHAR(2),
+    INDEX idx_orders_status_reserved_until (status, reserved_until),
+    INDEX idx_orders_updated_at_id (updated_at, id),
+    INDEX idx_orders_created_at_id (created_at, id),
+    INDEX idx_orders_total (total)
 );
 
 -- Inserting sample order data
-INSERT INTO orders (user_id, status) VALUES
-(1, 'pending'),
-(2, 'completed'),
-(3, 'shipped'),
-(4, 'cancelled'),
-(5, 'pending');
+INSERT INTO orders (user_id, status, currency, subtotal, total) VALUES
+(1, 'pending', 'EUR', 125100, 125100),
+(2, 'delivered', 'EUR', 95000, 95000),
+(3, 'shipped', 'EUR', 14500, 14500),
+(4, 'cancelled', 'EUR', 6000, 6000),
+(5, 'pending', 'EUR', 20000, 20000);
 
 -- Creating the order_items table
 CREATE TABLE IF NOT EXISTS order_items (
@@ -81,18 +175,219 @@ CREATE TABLE IF NOT EXISTS order_items (
     order_id BIGINT UNSIGNED,
     product_id BIGINT UNSIGNED,
     quantity INT NOT NULL,
-    price DECIMAL(10, 2) NOT NULL,
+    price BIGINT NOT NULL,
+    total BIGINT NOT NULL DEFAULT 0,
+    tax_percent DECIMAL(7, 4) NOT NULL DEFAULT 0,
+    tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+    tax BIGINT NOT NULL DEFAULT 0,
     FOREIGN KEY (order_id) REFERENCES orders(id),
     FOREIGN KEY (product_id) REFERENCES products(id)
 );
 
+-- Creating the order_outbox table
+CREATE TABLE IF NOT EXISTS order_outbox (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    order_id BIGINT UNSIGNED NOT NULL,
+    type VARCHAR(30) NOT NULL,
+    from_status VARCHAR(30),
+    to_status VARCHAR(30),
+    status VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at datetime NOT NULL,
+    last_error VARCHAR(1000),
+    delivered_at datetime NULL,
+    created_at datetime DEFAULT current_timestamp(),
+    updated_at datetime DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+    INDEX idx_order_outbox_order_id (order_id),
+    INDEX idx_order_outbox_status_next_attempt_at (status, next_attempt_at)
+);
+
+-- Creating the reindex_watermarks table
+CREATE TABLE IF NOT EXISTS reindex_watermarks (
+    `index` VARCHAR(100) PRIMARY KEY,
+    synced_at datetime(3) NOT NULL,
+    updated_at datetime DEFAULT current_timestamp() ON UPDATE current_timestamp()
+);
+
 -- Inserting sample order items data
-INSERT INTO order_items (order_id, product_id, quantity, price) VALUES
-(1, 1, 1, 1200.00),  -- Order 1, Laptop
-(1, 3, 2, 25.50),    -- Order 1, Wireless Mouse
-(2, 2, 1, 800.00),   -- Order 2, Smartphone
-(2, 4, 1, 150.00),   -- Order 2, Headphones
-(3, 5, 1, 100.00),   -- Order 3, Keyboard
-(3, 6, 3, 15.00),    -- Order 3, Coffee Mug
-(4, 7, 1, 60.00),    -- Order 4, Blender
-(5, 8, 1, 200.00);   -- Order 5, Desk Chair
+INSERT INTO order_items (order_id, product_id, quantity, price, total) VALUES
+(1, 1, 1, 120000, 120000), -- Order 1, Laptop
+(1, 3, 2, 2550, 5100),     -- Order 1, Wireless Mouse
+(2, 2, 1, 80000, 80000),   -- Order 2, Smartphone
+(2, 4, 1, 15000, 15000),   -- Order 2, Headphones
+(3, 5, 1, 10000, 10000),   -- Order 3, Keyboard
+(3, 6, 3, 1500, 4500),     -- Order 3, Coffee Mug
+(4, 7, 1, 6000, 6000),     -- Order 4, Blender
+(5, 8, 1, 20000, 20000);   -- Order 5, Desk Chair
+
+-- Creating the exchange_rates table, rates are from the base currency (EUR)
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency VARCHAR(3) PRIMARY KEY,
+    rate DECIMAL(18, 8) NOT NULL,
+    updated_at datetime DEFAULT current_timestamp() ON UPDATE current_timestamp()
+);
+
+-- Inserting sample exchange rates
+INSERT INTO exchange_rates (currency, rate) VALUES
+('USD', 1.08),
+('GBP', 0.85);
+
+-- Creating the promotions table
+CREATE TABLE IF NOT EXISTS promotions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(50) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    scope VARCHAR(20) NOT NULL,
+    product_id BIGINT UNSIGNED NULL,
+    percent BIGINT NOT NULL DEFAULT 0,
+    amount BIGINT NOT NULL DEFAULT 0,
+    buy_quantity BIGINT NOT NULL DEFAULT 0,
+    get_quantity BIGINT NOT NULL DEFAULT 0,
+    usage_limit BIGINT NOT NULL DEFAULT 0,
+    usage_count BIGINT NOT NULL DEFAULT 0,
+    starts_at datetime NULL,
+    ends_at datetime NULL,
+    created_at datetime DEFAULT current_timestamp(),
+    updated_at datetime DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+    deleted_at datetime NULL,
+    UNIQUE INDEX idx_promotions_code (code),
+    INDEX idx_promotions_product_id (product_id),
+    INDEX idx_promotions_deleted_at (deleted_at)
+);
+
+-- Inserting sample promotions
+INSERT INTO promotions (code, type, scope, product_id, percent, amount, buy_quantity, get_quantity, usage_limit) VALUES
+('WELCOME10', 'percentage', 'order', NULL, 10, 0, 0, 0, 0),
+('MUGS3FOR2', 'buy_x_get_y', 'product', 6, 0, 0, 2, 1, 0),
+('FIVEOFF', 'fixed', 'order', NULL, 0, 500, 0, 0, 100);
+
+-- Creating the order_promotions table, the promotions redeemed by each order
+CREATE TABLE IF NOT EXISTS order_promotions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    order_id BIGINT UNSIGNED,
+    promotion_id BIGINT UNSIGNED,
+    code VARCHAR(50) NOT NULL,
+    discount BIGINT NOT NULL,
+    INDEX idx_order_promotions_order_id (order_id),
+    INDEX idx_order_promotions_promotion_id (promotion_id),
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+
+-- Creating the tax_rates table, an empty category or region matches any
+CREATE TABLE IF NOT EXISTS tax_rates (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    category VARCHAR(50) NOT NULL DEFAULT '',
+    region VARCHAR(20) NOT NULL DEFAULT '',
+    percent DECIMAL(7, 4) NOT NULL,
+    inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at datetime DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+    UNIQUE INDEX idx_tax_rates_category_region (category, region)
+);
+
+-- Inserting sample tax rates
+INSERT INTO tax_rates (category, region, percent, inclusive) VALUES
+('', 'IT', 22, TRUE),
+('Home & Kitchen', 'IT', 10, TRUE),
+('', 'US', 8.875, FALSE);
+
+-- Creating the shipments table, the parcels sent for each order
+CREATE TABLE IF NOT EXISTS shipments (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    order_id BIGINT UNSIGNED,
+    carrier VARCHAR(50) NOT NULL,
+    tracking_number VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    shipped_at datetime NOT NULL,
+    delivered_at datetime NULL,
+    created_at datetime DEFAULT current_timestamp(),
+    updated_at datetime DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+    INDEX idx_shipments_order_id (order_id),
+    INDEX idx_shipments_tracking_number (tracking_number)
+);
+
+-- Creating the shipment_items table, the units of each order item in a shipment
+CREATE TABLE IF NOT EXISTS shipment_items (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    shipment_id BIGINT UNSIGNED,
+    order_item_id BIGINT UNSIGNED,
+    quantity INT NOT NULL,
+    INDEX idx_shipment_items_shipment_id (shipment_id),
+    INDEX idx_shipment_items_order_item_id (order_item_id),
+    FOREIGN KEY (shipment_id) REFERENCES shipments(id) ON DELETE CASCADE
+);
+
+-- Creating the returns table, the goods customers send back and what was refunded for them
+CREATE TABLE IF NOT EXISTS `returns` (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    order_id BIGINT UNSIGNED,
+    status VARCHAR(20) NOT NULL,
+    reason VARCHAR(500),
+    note VARCHAR(500),
+    restock BOOLEAN NOT NULL DEFAULT FALSE,
+    received_at datetime NULL,
+    refund_amount BIGINT NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL DEFAULT '',
+    refunded_at datetime NULL,
+    created_at datetime DEFAULT current_timestamp(),
+    updated_at datetime DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+    INDEX idx_returns_order_id (order_id)
+);
+
+-- Creating the return_items table, the units of each order item in a return
+CREATE TABLE IF NOT EXISTS return_items (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    return_id BIGINT UNSIGNED,
+    order_item_id BIGINT UNSIGNED,
+    quantity INT NOT NULL,
+    INDEX idx_return_items_return_id (return_id),
+    INDEX idx_return_items_order_item_id (order_item_id),
+    FOREIGN KEY (return_id) REFERENCES `returns`(id) ON DELETE CASCADE
+);
+
+-- Creating the payments table, the attempts to pay for each order at the payment provider
+CREATE TABLE IF NOT EXISTS payments (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    order_id BIGINT UNSIGNED,
+    provider VARCHAR(30) NOT NULL,
+    reference VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    amount BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    refunded BIGINT NOT NULL DEFAULT 0,
+    failure_reason VARCHAR(255),
+    created_at datetime DEFAULT current_timestamp(),
+    updated_at datetime DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+    INDEX idx_payments_order_id (order_id),
+    UNIQUE INDEX idx_payments_provider_reference (provider, reference),
+    FOREIGN KEY (order_id) REFERENCES orders(id)
+);
+
+-- Creating the webhook_subscriptions table, the endpoints order events are sent to
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    url VARCHAR(2048) NOT NULL,
+    event_types VARCHAR(255) NOT NULL,
+    secret VARCHAR(100) NOT NULL,
+    active BOOLEAN NOT NULL,
+    created_at datetime DEFAULT current_timestamp(),
+    updated_at datetime DEFAULT current_timestamp() ON UPDATE current_timestamp()
+);
+
+-- Creating the webhook_deliveries table, the delivery log of each subscription
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    subscription_id BIGINT UNSIGNED NOT NULL,
+    event_id VARCHAR(100) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at datetime NOT NULL,
+    response_status INT NOT NULL DEFAULT 0,
+    last_error VARCHAR(1000),
+    delivered_at datetime NULL,
+    created_at datetime DEFAULT current_timestamp(),
+    updated_at datetime DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+    UNIQUE INDEX idx_webhook_deliveries_subscription_event (subscription_id, event_id),
+    INDEX idx_webhook_deliveries_status_next_attempt_at (status, next_attempt_at)
+);
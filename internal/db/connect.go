@@ -19,7 +19,7 @@ func ConnectDB(configuration *configuration.Configuration) (*gorm.DB, error) {
 		configuration.DatabaseName,
 	)
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, err
 	}
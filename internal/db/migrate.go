@@ -0,0 +1,50 @@
+package db
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// ToMinorUnits converts a decimal money column to an integer amount of minor units.
+// It has to run before AutoMigrate, which would otherwise change the column type and drop the cents.
+// The amounts are written to a scratch column that replaces the decimal one in a single statement,
+// so a conversion interrupted half way is simply redone on the next start.
+func ToMinorUnits(db *gorm.DB, table string, column string) error {
+	if !db.Migrator().HasTable(table) {
+		return nil
+	}
+
+	columnTypes, err := db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return err
+	}
+
+	isDecimal := false
+	for _, columnType := range columnTypes {
+		if columnType.Name() == column {
+			isDecimal = strings.EqualFold(columnType.DatabaseTypeName(), "decimal")
+		}
+	}
+	if !isDecimal {
+		return nil
+	}
+
+	scratch := column + "_minor"
+	if !db.Migrator().HasColumn(table, scratch) {
+		err := db.Exec(fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN `%s` BIGINT NULL", table, scratch)).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	err = db.Exec(fmt.Sprintf("UPDATE `%s` SET `%s` = ROUND(`%s` * 100)", table, scratch, column)).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Exec(fmt.Sprintf(
+		"ALTER TABLE `%s` DROP COLUMN `%s`, CHANGE `%s` `%s` BIGINT NOT NULL",
+		table, column, scratch, column,
+	)).Error
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"sync"
+)
+
+// ITransactor runs a unit of work inside a single database transaction.
+// Stores taking part in the unit of work must be bound to the handed tx through their WithTx method.
+type ITransactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error) error
+}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) ITransactor {
+	return &transactor{db: db}
+}
+
+// Transaction runs fn and, once the outermost transaction committed, the hooks registered with AfterCommit.
+// Nested transactions, e.g. of a service bound to tx, become savepoints whose hooks wait for the outer commit.
+func (t *transactor) Transaction(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error) error {
+	if hooks, ok := ctx.Value(hooksKey{}).(*commitHooks); ok {
+		mark := hooks.len()
+		err := t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(ctx, tx)
+		})
+		if err != nil {
+			hooks.truncate(mark)
+		}
+		return err
+	}
+
+	hooks := &commitHooks{}
+	ctx = context.WithValue(ctx, hooksKey{}, hooks)
+	err := t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(ctx, tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	hooks.run(ctx)
+	return nil
+}
+
+type hooksKey struct{}
+
+type commitHooks struct {
+	mu    sync.Mutex
+	hooks []func(ctx context.Context)
+}
+
+func (h *commitHooks) add(hook func(ctx context.Context)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks = append(h.hooks, hook)
+}
+
+func (h *commitHooks) len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.hooks)
+}
+
+func (h *commitHooks) truncate(n int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks = h.hooks[:n]
+}
+
+func (h *commitHooks) run(ctx context.Context) {
+	h.mu.Lock()
+	hooks := h.hooks
+	h.hooks = nil
+	h.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook(ctx)
+	}
+}
+
+// AfterCommit runs hook once the transaction ctx belongs to commits, and never if it rolls back.
+// Outside of a transaction the hook runs right away.
+func AfterCommit(ctx context.Context, hook func(ctx context.Context)) {
+	if hooks, ok := ctx.Value(hooksKey{}).(*commitHooks); ok {
+		hooks.add(hook)
+		return
+	}
+	hook(ctx)
+}
@@ -0,0 +1,173 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"github.com/p4xx07/order-service/configuration"
+	"go.uber.org/zap"
+	"slices"
+	"sync"
+)
+
+// Name identifies a kind of event, e.g. order.created.
+type Name string
+
+// Event is something that happened in a domain, delivered to whoever subscribed to its name.
+type Event interface {
+	EventName() Name
+}
+
+type Handler func(ctx context.Context, event Event) error
+
+// Subscriber names a synchronous subscriber, so that its deliveries can be retried apart from the other subscribers.
+type Subscriber string
+
+// IBus delivers the published events to their subscribers. Synchronous subscribers run inside Publish,
+// which returns their errors; asynchronous subscribers run later on the workers started by Run and
+// their errors are only logged.
+type IBus interface {
+	Subscribe(subscriber Subscriber, name Name, handler Handler)
+	SubscribeAsync(name Name, handler Handler)
+	Publish(ctx context.Context, events ...Event) error
+	Subscribers(events ...Event) []Subscriber
+	PublishTo(ctx context.Context, subscriber Subscriber, events ...Event) error
+	Run(ctx context.Context)
+}
+
+type subscription struct {
+	subscriber Subscriber
+	handler    Handler
+}
+
+type delivery struct {
+	event   Event
+	handler Handler
+}
+
+type bus struct {
+	configuration *configuration.Configuration
+	logger        *zap.SugaredLogger
+	mu            sync.RWMutex
+	handlers      map[Name][]subscription
+	asyncHandlers map[Name][]Handler
+	queue         chan delivery
+}
+
+func NewBus(configuration *configuration.Configuration, logger *zap.SugaredLogger) IBus {
+	return &bus{
+		configuration: configuration,
+		logger:        logger,
+		handlers:      map[Name][]subscription{},
+		asyncHandlers: map[Name][]Handler{},
+		queue:         make(chan delivery, configuration.EventQueueSize),
+	}
+}
+
+func (b *bus) Subscribe(subscriber Subscriber, name Name, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], subscription{subscriber: subscriber, handler: handler})
+}
+
+func (b *bus) SubscribeAsync(name Name, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.asyncHandlers[name] = append(b.asyncHandlers[name], handler)
+}
+
+// Publish runs the synchronous subscribers of every event in the order they subscribed, even when one of them fails,
+// and queues the event for the asynchronous ones. It blocks while the queue is full.
+func (b *bus) Publish(ctx context.Context, events ...Event) error {
+	var errs []error
+	for _, event := range events {
+		b.mu.RLock()
+		handlers := b.handlers[event.EventName()]
+		asyncHandlers := b.asyncHandlers[event.EventName()]
+		b.mu.RUnlock()
+
+		for _, subscription := range handlers {
+			if err := subscription.handler(ctx, event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		for _, handler := range asyncHandlers {
+			select {
+			case b.queue <- delivery{event: event, handler: handler}:
+			case <-ctx.Done():
+				return errors.Join(append(errs, ctx.Err())...)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Subscribers lists the synchronous subscribers of the events, each once, in the order they subscribed.
+func (b *bus) Subscribers(events ...Event) []Subscriber {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var subscribers []Subscriber
+	for _, event := range events {
+		for _, subscription := range b.handlers[event.EventName()] {
+			if !slices.Contains(subscribers, subscription.subscriber) {
+				subscribers = append(subscribers, subscription.subscriber)
+			}
+		}
+	}
+	return subscribers
+}
+
+// PublishTo runs the handlers of one synchronous subscriber for the events, even when one of them fails,
+// and leaves the other subscribers, the asynchronous ones included, alone.
+func (b *bus) PublishTo(ctx context.Context, subscriber Subscriber, events ...Event) error {
+	var errs []error
+	for _, event := range events {
+		b.mu.RLock()
+		handlers := b.handlers[event.EventName()]
+		b.mu.RUnlock()
+
+		for _, subscription := range handlers {
+			if subscription.subscriber != subscriber {
+				continue
+			}
+			if err := subscription.handler(ctx, event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run delivers the queued events to the asynchronous subscribers on EVENT_WORKERS workers until ctx is cancelled.
+func (b *bus) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range max(b.configuration.EventWorkers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case d := <-b.queue:
+					b.deliver(d)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (b *bus) deliver(d delivery) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Errorw("event subscriber panicked", "event", d.event.EventName(), "panic", r)
+		}
+	}()
+
+	// the request that published the event is long gone by now
+	if err := d.handler(context.Background(), d.event); err != nil {
+		b.logger.Errorw("error handling event", "error", err, "event", d.event.EventName())
+	}
+}
@@ -0,0 +1,124 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/p4xx07/order-service/configuration"
+	http2 "github.com/p4xx07/order-service/internal/http"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+)
+
+var (
+	ErrKeyReused         = errors.New("idempotency key was already used for a different request")
+	ErrRequestInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+// Middleware makes a route safe to retry: a request carrying an Idempotency-Key header
+// runs once and any replay with the same key and body gets the original response back.
+// Requests without the header are passed through untouched.
+type Middleware fiber.Handler
+
+func NewMiddleware(store IStore, configuration *configuration.Configuration, logger *zap.SugaredLogger) Middleware {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderKey)
+		if key == "" {
+			return c.Next()
+		}
+
+		requestHash := hashRequest(c)
+		token, record, err := store.Claim(c.Context(), key, requestHash)
+		if err != nil {
+			logger.Errorw("error claiming idempotency key", "error", err, "key", key)
+			return c.SendStatus(http.StatusInternalServerError)
+		}
+
+		if record != nil {
+			if record.RequestHash != requestHash {
+				return http2.JSON(c, http.StatusUnprocessableEntity, nil, ErrKeyReused)
+			}
+			if !record.Completed {
+				return http2.JSON(c, http.StatusConflict, nil, ErrRequestInProgress)
+			}
+
+			c.Set(HeaderReplayed, "true")
+			c.Set(fiber.HeaderContentType, record.ContentType)
+			return c.Status(record.StatusCode).Send(record.Body)
+		}
+
+		stop := keepClaim(store, logger, key, token, configuration.IdempotencyPendingTTL/3)
+		err = c.Next()
+		stop()
+		if err != nil {
+			releaseKey(c, store, logger, key, token)
+			return err
+		}
+
+		// server errors are not remembered so that the client can retry them
+		status := c.Response().StatusCode()
+		if status >= http.StatusInternalServerError {
+			releaseKey(c, store, logger, key, token)
+			return nil
+		}
+
+		err = store.Complete(c.Context(), key, token, Record{
+			RequestHash: requestHash,
+			StatusCode:  status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        c.Response().Body(),
+		})
+		if err != nil {
+			logger.Errorw("error storing idempotent response", "error", err, "key", key)
+		}
+		return nil
+	}
+}
+
+// keepClaim refreshes the claim until stop is called, so that a request running longer than the pending ttl
+// keeps its key and a retry cannot run it a second time.
+func keepClaim(store IStore, logger *zap.SugaredLogger, key string, token string, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := store.Refresh(context.Background(), key, token); err != nil {
+					logger.Warnw("error refreshing idempotency key", "error", err, "key", key)
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+func releaseKey(c *fiber.Ctx, store IStore, logger *zap.SugaredLogger, key string, token string) {
+	if err := store.Release(c.Context(), key, token); err != nil {
+		logger.Errorw("error releasing idempotency key", "error", err, "key", key)
+	}
+}
+
+func hashRequest(c *fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Method() + " " + c.Path() + "\n"))
+	hash.Write(c.Body())
+	return hex.EncodeToString(hash.Sum(nil))
+}
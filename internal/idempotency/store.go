@@ -0,0 +1,157 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// releaseScript deletes the key only while it still holds the claim of our request,
+// so a claim that expired and was taken over by another request is left alone.
+var releaseScript = redis.NewScript(`
+local value = redis.call("get", KEYS[1])
+if value and cjson.decode(value).token == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0
+`)
+
+// completeScript stores the response only while the key still holds the claim of our request.
+var completeScript = redis.NewScript(`
+local value = redis.call("get", KEYS[1])
+if value and cjson.decode(value).token == ARGV[1] then
+	redis.call("set", KEYS[1], ARGV[2], "PX", ARGV[3])
+	return 1
+end
+return 0
+`)
+
+// refreshScript extends the claim of our request while it is still running.
+var refreshScript = redis.NewScript(`
+local value = redis.call("get", KEYS[1])
+if value and cjson.decode(value).token == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+var ErrNotClaimed = errors.New("idempotency key is no longer claimed by this request")
+
+type Record struct {
+	RequestHash string `json:"request_hash"`
+	// Token identifies the request holding a pending claim.
+	Token       string `json:"token,omitempty"`
+	Completed   bool   `json:"completed"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+type IStore interface {
+	// Claim reserves key for the request identified by requestHash and returns the token of the claim.
+	// It returns the stored record instead when the key was already claimed.
+	Claim(ctx context.Context, key string, requestHash string) (string, *Record, error)
+	// Complete stores the response of the request holding token.
+	Complete(ctx context.Context, key string, token string, record Record) error
+	// Refresh keeps the claim holding token from expiring while its request runs.
+	Refresh(ctx context.Context, key string, token string) error
+	// Release gives up the claim holding token, so that the request can be retried.
+	Release(ctx context.Context, key string, token string) error
+}
+
+type store struct {
+	redisClient *redis.Client
+	ttl         time.Duration
+	// pendingTTL bounds how long a key stays claimed by a request that stopped refreshing it, e.g. after a crash.
+	pendingTTL time.Duration
+}
+
+func NewStore(redisClient *redis.Client, configuration *configuration.Configuration) IStore {
+	return &store{redisClient: redisClient, ttl: configuration.IdempotencyTTL, pendingTTL: configuration.IdempotencyPendingTTL}
+}
+
+func (s *store) Claim(ctx context.Context, key string, requestHash string) (string, *Record, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	value, err := json.Marshal(Record{RequestHash: requestHash, Token: token})
+	if err != nil {
+		return "", nil, err
+	}
+
+	claimed, err := s.redisClient.SetNX(ctx, s.getKey(key), value, s.pendingTTL).Result()
+	if err != nil {
+		return "", nil, err
+	}
+	if claimed {
+		return token, nil, nil
+	}
+
+	stored, err := s.redisClient.Get(ctx, s.getKey(key)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			// the claim expired in between, let the caller retry as a fresh request
+			return s.Claim(ctx, key, requestHash)
+		}
+		return "", nil, err
+	}
+
+	var record Record
+	if err := json.Unmarshal(stored, &record); err != nil {
+		return "", nil, err
+	}
+	return "", &record, nil
+}
+
+func (s *store) Complete(ctx context.Context, key string, token string, record Record) error {
+	record.Completed = true
+	record.Token = ""
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	n, err := completeScript.Run(ctx, s.redisClient, []string{s.getKey(key)}, token, value, s.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotClaimed
+	}
+	return nil
+}
+
+func (s *store) Refresh(ctx context.Context, key string, token string) error {
+	n, err := refreshScript.Run(ctx, s.redisClient, []string{s.getKey(key)}, token, s.pendingTTL.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotClaimed
+	}
+	return nil
+}
+
+func (s *store) Release(ctx context.Context, key string, token string) error {
+	return releaseScript.Run(ctx, s.redisClient, []string{s.getKey(key)}, token).Err()
+}
+
+func (s *store) getKey(key string) string {
+	return fmt.Sprintf("idempotency_key_%s", key)
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,232 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	mathrand "math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotAcquired = errors.New("lock not acquired")
+	ErrNotHeld     = errors.New("lock not held")
+	ErrLost        = errors.New("lock lost")
+)
+
+const maxRetryDelay = time.Second
+
+// releaseScript deletes the key only while it still holds our token,
+// so a lock that expired and was taken over by someone else is left alone.
+var releaseScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0
+`)
+
+// refreshScript extends the key expiry only while it still holds our token.
+var refreshScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// ILocker hands out distributed locks over a set of keys.
+// Keys are always taken in sorted order so that two callers locking overlapping sets cannot deadlock.
+type ILocker interface {
+	Acquire(ctx context.Context, keys ...string) (ILock, error)
+}
+
+// ILock is a held lock. It is renewed in the background until Release is called.
+// When renewing fails for good, the lock may have gone to someone else: Lost is closed and Err returns ErrLost,
+// so callers should check Err before committing the work the lock guards.
+type ILock interface {
+	Refresh(ctx context.Context) error
+	Release(ctx context.Context) error
+	Lost() <-chan struct{}
+	Err() error
+}
+
+type locker struct {
+	redisClient *redis.Client
+	logger      *zap.SugaredLogger
+	ttl         time.Duration
+	waitTimeout time.Duration
+	retryDelay  time.Duration
+}
+
+func NewLocker(redisClient *redis.Client, configuration *configuration.Configuration, logger *zap.SugaredLogger) ILocker {
+	return &locker{
+		redisClient: redisClient,
+		logger:      logger,
+		ttl:         configuration.LockTTL,
+		waitTimeout: configuration.LockWaitTimeout,
+		retryDelay:  configuration.LockRetryDelay,
+	}
+}
+
+func (l *locker) Acquire(ctx context.Context, keys ...string) (ILock, error) {
+	keys = slices.Compact(slices.Sorted(slices.Values(keys)))
+
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(l.waitTimeout)
+	delay := l.retryDelay
+	for {
+		acquired, err := l.tryAcquire(ctx, keys, token)
+		if err != nil {
+			return nil, err
+		}
+		if acquired {
+			return l.newLock(keys, token), nil
+		}
+
+		if delay <= 0 || time.Now().Add(delay).After(deadline) {
+			return nil, ErrNotAcquired
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(jitter(delay)):
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// tryAcquire takes every key or none of them.
+func (l *locker) tryAcquire(ctx context.Context, keys []string, token string) (bool, error) {
+	for i, key := range keys {
+		ok, err := l.redisClient.SetNX(ctx, key, token, l.ttl).Result()
+		if err != nil || !ok {
+			l.release(ctx, keys[:i], token)
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// release gives up every key, also after a failure on one of them, so that no key is left held until it expires.
+func (l *locker) release(ctx context.Context, keys []string, token string) error {
+	var released int64
+	var errs []error
+	for _, key := range keys {
+		n, err := releaseScript.Run(ctx, l.redisClient, []string{key}, token).Int64()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		released += n
+	}
+
+	if released != int64(len(keys)-len(errs)) {
+		errs = append(errs, ErrNotHeld)
+	}
+	return errors.Join(errs...)
+}
+
+func (l *locker) refresh(ctx context.Context, keys []string, token string) error {
+	for _, key := range keys {
+		n, err := refreshScript.Run(ctx, l.redisClient, []string{key}, token, l.ttl.Milliseconds()).Int64()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrNotHeld
+		}
+	}
+	return nil
+}
+
+type lock struct {
+	locker *locker
+	keys   []string
+	token  string
+	stop   chan struct{}
+	once   sync.Once
+	lost   chan struct{}
+}
+
+func (l *locker) newLock(keys []string, token string) *lock {
+	held := &lock{locker: l, keys: keys, token: token, stop: make(chan struct{}), lost: make(chan struct{})}
+	go held.renew()
+	return held
+}
+
+func (l *lock) Refresh(ctx context.Context) error {
+	return l.locker.refresh(ctx, l.keys, l.token)
+}
+
+func (l *lock) Release(ctx context.Context) error {
+	l.once.Do(func() { close(l.stop) })
+	return l.locker.release(ctx, l.keys, l.token)
+}
+
+func (l *lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *lock) Err() error {
+	select {
+	case <-l.lost:
+		return ErrLost
+	default:
+		return nil
+	}
+}
+
+// renew keeps the lock alive for operations that outlive the ttl. A failed renewal is tried again on the next tick,
+// until a key turns out to be gone or the keys may have expired since the last renewal; the lock is lost then.
+func (l *lock) renew() {
+	interval := l.locker.ttl / 3
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	renewedAt := time.Now()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			err := l.Refresh(context.Background())
+			if err == nil {
+				renewedAt = time.Now()
+				continue
+			}
+
+			if errors.Is(err, ErrNotHeld) || time.Since(renewedAt) >= l.locker.ttl {
+				l.locker.logger.Errorw("lock lost", "error", err, "keys", l.keys)
+				close(l.lost)
+				return
+			}
+			l.locker.logger.Warnw("error renewing lock", "error", err, "keys", l.keys)
+		}
+	}
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func jitter(delay time.Duration) time.Duration {
+	return delay/2 + mathrand.N(delay/2+1)
+}
@@ -0,0 +1,48 @@
+package money
+
+import (
+	"fmt"
+	"math"
+)
+
+// Amount is an amount of money in minor units, e.g. cents, so that sums and products are exact.
+type Amount int64
+
+// Times is the amount for quantity units priced at a.
+func (a Amount) Times(quantity int) Amount {
+	return a * Amount(quantity)
+}
+
+// Convert is the amount in another currency at the given exchange rate, rounded to the nearest minor unit.
+func (a Amount) Convert(rate float64) Amount {
+	return Amount(math.Round(float64(a) * rate))
+}
+
+// Percent is the given percentage of the amount, rounded to the nearest minor unit.
+func (a Amount) Percent(percent int) Amount {
+	return Amount(math.Round(float64(a) * float64(percent) / 100))
+}
+
+// String formats the amount in major units with two decimals, e.g. 1250 as 12.50.
+func (a Amount) String() string {
+	sign := ""
+	if a < 0 {
+		sign, a = "-", -a
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, a/100, a%100)
+}
+
+// Currency is an ISO 4217 currency code, e.g. EUR.
+type Currency string
+
+func (c Currency) IsValid() bool {
+	if len(c) != 3 {
+		return false
+	}
+	for _, r := range c {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
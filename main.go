@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"github.com/p4xx07/order-service/app/domains/order"
 	"github.com/p4xx07/order-service/configuration"
 	"github.com/p4xx07/order-service/deps"
 	"github.com/p4xx07/order-service/internal/log"
+	"go.uber.org/zap"
+	"os"
 )
 
 func main() {
@@ -20,6 +25,26 @@ func main() {
 		zapLogger.Fatal(err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "reindex" {
+		reindex(app.Reindexer, zapLogger, os.Args[2:])
+		return
+	}
+
+	app.StartWorkers(context.Background())
+
 	err = app.Routes().Listen("0.0.0.0:8080")
 	zapLogger.Error(err)
 }
+
+// reindex rebuilds the orders search index and exits, e.g. `order-service reindex -mode incremental`.
+func reindex(reindexer order.IReindexer, zapLogger *zap.SugaredLogger, args []string) {
+	flags := flag.NewFlagSet("reindex", flag.ExitOnError)
+	mode := flags.String("mode", string(order.ReindexFull), "full rebuilds the index, incremental syncs the orders changed since the last run")
+	_ = flags.Parse(args)
+
+	progress, err := reindexer.Reindex(context.Background(), order.ReindexMode(*mode))
+	if err != nil {
+		zapLogger.Fatal(err)
+	}
+	zapLogger.Infow("reindex completed", "mode", progress.Mode, "indexed", progress.Indexed)
+}
@@ -0,0 +1,186 @@
+package eventstream
+
+import (
+	"context"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCount   = 10
+	defaultBlock   = 5 * time.Second
+	defaultMinIdle = time.Minute
+	errorBackoff   = time.Second
+)
+
+// Handler handles one event. An event whose handler fails stays pending and is handed out again,
+// so handlers must cope with seeing an event more than once.
+type Handler func(ctx context.Context, envelope Envelope) error
+
+type ConsumerOptions struct {
+	Stream string
+	// Group is the consumer group: every group gets every event, the consumers of a group share them.
+	Group string
+	// Consumer names this consumer within the group, it must be unique and stable across restarts.
+	Consumer string
+	// Count is how many events are read at once, 10 by default.
+	Count int64
+	// Block is how long a read waits for new events, 5s by default.
+	Block time.Duration
+	// MinIdle is how long an event stays unacknowledged before it is handed out again,
+	// e.g. because its handler failed or its consumer died, 1m by default.
+	MinIdle time.Duration
+	// MaxDeliveries is how many times an event is handed out before it is acknowledged without being handled;
+	// 0 keeps handing it out.
+	MaxDeliveries int64
+}
+
+// Consumer reads a stream through a consumer group with at-least-once semantics:
+// events are acknowledged only once their handler succeeded.
+type Consumer struct {
+	client  redis.Cmdable
+	options ConsumerOptions
+	logger  *zap.SugaredLogger
+}
+
+func NewConsumer(client redis.Cmdable, options ConsumerOptions, logger *zap.SugaredLogger) *Consumer {
+	if options.Count <= 0 {
+		options.Count = defaultCount
+	}
+	if options.Block <= 0 {
+		options.Block = defaultBlock
+	}
+	if options.MinIdle <= 0 {
+		options.MinIdle = defaultMinIdle
+	}
+	return &Consumer{client: client, options: options, logger: logger}
+}
+
+// EnsureGroup creates the consumer group, and the stream if needed. A new group starts from the oldest event kept.
+func (c *Consumer) EnsureGroup(ctx context.Context) error {
+	err := c.client.XGroupCreateMkStream(ctx, c.options.Stream, c.options.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	return nil
+}
+
+// Run handles events until ctx is cancelled.
+func (c *Consumer) Run(ctx context.Context, handler Handler) error {
+	if err := c.EnsureGroup(ctx); err != nil {
+		return err
+	}
+
+	for ctx.Err() == nil {
+		if _, err := c.Poll(ctx, handler); err != nil && ctx.Err() == nil {
+			c.logger.Errorw("error polling event stream", "error", err, "stream", c.options.Stream, "group", c.options.Group)
+			select {
+			case <-ctx.Done():
+			case <-time.After(errorBackoff):
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// Poll hands out the events left pending for too long, then the new ones, and returns how many were handled.
+func (c *Consumer) Poll(ctx context.Context, handler Handler) (int, error) {
+	claimed, err := c.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+	handled := c.handle(ctx, claimed, handler)
+
+	streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    c.options.Group,
+		Consumer: c.options.Consumer,
+		Streams:  []string{c.options.Stream, ">"},
+		Count:    c.options.Count,
+		Block:    c.options.Block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return handled, nil
+	}
+	if err != nil {
+		return handled, err
+	}
+
+	for _, stream := range streams {
+		handled += c.handle(ctx, stream.Messages, handler)
+	}
+	return handled, nil
+}
+
+// claim takes over the events pending for longer than MinIdle, and gives up on the ones handed out MaxDeliveries times.
+func (c *Consumer) claim(ctx context.Context) ([]redis.XMessage, error) {
+	pending, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: c.options.Stream,
+		Group:  c.options.Group,
+		Idle:   c.options.MinIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  c.options.Count,
+	}).Result()
+	if err != nil || len(pending) == 0 {
+		return nil, err
+	}
+
+	var ids []string
+	for _, p := range pending {
+		if c.options.MaxDeliveries > 0 && p.RetryCount >= c.options.MaxDeliveries {
+			c.logger.Errorw("giving up on event", "id", p.ID, "stream", c.options.Stream, "group", c.options.Group, "deliveries", p.RetryCount)
+			c.ack(ctx, p.ID)
+			continue
+		}
+		ids = append(ids, p.ID)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return c.client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   c.options.Stream,
+		Group:    c.options.Group,
+		Consumer: c.options.Consumer,
+		MinIdle:  c.options.MinIdle,
+		Messages: ids,
+	}).Result()
+}
+
+func (c *Consumer) handle(ctx context.Context, messages []redis.XMessage, handler Handler) int {
+	handled := 0
+	for _, message := range messages {
+		if message.Values == nil {
+			// trimmed from the stream before it was handled
+			c.ack(ctx, message.ID)
+			continue
+		}
+
+		envelope, err := decodeEnvelope(message.Values)
+		if err != nil {
+			// no consumer will ever make sense of it
+			c.logger.Errorw("dropping event", "error", err, "id", message.ID, "stream", c.options.Stream)
+			c.ack(ctx, message.ID)
+			continue
+		}
+
+		if err := handler(ctx, envelope); err != nil {
+			c.logger.Warnw("error handling event", "error", err, "id", envelope.ID, "type", envelope.Type, "stream", c.options.Stream)
+			continue
+		}
+
+		c.ack(ctx, message.ID)
+		handled++
+	}
+	return handled
+}
+
+func (c *Consumer) ack(ctx context.Context, id string) {
+	if err := c.client.XAck(ctx, c.options.Stream, c.options.Group, id).Err(); err != nil {
+		// the event stays pending and is handed out again
+		c.logger.Errorw("error acknowledging event", "error", err, "id", id, "stream", c.options.Stream)
+	}
+}
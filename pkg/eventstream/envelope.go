@@ -0,0 +1,53 @@
+// Package eventstream publishes events to a Redis Stream and reads them back through consumer groups.
+// Every stream entry carries one Envelope, so consumers can tell the events apart and evolve with them.
+package eventstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Version is the version of the envelope. It changes when the envelope or the data of an event
+// changes in a way older consumers cannot read.
+const Version = 1
+
+const (
+	fieldType     = "type"
+	fieldEnvelope = "envelope"
+)
+
+type Envelope struct {
+	Version int `json:"version"`
+	// ID identifies the event. An event can be delivered more than once, and always with the same ID.
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func NewEnvelope(id string, eventType string, occurredAt time.Time, data interface{}) (Envelope, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("failed to encode %s event data: %w", eventType, err)
+	}
+	return Envelope{Version: Version, ID: id, Type: eventType, OccurredAt: occurredAt, Data: raw}, nil
+}
+
+// Decode unmarshals the event data into v.
+func (e Envelope) Decode(v interface{}) error {
+	return json.Unmarshal(e.Data, v)
+}
+
+func decodeEnvelope(values map[string]interface{}) (Envelope, error) {
+	raw, ok := values[fieldEnvelope].(string)
+	if !ok {
+		return Envelope{}, fmt.Errorf("entry has no %s field", fieldEnvelope)
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal([]byte(raw), &envelope); err != nil {
+		return Envelope{}, fmt.Errorf("malformed envelope: %w", err)
+	}
+	return envelope, nil
+}
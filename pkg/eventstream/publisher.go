@@ -0,0 +1,40 @@
+package eventstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+)
+
+// Publisher appends envelopes to a stream. The stream is trimmed to about maxLen entries,
+// consumers that fall further behind miss the oldest events.
+type Publisher struct {
+	client redis.Cmdable
+	stream string
+	maxLen int64
+}
+
+func NewPublisher(client redis.Cmdable, stream string, maxLen int64) *Publisher {
+	return &Publisher{client: client, stream: stream, maxLen: maxLen}
+}
+
+func (p *Publisher) Publish(ctx context.Context, envelopes ...Envelope) error {
+	for _, envelope := range envelopes {
+		raw, err := json.Marshal(envelope)
+		if err != nil {
+			return fmt.Errorf("failed to encode envelope %s: %w", envelope.ID, err)
+		}
+
+		err = p.client.XAdd(ctx, &redis.XAddArgs{
+			Stream: p.stream,
+			MaxLen: p.maxLen,
+			Approx: true,
+			Values: []interface{}{fieldType, envelope.Type, fieldEnvelope, string(raw)},
+		}).Err()
+		if err != nil {
+			return fmt.Errorf("failed to publish %s to %s: %w", envelope.ID, p.stream, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package currency_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/currency"
+	"github.com/p4xx07/order-service/internal/money"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStore struct {
+	mock.Mock
+}
+
+func (m *MockStore) List(ctx context.Context) ([]currency.ExchangeRate, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]currency.ExchangeRate), args.Error(1)
+}
+
+func (m *MockStore) Get(ctx context.Context, code money.Currency) (*currency.ExchangeRate, error) {
+	args := m.Called(ctx, code)
+	return args.Get(0).(*currency.ExchangeRate), args.Error(1)
+}
+
+func (m *MockStore) Save(ctx context.Context, rates []currency.ExchangeRate) error {
+	args := m.Called(ctx, rates)
+	return args.Error(0)
+}
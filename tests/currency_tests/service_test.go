@@ -0,0 +1,70 @@
+package currency_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/currency"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestRate(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	mockStore.On("Get", mock.Anything, money.Currency("USD")).Return(&currency.ExchangeRate{Currency: "USD", Rate: 1.08}, nil)
+	mockStore.On("Get", mock.Anything, money.Currency("JPY")).Return(&currency.ExchangeRate{}, gorm.ErrRecordNotFound)
+
+	service := currency.NewService(mockStore, &configuration.Configuration{BaseCurrency: "EUR"}, logger)
+
+	rate, err := service.Rate(context.Background(), "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, rate)
+
+	rate, err = service.Rate(context.Background(), "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.08, rate)
+
+	_, err = service.Rate(context.Background(), "JPY")
+	assert.ErrorIs(t, err, currency.ErrUnsupportedCurrency)
+}
+
+func TestSetRates(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	mockStore.On("Save", mock.Anything, []currency.ExchangeRate{{Currency: "GBP", Rate: 0.85}}).Return(nil)
+	mockStore.On("List", mock.Anything).Return([]currency.ExchangeRate{{Currency: "GBP", Rate: 0.85}}, nil)
+
+	service := currency.NewService(mockStore, &configuration.Configuration{BaseCurrency: "EUR"}, logger)
+
+	response, err := service.SetRates(context.Background(), currency.PutRatesRequest{Rates: map[money.Currency]float64{"GBP": 0.85}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, money.Currency("EUR"), response.Base)
+	assert.Len(t, response.Items, 1)
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestSetRatesInvalid(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	service := currency.NewService(mockStore, &configuration.Configuration{BaseCurrency: "EUR"}, logger)
+
+	_, err := service.SetRates(context.Background(), currency.PutRatesRequest{Rates: map[money.Currency]float64{"EUR": 1.2}})
+	assert.ErrorIs(t, err, currency.ErrUnsupportedCurrency)
+
+	_, err = service.SetRates(context.Background(), currency.PutRatesRequest{Rates: map[money.Currency]float64{"usd": 1.2}})
+	assert.ErrorIs(t, err, currency.ErrUnsupportedCurrency)
+
+	_, err = service.SetRates(context.Background(), currency.PutRatesRequest{Rates: map[money.Currency]float64{"USD": 0}})
+	assert.ErrorIs(t, err, currency.ErrInvalidRate)
+
+	mockStore.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
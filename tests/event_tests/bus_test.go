@@ -0,0 +1,168 @@
+package event_tests
+
+import (
+	"context"
+	"errors"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/event"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	pinged event.Name = "test.pinged"
+	ponged event.Name = "test.ponged"
+)
+
+type ping struct {
+	Sequence int
+}
+
+func (ping) EventName() event.Name {
+	return pinged
+}
+
+type pong struct{}
+
+func (pong) EventName() event.Name {
+	return ponged
+}
+
+func newBus() event.IBus {
+	return event.NewBus(&configuration.Configuration{EventWorkers: 2, EventQueueSize: 8}, zap.NewNop().Sugar())
+}
+
+func TestPublishSync(t *testing.T) {
+	bus := newBus()
+
+	var calls []string
+	bus.Subscribe("first", pinged, func(ctx context.Context, e event.Event) error {
+		calls = append(calls, "first")
+		return errors.New("index unavailable")
+	})
+	bus.Subscribe("second", pinged, func(ctx context.Context, e event.Event) error {
+		calls = append(calls, "second")
+		return nil
+	})
+	bus.Subscribe("second", ponged, func(ctx context.Context, e event.Event) error {
+		calls = append(calls, "pong")
+		return nil
+	})
+
+	err := bus.Publish(context.Background(), ping{Sequence: 1})
+
+	assert.EqualError(t, err, "index unavailable")
+	assert.Equal(t, []string{"first", "second"}, calls)
+}
+
+func TestPublishTo(t *testing.T) {
+	bus := newBus()
+
+	var calls []string
+	bus.Subscribe("first", pinged, func(ctx context.Context, e event.Event) error {
+		calls = append(calls, "first")
+		return errors.New("index unavailable")
+	})
+	bus.Subscribe("second", pinged, func(ctx context.Context, e event.Event) error {
+		calls = append(calls, "second ping")
+		return nil
+	})
+	bus.Subscribe("second", ponged, func(ctx context.Context, e event.Event) error {
+		calls = append(calls, "second pong")
+		return nil
+	})
+
+	assert.Equal(t, []event.Subscriber{"first", "second"}, bus.Subscribers(ping{}, pong{}))
+	assert.Equal(t, []event.Subscriber{"second"}, bus.Subscribers(pong{}))
+
+	err := bus.PublishTo(context.Background(), "second", ping{}, pong{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"second ping", "second pong"}, calls)
+}
+
+func TestPublishWithoutSubscribers(t *testing.T) {
+	err := newBus().Publish(context.Background(), ping{}, pong{})
+
+	assert.NoError(t, err)
+}
+
+func TestPublishAsync(t *testing.T) {
+	bus := newBus()
+
+	var (
+		mu        sync.Mutex
+		sequences []int
+		wg        sync.WaitGroup
+	)
+	wg.Add(3)
+	bus.SubscribeAsync(pinged, func(ctx context.Context, e event.Event) error {
+		defer wg.Done()
+		mu.Lock()
+		defer mu.Unlock()
+		sequences = append(sequences, e.(ping).Sequence)
+		return errors.New("failures are only logged")
+	})
+
+	err := bus.Publish(context.Background(), ping{Sequence: 1}, ping{Sequence: 2}, ping{Sequence: 3})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bus.Run(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("async subscriber was not called")
+	}
+	assert.ElementsMatch(t, []int{1, 2, 3}, sequences)
+}
+
+func TestPublishAsyncRecoversPanic(t *testing.T) {
+	bus := newBus()
+
+	delivered := make(chan struct{})
+	bus.SubscribeAsync(pinged, func(ctx context.Context, e event.Event) error {
+		panic("subscriber bug")
+	})
+	bus.SubscribeAsync(ponged, func(ctx context.Context, e event.Event) error {
+		close(delivered)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bus.Run(ctx)
+
+	assert.NoError(t, bus.Publish(context.Background(), ping{}, pong{}))
+
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		t.Fatal("worker stopped after a panic")
+	}
+}
+
+func TestPublishFullQueue(t *testing.T) {
+	bus := event.NewBus(&configuration.Configuration{EventQueueSize: 1}, zap.NewNop().Sugar())
+	bus.SubscribeAsync(pinged, func(ctx context.Context, e event.Event) error {
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := bus.Publish(ctx, ping{Sequence: 1}, ping{Sequence: 2})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,172 @@
+package eventstream_tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-redis/redismock/v9"
+	"github.com/p4xx07/order-service/pkg/eventstream"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+const (
+	stream = "events"
+	group  = "audit"
+	worker = "audit-1"
+)
+
+var occurredAt = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func newEnvelope(t *testing.T, id string) (eventstream.Envelope, string) {
+	envelope, err := eventstream.NewEnvelope(id, "order.created", occurredAt, map[string]uint{"id": 42})
+	assert.NoError(t, err)
+
+	raw, err := json.Marshal(envelope)
+	assert.NoError(t, err)
+	return envelope, string(raw)
+}
+
+func newConsumer(client redis.Cmdable, maxDeliveries int64) *eventstream.Consumer {
+	return eventstream.NewConsumer(client, eventstream.ConsumerOptions{
+		Stream:        stream,
+		Group:         group,
+		Consumer:      worker,
+		MaxDeliveries: maxDeliveries,
+	}, zap.NewNop().Sugar())
+}
+
+func pendingArgs() *redis.XPendingExtArgs {
+	return &redis.XPendingExtArgs{Stream: stream, Group: group, Idle: time.Minute, Start: "-", End: "+", Count: 10}
+}
+
+func readArgs() *redis.XReadGroupArgs {
+	return &redis.XReadGroupArgs{Group: group, Consumer: worker, Streams: []string{stream, ">"}, Count: 10, Block: 5 * time.Second}
+}
+
+func TestPublish(t *testing.T) {
+	redisClient, mockClient := redismock.NewClientMock()
+	envelope, raw := newEnvelope(t, "order.created.1")
+
+	mockClient.ExpectXAdd(&redis.XAddArgs{
+		Stream: stream,
+		MaxLen: 1000,
+		Approx: true,
+		Values: []interface{}{"type", "order.created", "envelope", raw},
+	}).SetVal("1-0")
+
+	err := eventstream.NewPublisher(redisClient, stream, 1000).Publish(context.Background(), envelope)
+	assert.NoError(t, err)
+	assert.Equal(t, eventstream.Version, envelope.Version)
+	assert.NoError(t, mockClient.ExpectationsWereMet())
+}
+
+func TestPublishError(t *testing.T) {
+	redisClient, mockClient := redismock.NewClientMock()
+	envelope, raw := newEnvelope(t, "order.created.1")
+
+	mockClient.ExpectXAdd(&redis.XAddArgs{
+		Stream: stream,
+		MaxLen: 1000,
+		Approx: true,
+		Values: []interface{}{"type", "order.created", "envelope", raw},
+	}).SetErr(errors.New("connection refused"))
+
+	err := eventstream.NewPublisher(redisClient, stream, 1000).Publish(context.Background(), envelope)
+	assert.Error(t, err)
+	assert.NoError(t, mockClient.ExpectationsWereMet())
+}
+
+func TestEnsureGroupExists(t *testing.T) {
+	redisClient, mockClient := redismock.NewClientMock()
+	mockClient.ExpectXGroupCreateMkStream(stream, group, "0").SetErr(errors.New("BUSYGROUP Consumer Group name already exists"))
+
+	err := newConsumer(redisClient, 0).EnsureGroup(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, mockClient.ExpectationsWereMet())
+}
+
+func TestPollHandlesAndAcks(t *testing.T) {
+	redisClient, mockClient := redismock.NewClientMock()
+	mockClient.MatchExpectationsInOrder(true)
+	_, raw := newEnvelope(t, "order.created.1")
+
+	mockClient.ExpectXPendingExt(pendingArgs()).SetVal(nil)
+	mockClient.ExpectXReadGroup(readArgs()).SetVal([]redis.XStream{{
+		Stream: stream,
+		Messages: []redis.XMessage{
+			{ID: "1-0", Values: map[string]interface{}{"type": "order.created", "envelope": raw}},
+			{ID: "2-0", Values: map[string]interface{}{"type": "order.created", "envelope": "{"}},
+		},
+	}})
+	mockClient.ExpectXAck(stream, group, "1-0").SetVal(1)
+	mockClient.ExpectXAck(stream, group, "2-0").SetVal(1)
+
+	var received []eventstream.Envelope
+	handled, err := newConsumer(redisClient, 0).Poll(context.Background(), func(ctx context.Context, envelope eventstream.Envelope) error {
+		received = append(received, envelope)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, handled)
+
+	assert.Len(t, received, 1)
+	assert.Equal(t, "order.created.1", received[0].ID)
+	assert.True(t, occurredAt.Equal(received[0].OccurredAt))
+
+	var data map[string]uint
+	assert.NoError(t, received[0].Decode(&data))
+	assert.Equal(t, uint(42), data["id"])
+
+	assert.NoError(t, mockClient.ExpectationsWereMet())
+}
+
+func TestPollHandlerErrorLeavesPending(t *testing.T) {
+	redisClient, mockClient := redismock.NewClientMock()
+	mockClient.MatchExpectationsInOrder(true)
+	_, raw := newEnvelope(t, "order.created.1")
+
+	mockClient.ExpectXPendingExt(pendingArgs()).SetVal(nil)
+	mockClient.ExpectXReadGroup(readArgs()).SetVal([]redis.XStream{{
+		Stream:   stream,
+		Messages: []redis.XMessage{{ID: "1-0", Values: map[string]interface{}{"type": "order.created", "envelope": raw}}},
+	}})
+
+	handled, err := newConsumer(redisClient, 0).Poll(context.Background(), func(ctx context.Context, envelope eventstream.Envelope) error {
+		return errors.New("downstream unavailable")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, handled)
+	assert.NoError(t, mockClient.ExpectationsWereMet())
+}
+
+func TestPollClaimsPending(t *testing.T) {
+	redisClient, mockClient := redismock.NewClientMock()
+	mockClient.MatchExpectationsInOrder(true)
+	_, raw := newEnvelope(t, "order.created.1")
+
+	mockClient.ExpectXPendingExt(pendingArgs()).SetVal([]redis.XPendingExt{
+		{ID: "1-0", Consumer: "audit-2", Idle: 2 * time.Minute, RetryCount: 1},
+		{ID: "2-0", Consumer: "audit-2", Idle: 2 * time.Minute, RetryCount: 3},
+	})
+	mockClient.ExpectXAck(stream, group, "2-0").SetVal(1)
+	mockClient.ExpectXClaim(&redis.XClaimArgs{
+		Stream:   stream,
+		Group:    group,
+		Consumer: worker,
+		MinIdle:  time.Minute,
+		Messages: []string{"1-0"},
+	}).SetVal([]redis.XMessage{{ID: "1-0", Values: map[string]interface{}{"type": "order.created", "envelope": raw}}})
+	mockClient.ExpectXAck(stream, group, "1-0").SetVal(1)
+	mockClient.ExpectXReadGroup(readArgs()).RedisNil()
+
+	handled, err := newConsumer(redisClient, 3).Poll(context.Background(), func(ctx context.Context, envelope eventstream.Envelope) error {
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, handled)
+	assert.NoError(t, mockClient.ExpectationsWereMet())
+}
@@ -0,0 +1,150 @@
+package idempotency_tests
+
+import (
+	"bytes"
+	"github.com/gofiber/fiber/v2"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/idempotency"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newApp(store idempotency.IStore, status int, calls *int) *fiber.App {
+	app := fiber.New()
+	app.Post("/order", fiber.Handler(idempotency.NewMiddleware(store, &configuration.Configuration{}, zap.NewNop().Sugar())), func(c *fiber.Ctx) error {
+		*calls++
+		return c.Status(status).JSON(fiber.Map{"id": *calls})
+	})
+	return app
+}
+
+func post(t *testing.T, app *fiber.App, key string, body string) (*http.Response, string) {
+	req := httptest.NewRequest(http.MethodPost, "/order", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(idempotency.HeaderKey, key)
+	}
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+
+	data, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return resp, string(data)
+}
+
+func TestMiddleware_Replay(t *testing.T) {
+	calls := 0
+	app := newApp(NewMemoryStore(), http.StatusOK, &calls)
+
+	first, firstBody := post(t, app, "key-1", `{"user_id": 1}`)
+	second, secondBody := post(t, app, "key-1", `{"user_id": 1}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusOK, first.StatusCode)
+	assert.Equal(t, http.StatusOK, second.StatusCode)
+	assert.Equal(t, firstBody, secondBody)
+	assert.Equal(t, "true", second.Header.Get(idempotency.HeaderReplayed))
+	assert.Equal(t, "application/json", second.Header.Get("Content-Type"))
+}
+
+func TestMiddleware_KeyReused(t *testing.T) {
+	calls := 0
+	app := newApp(NewMemoryStore(), http.StatusOK, &calls)
+
+	post(t, app, "key-1", `{"user_id": 1}`)
+	resp, _ := post(t, app, "key-1", `{"user_id": 2}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+}
+
+func TestMiddleware_InProgress(t *testing.T) {
+	calls := 0
+	store := NewMemoryStore()
+	app := newApp(store, http.StatusOK, &calls)
+
+	post(t, app, "key-1", `{"user_id": 1}`)
+
+	record := store.records["key-1"]
+	record.Completed = false
+	store.records["key-1"] = record
+
+	resp, _ := post(t, app, "key-1", `{"user_id": 1}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func TestMiddleware_ServerErrorIsNotRemembered(t *testing.T) {
+	calls := 0
+	app := newApp(NewMemoryStore(), http.StatusInternalServerError, &calls)
+
+	post(t, app, "key-1", `{"user_id": 1}`)
+	post(t, app, "key-1", `{"user_id": 1}`)
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestMiddleware_WithoutKey(t *testing.T) {
+	calls := 0
+	app := newApp(NewMemoryStore(), http.StatusOK, &calls)
+
+	post(t, app, "", `{"user_id": 1}`)
+	post(t, app, "", `{"user_id": 1}`)
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestMiddleware_ReleaseKeepsClaimOfAnotherRequest(t *testing.T) {
+	store := NewMemoryStore()
+
+	app := fiber.New()
+	app.Post("/order", fiber.Handler(idempotency.NewMiddleware(store, &configuration.Configuration{}, zap.NewNop().Sugar())), func(c *fiber.Ctx) error {
+		// the claim expired while the request ran and a retry claimed the key
+		store.records["key-1"] = idempotency.Record{RequestHash: "retry", Token: "retry"}
+		return c.SendStatus(http.StatusInternalServerError)
+	})
+
+	post(t, app, "key-1", `{"user_id": 1}`)
+
+	assert.Equal(t, "retry", store.records["key-1"].Token)
+}
+
+func TestMiddleware_CompleteKeepsClaimOfAnotherRequest(t *testing.T) {
+	store := NewMemoryStore()
+
+	app := fiber.New()
+	app.Post("/order", fiber.Handler(idempotency.NewMiddleware(store, &configuration.Configuration{}, zap.NewNop().Sugar())), func(c *fiber.Ctx) error {
+		// the claim expired while the request ran and a retry claimed the key
+		store.records["key-1"] = idempotency.Record{RequestHash: "retry", Token: "retry"}
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	post(t, app, "key-1", `{"user_id": 1}`)
+
+	assert.Equal(t, "retry", store.records["key-1"].Token)
+	assert.False(t, store.records["key-1"].Completed)
+}
+
+func TestMiddleware_RefreshesClaimOfLongRequest(t *testing.T) {
+	store := NewMemoryStore()
+
+	app := fiber.New()
+	app.Post("/order", fiber.Handler(idempotency.NewMiddleware(store, &configuration.Configuration{IdempotencyPendingTTL: 30 * time.Millisecond}, zap.NewNop().Sugar())), func(c *fiber.Ctx) error {
+		time.Sleep(100 * time.Millisecond)
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	post(t, app, "key-1", `{"user_id": 1}`)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	assert.Positive(t, store.refreshes)
+	assert.True(t, store.records["key-1"].Completed)
+}
@@ -0,0 +1,65 @@
+package idempotency_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/internal/idempotency"
+	"strconv"
+	"sync"
+)
+
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]idempotency.Record
+	tokens    int
+	refreshes int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]idempotency.Record{}}
+}
+
+func (m *MemoryStore) Claim(ctx context.Context, key string, requestHash string) (string, *idempotency.Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if record, ok := m.records[key]; ok {
+		return "", &record, nil
+	}
+	m.tokens++
+	token := strconv.Itoa(m.tokens)
+	m.records[key] = idempotency.Record{RequestHash: requestHash, Token: token}
+	return token, nil, nil
+}
+
+func (m *MemoryStore) Complete(ctx context.Context, key string, token string, record idempotency.Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.records[key].Token != token {
+		return idempotency.ErrNotClaimed
+	}
+	record.Completed = true
+	m.records[key] = record
+	return nil
+}
+
+func (m *MemoryStore) Refresh(ctx context.Context, key string, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.records[key].Token != token {
+		return idempotency.ErrNotClaimed
+	}
+	m.refreshes++
+	return nil
+}
+
+func (m *MemoryStore) Release(ctx context.Context, key string, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.records[key].Token == token {
+		delete(m.records, key)
+	}
+	return nil
+}
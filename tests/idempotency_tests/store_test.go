@@ -0,0 +1,53 @@
+package idempotency_tests
+
+import (
+	"context"
+	"github.com/go-redis/redismock/v9"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/idempotency"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+const scriptSha = "^[0-9a-f]{40}$"
+
+func TestStoreRelease(t *testing.T) {
+	redisClient, mockClient := redismock.NewClientMock()
+	mockClient.MatchExpectationsInOrder(true)
+
+	var claimed string
+	mockClient.CustomMatch(func(expected, actual []interface{}) error {
+		claimed = string(actual[2].([]byte))
+		return nil
+	}).ExpectSetNX("idempotency_key_key-1", "", 30*time.Second).SetVal(true)
+
+	store := idempotency.NewStore(redisClient, &configuration.Configuration{IdempotencyPendingTTL: 30 * time.Second})
+
+	token, record, err := store.Claim(context.Background(), "key-1", "hash")
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+	assert.Regexp(t, "^[0-9a-f]{32}$", token)
+	assert.Contains(t, claimed, `"token":"`+token+`"`)
+
+	mockClient.Regexp().ExpectEvalSha(scriptSha, []string{"idempotency_key_key-1"}, regexp.QuoteMeta(token)).SetVal(int64(1))
+
+	err = store.Release(context.Background(), "key-1", token)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mockClient.ExpectationsWereMet())
+}
+
+func TestStoreCompleteChecksToken(t *testing.T) {
+	redisClient, mockClient := redismock.NewClientMock()
+
+	mockClient.Regexp().ExpectEvalSha(scriptSha, []string{"idempotency_key_key-1"}, "token-1", ".*", "86400000").SetVal(int64(0))
+
+	store := idempotency.NewStore(redisClient, &configuration.Configuration{IdempotencyTTL: 24 * time.Hour})
+
+	err := store.Complete(context.Background(), "key-1", "token-1", idempotency.Record{RequestHash: "hash", StatusCode: 201})
+	assert.ErrorIs(t, err, idempotency.ErrNotClaimed)
+
+	assert.NoError(t, mockClient.ExpectationsWereMet())
+}
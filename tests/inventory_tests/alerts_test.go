@@ -0,0 +1,124 @@
+package inventory_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/inventory"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestCommitReservationsPublishesLowStock(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	orderID := uint(7)
+	reference := inventory.Reference{Reason: inventory.ReasonOrderConfirmed, OrderID: orderID}
+	alert := inventory.LowStockAlert{
+		ID:              3,
+		ProductID:       1,
+		Stock:           4,
+		ReorderPoint:    5,
+		ReorderQuantity: 20,
+		Reason:          inventory.ReasonOrderConfirmed,
+		OrderID:         &orderID,
+		Status:          inventory.AlertOpen,
+	}
+	mockStore.On("CommitReservations", mock.Anything, orderID, reference).Return(map[uint]int{1: 2, 3: 1}, []inventory.LowStockAlert{alert}, nil)
+
+	bus := newBus(logger)
+	decreased := record(bus, inventory.EventStockDecreased)
+	low := record(bus, inventory.EventStockLow)
+	service := inventory.NewService(mockStore, bus, &configuration.Configuration{}, logger)
+
+	err := service.CommitReservations(context.Background(), orderID, reference)
+
+	assert.NoError(t, err)
+	assert.Len(t, *decreased, 1)
+	assert.Equal(t, []event.Event{inventory.StockLow{Alert: alert}}, *low)
+	assert.Equal(t, "stock.low.3", inventory.StockLow{Alert: alert}.EventID())
+}
+
+func TestAdjustDamageWithoutAlertPublishesNoLowStock(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	reference := inventory.Reference{Reason: inventory.ReasonDamage}
+	mockStore.On("DecreaseStockBulk", mock.Anything, map[uint]int{1: 3}, reference).Return(nil, nil)
+	mockStore.On("Get", mock.Anything, uint(1)).Return(&inventory.Inventory{ProductID: 1, Stock: 47}, nil)
+
+	bus := newBus(logger)
+	low := record(bus, inventory.EventStockLow)
+	service := inventory.NewService(mockStore, bus, &configuration.Configuration{}, logger)
+
+	_, err := service.Adjust(context.Background(), inventory.AdjustmentRequest{ProductID: 1, Quantity: -3, Reason: inventory.ReasonDamage})
+
+	assert.NoError(t, err)
+	assert.Empty(t, *low)
+}
+
+func TestSetReorder(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	reorderPoint := 10
+	mockStore.On("SetReorder", mock.Anything, uint(1), &reorderPoint, 50).Return(nil)
+	mockStore.On("Get", mock.Anything, uint(1)).Return(&inventory.Inventory{ProductID: 1, Stock: 47, ReorderPoint: &reorderPoint, ReorderQuantity: 50}, nil)
+
+	service := inventory.NewService(mockStore, newBus(logger), &configuration.Configuration{}, logger)
+
+	response, err := service.SetReorder(context.Background(), inventory.ReorderRequest{ProductID: 1, ReorderPoint: &reorderPoint, ReorderQuantity: 50})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &reorderPoint, response.ReorderPoint)
+	assert.Equal(t, 50, response.ReorderQuantity)
+	mockStore.AssertExpectations(t)
+}
+
+func TestSetReorderNegative(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	service := inventory.NewService(mockStore, newBus(logger), &configuration.Configuration{}, logger)
+
+	reorderPoint := -1
+	_, err := service.SetReorder(context.Background(), inventory.ReorderRequest{ProductID: 1, ReorderPoint: &reorderPoint})
+
+	assert.ErrorIs(t, err, inventory.ErrInvalidReorder)
+	mockStore.AssertNotCalled(t, "SetReorder", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListAlerts(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	request := inventory.ListAlertsRequest{Status: inventory.AlertOpen, Limit: 50}
+	mockStore.On("ListAlerts", mock.Anything, request).Return([]inventory.LowStockAlert{
+		{ID: 2, ProductID: 1, Stock: 4, ReorderPoint: 5, Status: inventory.AlertOpen},
+	}, int64(1), nil)
+
+	service := inventory.NewService(mockStore, newBus(logger), &configuration.Configuration{}, logger)
+
+	response, err := service.ListAlerts(context.Background(), inventory.ListAlertsRequest{Status: inventory.AlertOpen})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), response.Total)
+	assert.Equal(t, 50, response.Limit)
+	assert.Equal(t, uint(2), response.Items[0].ID)
+	mockStore.AssertExpectations(t)
+}
+
+func TestListAlertsInvalidStatus(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	service := inventory.NewService(mockStore, newBus(logger), &configuration.Configuration{}, logger)
+
+	_, err := service.ListAlerts(context.Background(), inventory.ListAlertsRequest{Status: "closed"})
+
+	assert.ErrorIs(t, err, inventory.ErrInvalidStatus)
+	mockStore.AssertNotCalled(t, "ListAlerts", mock.Anything, mock.Anything)
+}
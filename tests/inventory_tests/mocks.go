@@ -0,0 +1,74 @@
+package inventory_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/inventory"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockStore struct {
+	mock.Mock
+}
+
+func (m *MockStore) WithTx(tx *gorm.DB) inventory.IStore {
+	return m
+}
+
+func (m *MockStore) Get(ctx context.Context, productID uint) (*inventory.Inventory, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).(*inventory.Inventory), args.Error(1)
+}
+
+func (m *MockStore) GetMultiple(ctx context.Context, productIDs []uint) (map[uint]inventory.Inventory, error) {
+	args := m.Called(ctx, productIDs)
+	return args.Get(0).(map[uint]inventory.Inventory), args.Error(1)
+}
+
+func (m *MockStore) IncreaseStockBulk(ctx context.Context, updates map[uint]int, reference inventory.Reference) error {
+	args := m.Called(ctx, updates, reference)
+	return args.Error(0)
+}
+
+func (m *MockStore) DecreaseStockBulk(ctx context.Context, updates map[uint]int, reference inventory.Reference) ([]inventory.LowStockAlert, error) {
+	args := m.Called(ctx, updates, reference)
+	alerts, _ := args.Get(0).([]inventory.LowStockAlert)
+	return alerts, args.Error(1)
+}
+
+func (m *MockStore) SetStock(ctx context.Context, productID uint, stock int, reference inventory.Reference) (int, error) {
+	args := m.Called(ctx, productID, stock, reference)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockStore) SetReorder(ctx context.Context, productID uint, reorderPoint *int, reorderQuantity int) error {
+	args := m.Called(ctx, productID, reorderPoint, reorderQuantity)
+	return args.Error(0)
+}
+
+func (m *MockStore) ListMovements(ctx context.Context, request inventory.ListMovementsRequest) ([]inventory.InventoryMovement, int64, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).([]inventory.InventoryMovement), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockStore) ListAlerts(ctx context.Context, request inventory.ListAlertsRequest) ([]inventory.LowStockAlert, int64, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).([]inventory.LowStockAlert), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockStore) Reserve(ctx context.Context, orderID uint, quantities map[uint]int) error {
+	args := m.Called(ctx, orderID, quantities)
+	return args.Error(0)
+}
+
+func (m *MockStore) CommitReservations(ctx context.Context, orderID uint, reference inventory.Reference) (map[uint]int, []inventory.LowStockAlert, error) {
+	args := m.Called(ctx, orderID, reference)
+	quantities, _ := args.Get(0).(map[uint]int)
+	alerts, _ := args.Get(1).([]inventory.LowStockAlert)
+	return quantities, alerts, args.Error(2)
+}
+
+func (m *MockStore) ReleaseReservations(ctx context.Context, orderID uint, status inventory.ReservationStatus) error {
+	args := m.Called(ctx, orderID, status)
+	return args.Error(0)
+}
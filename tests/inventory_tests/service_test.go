@@ -0,0 +1,171 @@
+package inventory_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/inventory"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func newBus(logger *zap.SugaredLogger) event.IBus {
+	return event.NewBus(&configuration.Configuration{}, logger)
+}
+
+// record subscribes to name and collects the events published under it.
+func record(bus event.IBus, name event.Name) *[]event.Event {
+	var events []event.Event
+	bus.Subscribe("recorder", name, func(ctx context.Context, e event.Event) error {
+		events = append(events, e)
+		return nil
+	})
+	return &events
+}
+
+func TestAdjustRestock(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	reference := inventory.Reference{Reason: inventory.ReasonRestock, Note: "supplier delivery"}
+	mockStore.On("IncreaseStockBulk", mock.Anything, map[uint]int{1: 25}, reference).Return(nil)
+	mockStore.On("Get", mock.Anything, uint(1)).Return(&inventory.Inventory{ProductID: 1, Stock: 75}, nil)
+
+	bus := newBus(logger)
+	increased := record(bus, inventory.EventStockIncreased)
+	service := inventory.NewService(mockStore, bus, &configuration.Configuration{}, logger)
+
+	response, err := service.Adjust(context.Background(), inventory.AdjustmentRequest{
+		ProductID: 1,
+		Quantity:  25,
+		Reason:    inventory.ReasonRestock,
+		Note:      "supplier delivery",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 75, response.Stock)
+	assert.Equal(t, []event.Event{inventory.StockIncreased{Quantities: map[uint]int{1: 25}, Reference: reference}}, *increased)
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestAdjustDamage(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	reference := inventory.Reference{Reason: inventory.ReasonDamage}
+	mockStore.On("DecreaseStockBulk", mock.Anything, map[uint]int{1: 3}, reference).Return(nil, nil)
+	mockStore.On("Get", mock.Anything, uint(1)).Return(&inventory.Inventory{ProductID: 1, Stock: 47}, nil)
+
+	service := inventory.NewService(mockStore, newBus(logger), &configuration.Configuration{}, logger)
+
+	_, err := service.Adjust(context.Background(), inventory.AdjustmentRequest{
+		ProductID: 1,
+		Quantity:  -3,
+		Reason:    inventory.ReasonDamage,
+	})
+
+	assert.NoError(t, err)
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestAdjustInvalid(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+
+	tests := []struct {
+		name    string
+		request inventory.AdjustmentRequest
+		err     error
+	}{
+		{
+			name:    "order reasons are reserved",
+			request: inventory.AdjustmentRequest{ProductID: 1, Quantity: 1, Reason: inventory.ReasonOrderConfirmed},
+			err:     inventory.ErrInvalidReason,
+		},
+		{
+			name:    "restock must add stock",
+			request: inventory.AdjustmentRequest{ProductID: 1, Quantity: -1, Reason: inventory.ReasonRestock},
+			err:     inventory.ErrInvalidQuantity,
+		},
+		{
+			name:    "damage must remove stock",
+			request: inventory.AdjustmentRequest{ProductID: 1, Quantity: 1, Reason: inventory.ReasonDamage},
+			err:     inventory.ErrInvalidQuantity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockStore)
+			service := inventory.NewService(mockStore, newBus(logger), &configuration.Configuration{}, logger)
+
+			_, err := service.Adjust(context.Background(), tt.request)
+
+			assert.ErrorIs(t, err, tt.err)
+			mockStore.AssertNotCalled(t, "IncreaseStockBulk", mock.Anything, mock.Anything, mock.Anything)
+			mockStore.AssertNotCalled(t, "DecreaseStockBulk", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestSetStock(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	mockStore.On("SetStock", mock.Anything, uint(2), 10, inventory.Reference{Reason: inventory.ReasonCorrection, Note: "stocktake"}).Return(-5, nil)
+	mockStore.On("Get", mock.Anything, uint(2)).Return(&inventory.Inventory{ProductID: 2, Stock: 10}, nil)
+
+	bus := newBus(logger)
+	decreased := record(bus, inventory.EventStockDecreased)
+	increased := record(bus, inventory.EventStockIncreased)
+	service := inventory.NewService(mockStore, bus, &configuration.Configuration{}, logger)
+
+	response, err := service.SetStock(context.Background(), inventory.SetStockRequest{ProductID: 2, Stock: 10, Note: "stocktake"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 10, response.Stock)
+	assert.Equal(t, []event.Event{inventory.StockDecreased{
+		Quantities: map[uint]int{2: 5},
+		Reference:  inventory.Reference{Reason: inventory.ReasonCorrection, Note: "stocktake"},
+	}}, *decreased)
+	assert.Empty(t, *increased)
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestCommitReservationsPublishes(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	reference := inventory.Reference{Reason: inventory.ReasonOrderConfirmed, OrderID: 7}
+	mockStore.On("CommitReservations", mock.Anything, uint(7), reference).Return(map[uint]int{1: 2, 3: 1}, nil, nil)
+
+	bus := newBus(logger)
+	decreased := record(bus, inventory.EventStockDecreased)
+	service := inventory.NewService(mockStore, bus, &configuration.Configuration{}, logger)
+
+	err := service.CommitReservations(context.Background(), 7, reference)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []event.Event{inventory.StockDecreased{Quantities: map[uint]int{1: 2, 3: 1}, Reference: reference}}, *decreased)
+}
+
+func TestCommitReservationsFailedPublishesNothing(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	reference := inventory.Reference{Reason: inventory.ReasonOrderConfirmed, OrderID: 7}
+	mockStore.On("CommitReservations", mock.Anything, uint(7), reference).Return(nil, nil, inventory.ErrNoReservation)
+
+	bus := newBus(logger)
+	decreased := record(bus, inventory.EventStockDecreased)
+	service := inventory.NewService(mockStore, bus, &configuration.Configuration{}, logger)
+
+	err := service.CommitReservations(context.Background(), 7, reference)
+
+	assert.ErrorIs(t, err, inventory.ErrNoReservation)
+	assert.Empty(t, *decreased)
+}
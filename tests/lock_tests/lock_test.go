@@ -0,0 +1,139 @@
+package lock_tests
+
+import (
+	"context"
+	"errors"
+	"github.com/go-redis/redismock/v9"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/lock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+const (
+	token       = "^[0-9a-f]{32}$"
+	scriptSha   = "^[0-9a-f]{40}$"
+	ttl         = 5 * time.Second
+	waitTimeout = 200 * time.Millisecond
+	retryDelay  = 10 * time.Millisecond
+)
+
+func newConfiguration(waitTimeout time.Duration) *configuration.Configuration {
+	return &configuration.Configuration{LockTTL: ttl, LockWaitTimeout: waitTimeout, LockRetryDelay: retryDelay}
+}
+
+func TestAcquireSortsKeys(t *testing.T) {
+	redisClient, mockClient := redismock.NewClientMock()
+	mockClient.MatchExpectationsInOrder(true)
+
+	mockClient.Regexp().ExpectSetNX("a", token, ttl).SetVal(true)
+	mockClient.Regexp().ExpectSetNX("b", token, ttl).SetVal(true)
+	mockClient.Regexp().ExpectEvalSha(scriptSha, []string{"a"}, token).SetVal(int64(1))
+	mockClient.Regexp().ExpectEvalSha(scriptSha, []string{"b"}, token).SetVal(int64(1))
+
+	locker := lock.NewLocker(redisClient, newConfiguration(waitTimeout), zap.NewNop().Sugar())
+
+	held, err := locker.Acquire(context.Background(), "b", "a", "b")
+	assert.NoError(t, err)
+
+	err = held.Release(context.Background())
+	assert.NoError(t, err)
+
+	assert.NoError(t, mockClient.ExpectationsWereMet())
+}
+
+func TestAcquireRetriesUntilFree(t *testing.T) {
+	redisClient, mockClient := redismock.NewClientMock()
+	mockClient.MatchExpectationsInOrder(true)
+
+	mockClient.Regexp().ExpectSetNX("a", token, ttl).SetVal(false)
+	mockClient.Regexp().ExpectSetNX("a", token, ttl).SetVal(true)
+
+	locker := lock.NewLocker(redisClient, newConfiguration(waitTimeout), zap.NewNop().Sugar())
+
+	_, err := locker.Acquire(context.Background(), "a")
+	assert.NoError(t, err)
+
+	assert.NoError(t, mockClient.ExpectationsWereMet())
+}
+
+func TestAcquireRollsBackPartialLock(t *testing.T) {
+	redisClient, mockClient := redismock.NewClientMock()
+	mockClient.MatchExpectationsInOrder(true)
+
+	mockClient.Regexp().ExpectSetNX("a", token, ttl).SetVal(true)
+	mockClient.Regexp().ExpectSetNX("b", token, ttl).SetVal(false)
+	mockClient.Regexp().ExpectEvalSha(scriptSha, []string{"a"}, token).SetVal(int64(1))
+
+	locker := lock.NewLocker(redisClient, newConfiguration(0), zap.NewNop().Sugar())
+
+	_, err := locker.Acquire(context.Background(), "a", "b")
+	assert.ErrorIs(t, err, lock.ErrNotAcquired)
+
+	assert.NoError(t, mockClient.ExpectationsWereMet())
+}
+
+func TestReleaseExpiredLock(t *testing.T) {
+	redisClient, mockClient := redismock.NewClientMock()
+
+	mockClient.Regexp().ExpectSetNX("a", token, ttl).SetVal(true)
+	mockClient.Regexp().ExpectEvalSha(scriptSha, []string{"a"}, token).SetVal(int64(0))
+
+	locker := lock.NewLocker(redisClient, newConfiguration(waitTimeout), zap.NewNop().Sugar())
+
+	held, err := locker.Acquire(context.Background(), "a")
+	assert.NoError(t, err)
+
+	err = held.Release(context.Background())
+	assert.ErrorIs(t, err, lock.ErrNotHeld)
+}
+
+func TestReleaseTriesEveryKey(t *testing.T) {
+	redisClient, mockClient := redismock.NewClientMock()
+	mockClient.MatchExpectationsInOrder(true)
+
+	mockClient.Regexp().ExpectSetNX("a", token, ttl).SetVal(true)
+	mockClient.Regexp().ExpectSetNX("b", token, ttl).SetVal(true)
+	mockClient.Regexp().ExpectSetNX("c", token, ttl).SetVal(true)
+	mockClient.Regexp().ExpectEvalSha(scriptSha, []string{"a"}, token).SetErr(errors.New("connection reset"))
+	mockClient.Regexp().ExpectEvalSha(scriptSha, []string{"b"}, token).SetVal(int64(0))
+	mockClient.Regexp().ExpectEvalSha(scriptSha, []string{"c"}, token).SetVal(int64(1))
+
+	locker := lock.NewLocker(redisClient, newConfiguration(waitTimeout), zap.NewNop().Sugar())
+
+	held, err := locker.Acquire(context.Background(), "a", "b", "c")
+	assert.NoError(t, err)
+
+	err = held.Release(context.Background())
+	assert.ErrorContains(t, err, "connection reset")
+	assert.ErrorIs(t, err, lock.ErrNotHeld)
+
+	assert.NoError(t, mockClient.ExpectationsWereMet())
+}
+
+func TestLockLostWhenTakenOver(t *testing.T) {
+	redisClient, mockClient := redismock.NewClientMock()
+	mockClient.MatchExpectationsInOrder(true)
+
+	shortTTL := 30 * time.Millisecond
+	mockClient.Regexp().ExpectSetNX("a", token, shortTTL).SetVal(true)
+	// the key expired and went to someone else, so it cannot be renewed
+	mockClient.Regexp().ExpectEvalSha(scriptSha, []string{"a"}, token, "30").SetVal(int64(0))
+
+	configuration := &configuration.Configuration{LockTTL: shortTTL, LockWaitTimeout: waitTimeout, LockRetryDelay: retryDelay}
+	locker := lock.NewLocker(redisClient, configuration, zap.NewNop().Sugar())
+
+	held, err := locker.Acquire(context.Background(), "a")
+	assert.NoError(t, err)
+	assert.NoError(t, held.Err())
+
+	select {
+	case <-held.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("lock was not reported lost")
+	}
+	assert.ErrorIs(t, held.Err(), lock.ErrLost)
+	assert.NoError(t, mockClient.ExpectationsWereMet())
+}
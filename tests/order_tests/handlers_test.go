@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"github.com/gofiber/fiber/v2"
 	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -45,12 +48,14 @@ func TestHandler_Post(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-	var response order.CreateOrderResponse
+	var response struct {
+		Data order.CreateOrderResponse `json:"data"`
+	}
 	err = json.NewDecoder(resp.Body).Decode(&response)
 	if err != nil {
 		t.Fatalf("Error unmarshalling response: %v", err)
 	}
-	assert.Equal(t, expectedResponse.ID, response.ID)
+	assert.Equal(t, expectedResponse.ID, response.Data.ID)
 
 	mockService.AssertExpectations(t)
 }
@@ -64,8 +69,8 @@ func TestHandler_Get(t *testing.T) {
 	expectedResponse := &order.OrderResponse{
 		ID: orderID,
 		Items: []order.OrderItemResponse{
-			{Quantity: 2, Price: 10.0},
-			{Quantity: 1, Price: 20.0},
+			{Quantity: 2, Price: 1000},
+			{Quantity: 1, Price: 2000},
 		},
 	}
 
@@ -81,13 +86,15 @@ func TestHandler_Get(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-	var response order.Order
+	var response struct {
+		Data order.OrderResponse `json:"data"`
+	}
 	err = json.NewDecoder(resp.Body).Decode(&response)
 	if err != nil {
 		t.Fatalf("Error unmarshalling response: %v", err)
 	}
-	assert.Equal(t, expectedResponse.ID, response.ID)
-	assert.Equal(t, len(expectedResponse.Items), len(response.Items))
+	assert.Equal(t, expectedResponse.ID, response.Data.ID)
+	assert.Equal(t, len(expectedResponse.Items), len(response.Data.Items))
 
 	mockService.AssertExpectations(t)
 }
@@ -147,3 +154,195 @@ func TestHandler_Delete(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+func TestHandler_Transition(t *testing.T) {
+	mockService := new(MockService)
+	logger := zap.NewNop().Sugar()
+	handler := order.NewHandler(mockService, logger)
+
+	request := order.TransitionRequest{ID: 1, Status: order.StatusShipped, Client: true}
+	mockService.On("Transition", mock.Anything, request).
+		Return((*order.OrderResponse)(nil), &order.TransitionError{From: order.StatusPending, To: order.StatusShipped})
+
+	app := fiber.New()
+	app.Post("/orders/:id/transitions", handler.Transition)
+
+	jRequest, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("Error marshalling request: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/orders/1/transitions", bytes.NewReader(jRequest))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_List(t *testing.T) {
+	mockService := new(MockService)
+	logger := zap.NewNop().Sugar()
+	handler := order.NewHandler(mockService, logger)
+
+	mockService.On("List", mock.Anything, order.ListRequest{Input: "laptop", Limit: 10}).
+		Return(&order.ListOrdersResponse{}, order.SearchBackendDatabase, nil)
+
+	app := fiber.New()
+	app.Get("/orders", handler.List)
+
+	req := httptest.NewRequest(http.MethodGet, "/orders?input=laptop&limit=10", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "database", resp.Header.Get(order.HeaderSearchBackend))
+
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_ListFilters(t *testing.T) {
+	mockService := new(MockService)
+	logger := zap.NewNop().Sugar()
+	handler := order.NewHandler(mockService, logger)
+
+	minTotal := money.Amount(5050)
+	maxItems := 3
+	mockService.On("List", mock.Anything, order.ListRequest{
+		UserID:        1,
+		Status:        order.StatusPaid,
+		ProductIDs:    []uint{2, 3},
+		MinTotal:      &minTotal,
+		MaxItems:      &maxItems,
+		SortBy:        order.SortTotal,
+		SortDirection: order.SortDesc,
+	}).Return(&order.ListOrdersResponse{}, order.SearchBackendMeilisearch, nil)
+
+	app := fiber.New()
+	app.Get("/orders", handler.List)
+
+	req := httptest.NewRequest(http.MethodGet, "/orders?user_id=1&status=paid&product_ids=2,3&min_total=5050&max_items=3&sort=total:desc", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_ListSortDirection(t *testing.T) {
+	mockService := new(MockService)
+	logger := zap.NewNop().Sugar()
+	handler := order.NewHandler(mockService, logger)
+
+	mockService.On("List", mock.Anything, order.ListRequest{SortDirection: order.SortAsc}).Return(&order.ListOrdersResponse{}, order.SearchBackendDatabase, nil)
+
+	app := fiber.New()
+	app.Get("/orders", handler.List)
+
+	req := httptest.NewRequest(http.MethodGet, "/orders?sort=asc", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_ListInvalidFilters(t *testing.T) {
+	mockService := new(MockService)
+	logger := zap.NewNop().Sugar()
+	handler := order.NewHandler(mockService, logger)
+
+	app := fiber.New()
+	app.Get("/orders", handler.List)
+
+	for _, query := range []string{
+		"sort=price",
+		"sort=total:up",
+		"status=unknown",
+		"product_ids=1,a",
+		"min_total=20&max_total=10",
+		"min_items=-1",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/orders?"+query, nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+	}
+
+	mockService.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestHandler_ListInvalidCursor(t *testing.T) {
+	mockService := new(MockService)
+	logger := zap.NewNop().Sugar()
+	handler := order.NewHandler(mockService, logger)
+
+	mockService.On("List", mock.Anything, order.ListRequest{Cursor: "bogus"}).Return(nil, order.SearchBackend(""), order.ErrInvalidCursor)
+
+	app := fiber.New()
+	app.Get("/orders", handler.List)
+
+	req := httptest.NewRequest(http.MethodGet, "/orders?cursor=bogus", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAdminHandler_ListOutbox(t *testing.T) {
+	mockOutboxStore := new(MockOutboxStore)
+	logger := zap.NewNop().Sugar()
+	handler := order.NewAdminHandler(order.NewAdminService(mockOutboxStore, nil, &configuration.Configuration{}, logger), logger)
+
+	mockOutboxStore.On("List", mock.Anything, order.OutboxDead, 20, 0).Return([]order.OutboxEvent{
+		{ID: 7, OrderID: 1, Type: order.OutboxOrderUpdated, Status: order.OutboxDead, Attempts: 10, LastError: "meilisearch unavailable"},
+	}, int64(1), nil)
+
+	app := fiber.New()
+	app.Get("/admin/order/outbox", handler.ListOutbox)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/order/outbox", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Data order.ListOutboxResponse `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		t.Fatalf("Error unmarshalling response: %v", err)
+	}
+	assert.Equal(t, int64(1), response.Data.Total)
+	assert.Equal(t, uint(7), response.Data.Items[0].ID)
+	assert.Equal(t, "meilisearch unavailable", response.Data.Items[0].LastError)
+
+	mockOutboxStore.AssertExpectations(t)
+}
+
+func TestAdminHandler_RetryOutboxNotFound(t *testing.T) {
+	mockOutboxStore := new(MockOutboxStore)
+	logger := zap.NewNop().Sugar()
+	handler := order.NewAdminHandler(order.NewAdminService(mockOutboxStore, nil, &configuration.Configuration{}, logger), logger)
+
+	mockOutboxStore.On("Retry", mock.Anything, uint(7)).Return(gorm.ErrRecordNotFound)
+
+	app := fiber.New()
+	app.Post("/admin/order/outbox/:id/retry", handler.RetryOutbox)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/order/outbox/7/retry", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	mockOutboxStore.AssertExpectations(t)
+}
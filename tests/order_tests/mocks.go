@@ -2,17 +2,99 @@ package order_tests
 
 import (
 	"context"
+	"github.com/p4xx07/order-service/app/domains/currency"
 	"github.com/p4xx07/order-service/app/domains/inventory"
 	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/app/domains/promotion"
+	"github.com/p4xx07/order-service/app/domains/tax"
+	"github.com/p4xx07/order-service/app/domains/user"
+	"github.com/p4xx07/order-service/internal/lock"
+	"github.com/p4xx07/order-service/internal/money"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"time"
 )
 
+type MockTransactor struct{}
+
+func (m *MockTransactor) Transaction(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error) error {
+	return fn(ctx, nil)
+}
+
+type MockLocker struct {
+	mock.Mock
+}
+
+func (m *MockLocker) Acquire(ctx context.Context, keys ...string) (lock.ILock, error) {
+	args := m.Called(ctx, keys)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(lock.ILock), args.Error(1)
+}
+
+type MockLock struct {
+	mock.Mock
+	lost chan struct{}
+}
+
+// lose makes the lock report it was lost.
+func (m *MockLock) lose() {
+	m.lost = make(chan struct{})
+	close(m.lost)
+}
+
+func (m *MockLock) Lost() <-chan struct{} {
+	return m.lost
+}
+
+func (m *MockLock) Err() error {
+	if m.lost != nil {
+		return lock.ErrLost
+	}
+	return nil
+}
+
+func (m *MockLock) Refresh(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockLock) Release(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 type MockStore struct {
 	mock.Mock
 }
 
-func (m *MockStore) Fetch(size int, offset int) ([]order.Order, error) {
-	args := m.Called(size, offset)
+func (m *MockStore) List(ctx context.Context, request order.ListRequest) ([]order.Order, int64, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).([]order.Order), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockStore) ListAfter(ctx context.Context, after order.Cursor, limit int) ([]order.Order, error) {
+	args := m.Called(ctx, after, limit)
+	return args.Get(0).([]order.Order), args.Error(1)
+}
+
+func (m *MockStore) ListUpdatedSince(ctx context.Context, since time.Time, afterID uint, limit int) ([]order.Order, error) {
+	args := m.Called(ctx, since, afterID, limit)
+	return args.Get(0).([]order.Order), args.Error(1)
+}
+
+func (m *MockStore) WithTx(tx *gorm.DB) order.IStore {
+	return m
+}
+
+func (m *MockStore) ListByUser(ctx context.Context, userID uint, limit int, offset int) ([]order.Order, int64, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	return args.Get(0).([]order.Order), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockStore) ListExpired(ctx context.Context, now time.Time, limit int) ([]order.Order, error) {
+	args := m.Called(ctx, now, limit)
 	return args.Get(0).([]order.Order), args.Error(1)
 }
 
@@ -21,6 +103,16 @@ func (m *MockStore) Get(ctx context.Context, id uint) (*order.Order, error) {
 	return args.Get(0).(*order.Order), args.Error(1)
 }
 
+func (m *MockStore) GetForUpdate(ctx context.Context, id uint) (*order.Order, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*order.Order), args.Error(1)
+}
+
+func (m *MockStore) UpdateStatus(ctx context.Context, id uint, from order.Status, to order.Status) error {
+	args := m.Called(ctx, id, from, to)
+	return args.Error(0)
+}
+
 func (m *MockStore) Delete(ctx context.Context, id uint) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -31,6 +123,11 @@ func (m *MockStore) DeleteOrderItems(ctx context.Context, orderItemIDs []uint) e
 	return args.Error(0)
 }
 
+func (m *MockStore) DeleteOrderPromotions(ctx context.Context, orderID uint) error {
+	args := m.Called(ctx, orderID)
+	return args.Error(0)
+}
+
 func (m *MockStore) Create(ctx context.Context, order *order.Order) error {
 	args := m.Called(ctx, order)
 	return args.Error(0)
@@ -41,6 +138,59 @@ func (m *MockStore) Update(ctx context.Context, order *order.Order) error {
 	return args.Error(0)
 }
 
+type MockOutboxStore struct {
+	mock.Mock
+}
+
+func (m *MockOutboxStore) Add(ctx context.Context, event *order.OutboxEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockOutboxStore) ClaimDue(ctx context.Context, now time.Time, limit int, leaseUntil time.Time) ([]order.OutboxEvent, error) {
+	args := m.Called(ctx, now, limit, leaseUntil)
+	return args.Get(0).([]order.OutboxEvent), args.Error(1)
+}
+
+func (m *MockOutboxStore) List(ctx context.Context, status order.OutboxStatus, limit int, offset int) ([]order.OutboxEvent, int64, error) {
+	args := m.Called(ctx, status, limit, offset)
+	return args.Get(0).([]order.OutboxEvent), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockOutboxStore) ListDeletedSince(ctx context.Context, since time.Time, afterID uint, limit int) ([]order.OutboxEvent, error) {
+	args := m.Called(ctx, since, afterID, limit)
+	return args.Get(0).([]order.OutboxEvent), args.Error(1)
+}
+
+func (m *MockOutboxStore) Update(ctx context.Context, event *order.OutboxEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockOutboxStore) Retry(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOutboxStore) WithTx(tx *gorm.DB) order.IOutboxStore {
+	return m
+}
+
+// outboxEvent matches a pending outbox event queued for the given order.
+func outboxEvent(orderID uint, eventType order.OutboxEventType) interface{} {
+	return mock.MatchedBy(func(event *order.OutboxEvent) bool {
+		return event.OrderID == orderID && event.Type == eventType && event.Status == order.OutboxPending
+	})
+}
+
+// statusOutboxEvent matches a pending outbox event queued for the order moving from one status to another.
+func statusOutboxEvent(orderID uint, from order.Status, to order.Status) interface{} {
+	return mock.MatchedBy(func(event *order.OutboxEvent) bool {
+		return event.OrderID == orderID && event.Type == order.OutboxOrderStatusChanged && event.Status == order.OutboxPending &&
+			event.FromStatus == from && event.ToStatus == to
+	})
+}
+
 type MockInventoryService struct {
 	mock.Mock
 }
@@ -50,28 +200,132 @@ func (m *MockInventoryService) Get(ctx context.Context, productID uint) (*invent
 	return args.Get(0).(*inventory.Inventory), args.Error(0)
 }
 
-func (m *MockInventoryService) DecreaseStockBulk(ctx context.Context, updates map[uint]int) error {
-	args := m.Called(ctx, updates)
+func (m *MockInventoryService) WithTx(tx *gorm.DB) inventory.IService {
+	return m
+}
+
+func (m *MockInventoryService) DecreaseStockBulk(ctx context.Context, updates map[uint]int, reference inventory.Reference) error {
+	args := m.Called(ctx, updates, reference)
 	return args.Error(0)
 }
 
-func (m *MockInventoryService) IncreaseStockBulk(ctx context.Context, updates map[uint]int) error {
-	args := m.Called(ctx, updates)
+func (m *MockInventoryService) IncreaseStockBulk(ctx context.Context, updates map[uint]int, reference inventory.Reference) error {
+	args := m.Called(ctx, updates, reference)
 	return args.Error(0)
 }
 
+func (m *MockInventoryService) SetStock(ctx context.Context, request inventory.SetStockRequest) (*inventory.InventoryResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*inventory.InventoryResponse), args.Error(1)
+}
+
+func (m *MockInventoryService) SetReorder(ctx context.Context, request inventory.ReorderRequest) (*inventory.InventoryResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*inventory.InventoryResponse), args.Error(1)
+}
+
+func (m *MockInventoryService) Adjust(ctx context.Context, request inventory.AdjustmentRequest) (*inventory.InventoryResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*inventory.InventoryResponse), args.Error(1)
+}
+
+func (m *MockInventoryService) ListMovements(ctx context.Context, request inventory.ListMovementsRequest) (*inventory.ListMovementsResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*inventory.ListMovementsResponse), args.Error(1)
+}
+
+func (m *MockInventoryService) ListAlerts(ctx context.Context, request inventory.ListAlertsRequest) (*inventory.ListAlertsResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*inventory.ListAlertsResponse), args.Error(1)
+}
+
 func (m *MockInventoryService) GetMultiple(ctx context.Context, ids []uint) (map[uint]inventory.Inventory, error) {
 	args := m.Called(ctx, ids)
 	return args.Get(0).(map[uint]inventory.Inventory), args.Error(1)
 }
 
+func (m *MockInventoryService) Reserve(ctx context.Context, orderID uint, quantities map[uint]int) error {
+	args := m.Called(ctx, orderID, quantities)
+	return args.Error(0)
+}
+
+func (m *MockInventoryService) CommitReservations(ctx context.Context, orderID uint, reference inventory.Reference) error {
+	args := m.Called(ctx, orderID, reference)
+	return args.Error(0)
+}
+
+func (m *MockInventoryService) ReleaseReservations(ctx context.Context, orderID uint, status inventory.ReservationStatus) error {
+	args := m.Called(ctx, orderID, status)
+	return args.Error(0)
+}
+
+type MockUserService struct {
+	mock.Mock
+}
+
+func (m *MockUserService) List(ctx context.Context, request user.ListRequest) (*user.ListUsersResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*user.ListUsersResponse), args.Error(1)
+}
+
+func (m *MockUserService) Get(ctx context.Context, id uint) (*user.UserResponse, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*user.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) Create(ctx context.Context, request user.PostRequest) (*user.CreateUserResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*user.CreateUserResponse), args.Error(1)
+}
+
+func (m *MockUserService) Update(ctx context.Context, request user.PutRequest) (*user.UserResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*user.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserService) CreateAddress(ctx context.Context, request user.AddressRequest) (*user.AddressResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*user.AddressResponse), args.Error(1)
+}
+
+func (m *MockUserService) GetAddress(ctx context.Context, userID uint, addressID uint) (*user.AddressResponse, error) {
+	args := m.Called(ctx, userID, addressID)
+	address, _ := args.Get(0).(*user.AddressResponse)
+	return address, args.Error(1)
+}
+
+func (m *MockUserService) ListAddresses(ctx context.Context, userID uint) (*user.ListAddressesResponse, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(*user.ListAddressesResponse), args.Error(1)
+}
+
+func (m *MockUserService) DeleteAddress(ctx context.Context, userID uint, addressID uint) error {
+	args := m.Called(ctx, userID, addressID)
+	return args.Error(0)
+}
+
 type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) List(ctx context.Context, request order.ListRequest) (interface{}, error) {
+func (m *MockService) WithTx(tx *gorm.DB) order.IService {
+	return m
+}
+
+func (m *MockService) List(ctx context.Context, request order.ListRequest) (*order.ListOrdersResponse, order.SearchBackend, error) {
 	args := m.Called(ctx, request)
-	return args.Get(0).(interface{}), args.Error(1)
+	response, _ := args.Get(0).(*order.ListOrdersResponse)
+	return response, args.Get(1).(order.SearchBackend), args.Error(2)
+}
+
+func (m *MockService) ListByUser(ctx context.Context, request order.ListByUserRequest) (*order.ListOrdersResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*order.ListOrdersResponse), args.Error(1)
 }
 
 func (m *MockService) Create(ctx context.Context, request order.PostRequest) (*order.CreateOrderResponse, error) {
@@ -94,13 +348,19 @@ func (m *MockService) Delete(ctx context.Context, id uint) error {
 	return args.Error(0)
 }
 
+func (m *MockService) Transition(ctx context.Context, request order.TransitionRequest) (*order.OrderResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*order.OrderResponse), args.Error(1)
+}
+
 type MockMeilisearchService struct {
 	mock.Mock
 }
 
-func (m *MockMeilisearchService) List(ctx context.Context, request order.ListRequest) (interface{}, error) {
+func (m *MockMeilisearchService) List(ctx context.Context, request order.ListRequest) (*order.ListOrdersResponse, error) {
 	args := m.Called(ctx, request)
-	return args.Get(0).(interface{}), args.Error(1)
+	response, _ := args.Get(0).(*order.ListOrdersResponse)
+	return response, args.Error(1)
 }
 
 func (m *MockMeilisearchService) Update(orders order.Order) error {
@@ -117,3 +377,133 @@ func (m *MockMeilisearchService) Delete(orderIDs ...uint) error {
 	args := m.Called(orderIDs)
 	return args.Error(0)
 }
+
+func (m *MockMeilisearchService) IsEmpty(ctx context.Context) (bool, error) {
+	args := m.Called(ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMeilisearchService) PrepareIndex(ctx context.Context, uid string) error {
+	args := m.Called(ctx, uid)
+	return args.Error(0)
+}
+
+func (m *MockMeilisearchService) IndexOrders(ctx context.Context, uid string, orders []order.Order) error {
+	args := m.Called(ctx, uid, orders)
+	return args.Error(0)
+}
+
+func (m *MockMeilisearchService) PromoteIndex(ctx context.Context, uid string) error {
+	args := m.Called(ctx, uid)
+	return args.Error(0)
+}
+
+type MockWatermarkStore struct {
+	mock.Mock
+}
+
+func (m *MockWatermarkStore) Get(ctx context.Context, index string) (*order.ReindexWatermark, error) {
+	args := m.Called(ctx, index)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*order.ReindexWatermark), args.Error(1)
+}
+
+func (m *MockWatermarkStore) Save(ctx context.Context, watermark *order.ReindexWatermark) error {
+	args := m.Called(ctx, watermark)
+	return args.Error(0)
+}
+
+type MockCurrencyService struct {
+	mock.Mock
+}
+
+// newCurrencyService prices orders in EUR, the base currency.
+func newCurrencyService() *MockCurrencyService {
+	m := new(MockCurrencyService)
+	m.On("Base").Return(money.Currency("EUR")).Maybe()
+	m.On("Rate", mock.Anything, money.Currency("EUR")).Return(1.0, nil).Maybe()
+	return m
+}
+
+func (m *MockCurrencyService) ListRates(ctx context.Context) (*currency.ListRatesResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*currency.ListRatesResponse), args.Error(1)
+}
+
+func (m *MockCurrencyService) SetRates(ctx context.Context, request currency.PutRatesRequest) (*currency.ListRatesResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*currency.ListRatesResponse), args.Error(1)
+}
+
+func (m *MockCurrencyService) Rate(ctx context.Context, code money.Currency) (float64, error) {
+	args := m.Called(ctx, code)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockCurrencyService) Base() money.Currency {
+	args := m.Called()
+	return args.Get(0).(money.Currency)
+}
+
+type MockPromotionService struct {
+	mock.Mock
+}
+
+func (m *MockPromotionService) WithTx(tx *gorm.DB) promotion.IService {
+	return m
+}
+
+func (m *MockPromotionService) List(ctx context.Context, request promotion.ListRequest) (*promotion.ListPromotionsResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*promotion.ListPromotionsResponse), args.Error(1)
+}
+
+func (m *MockPromotionService) Get(ctx context.Context, id uint) (*promotion.PromotionResponse, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*promotion.PromotionResponse), args.Error(1)
+}
+
+func (m *MockPromotionService) Create(ctx context.Context, request promotion.PostRequest) (*promotion.CreatePromotionResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*promotion.CreatePromotionResponse), args.Error(1)
+}
+
+func (m *MockPromotionService) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPromotionService) Apply(ctx context.Context, request promotion.ApplyRequest) ([]promotion.Discount, error) {
+	args := m.Called(ctx, request)
+	discounts, _ := args.Get(0).([]promotion.Discount)
+	return discounts, args.Error(1)
+}
+
+func (m *MockPromotionService) Redeem(ctx context.Context, promotionIDs []uint) error {
+	args := m.Called(ctx, promotionIDs)
+	return args.Error(0)
+}
+
+func (m *MockPromotionService) Release(ctx context.Context, promotionIDs []uint) error {
+	args := m.Called(ctx, promotionIDs)
+	return args.Error(0)
+}
+
+// untaxed charges no tax on any line.
+type untaxed struct{}
+
+func (untaxed) Calculate(ctx context.Context, request tax.CalculateRequest) ([]tax.LineTax, error) {
+	return make([]tax.LineTax, len(request.Lines)), nil
+}
+
+type MockTaxCalculator struct {
+	mock.Mock
+}
+
+func (m *MockTaxCalculator) Calculate(ctx context.Context, request tax.CalculateRequest) ([]tax.LineTax, error) {
+	args := m.Called(ctx, request)
+	taxes, _ := args.Get(0).([]tax.LineTax)
+	return taxes, args.Error(1)
+}
@@ -0,0 +1,178 @@
+package order_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/lock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestReindexFull(t *testing.T) {
+	mockStore := new(MockStore)
+	mockWatermarkStore := new(MockWatermarkStore)
+	mockMeilisearchService := new(MockMeilisearchService)
+	logger := zap.NewNop().Sugar()
+
+	createdAt := time.Date(2025, 3, 29, 12, 30, 0, 0, time.UTC)
+	firstBatch := []order.Order{{ID: 1, CreatedAt: createdAt}, {ID: 2, CreatedAt: createdAt}}
+	mockMeilisearchService.On("PrepareIndex", mock.Anything, "orders_reindex").Return(nil)
+	mockStore.On("ListAfter", mock.Anything, order.Cursor{}, mock.Anything).Return(firstBatch, nil)
+	mockStore.On("ListAfter", mock.Anything, order.Cursor{CreatedAt: createdAt, ID: 2}, mock.Anything).Return([]order.Order{}, nil)
+	mockMeilisearchService.On("IndexOrders", mock.Anything, "orders_reindex", firstBatch).Return(nil)
+	mockMeilisearchService.On("PromoteIndex", mock.Anything, "orders_reindex").Return(nil)
+
+	watermark := &order.ReindexWatermark{Index: "orders"}
+	mockWatermarkStore.On("Save", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		*watermark = *args.Get(1).(*order.ReindexWatermark)
+	}).Return(nil)
+	mockWatermarkStore.On("Get", mock.Anything, "orders").Return(watermark, nil)
+
+	// the catch up after the swap starts shortly before the reindex started
+	mockStore.On("ListUpdatedSince", mock.Anything, mock.MatchedBy(func(since time.Time) bool {
+		return since.Before(time.Now().Add(-50 * time.Second))
+	}), uint(0), mock.Anything).Return([]order.Order{}, nil)
+
+	mockLock := new(MockLock)
+	mockLock.On("Release", mock.Anything).Return(nil)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"reindex_orders"}).Return(mockLock, nil)
+
+	// an order deleted while the shadow index was filling is removed from the index swapped in
+	deletedAt := time.Now()
+	mockOutboxStore := new(MockOutboxStore)
+	mockOutboxStore.On("ListDeletedSince", mock.Anything, mock.Anything, uint(0), mock.Anything).Return([]order.OutboxEvent{{ID: 9, OrderID: 2, Type: order.OutboxOrderDeleted, CreatedAt: deletedAt}}, nil)
+	mockOutboxStore.On("ListDeletedSince", mock.Anything, deletedAt, uint(9), mock.Anything).Return([]order.OutboxEvent{}, nil)
+	mockMeilisearchService.On("Delete", []uint{2}).Return(nil)
+
+	reindexer := order.NewReindexer(mockStore, mockOutboxStore, mockWatermarkStore, mockMeilisearchService, mockLocker, &configuration.Configuration{}, logger)
+
+	progress, err := reindexer.Reindex(context.Background(), order.ReindexFull)
+
+	assert.NoError(t, err)
+	assert.Equal(t, order.ReindexCompleted, progress.State)
+	assert.Equal(t, 2, progress.Indexed)
+	assert.NotNil(t, progress.FinishedAt)
+
+	mockStore.AssertExpectations(t)
+	mockMeilisearchService.AssertExpectations(t)
+	mockWatermarkStore.AssertExpectations(t)
+	mockLock.AssertExpectations(t)
+}
+
+func TestReindexIncremental(t *testing.T) {
+	mockStore := new(MockStore)
+	mockWatermarkStore := new(MockWatermarkStore)
+	mockMeilisearchService := new(MockMeilisearchService)
+	logger := zap.NewNop().Sugar()
+
+	updatedAt := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	batch := []order.Order{{ID: 3, UpdatedAt: updatedAt}, {ID: 1, UpdatedAt: updatedAt.Add(time.Second)}}
+
+	mockWatermarkStore.On("Get", mock.Anything, "orders").Return((*order.ReindexWatermark)(nil), gorm.ErrRecordNotFound)
+	mockStore.On("ListUpdatedSince", mock.Anything, time.Time{}, uint(0), mock.Anything).Return(batch, nil)
+	mockStore.On("ListUpdatedSince", mock.Anything, updatedAt.Add(time.Second), uint(1), mock.Anything).Return([]order.Order{}, nil)
+	mockMeilisearchService.On("IndexOrders", mock.Anything, "orders", batch).Return(nil)
+	mockWatermarkStore.On("Save", mock.Anything, &order.ReindexWatermark{Index: "orders", SyncedAt: updatedAt.Add(time.Second)}).Return(nil)
+
+	mockLock := new(MockLock)
+	mockLock.On("Release", mock.Anything).Return(nil)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"reindex_orders"}).Return(mockLock, nil)
+
+	mockOutboxStore := new(MockOutboxStore)
+	mockOutboxStore.On("ListDeletedSince", mock.Anything, time.Time{}, uint(0), mock.Anything).Return([]order.OutboxEvent{}, nil)
+
+	reindexer := order.NewReindexer(mockStore, mockOutboxStore, mockWatermarkStore, mockMeilisearchService, mockLocker, &configuration.Configuration{}, logger)
+
+	progress, err := reindexer.Reindex(context.Background(), order.ReindexIncremental)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, progress.Indexed)
+
+	mockStore.AssertExpectations(t)
+	mockMeilisearchService.AssertExpectations(t)
+	mockWatermarkStore.AssertExpectations(t)
+}
+
+func TestReindexInProgress(t *testing.T) {
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"reindex_orders"}).Return(nil, lock.ErrNotAcquired)
+
+	reindexer := order.NewReindexer(new(MockStore), new(MockOutboxStore), new(MockWatermarkStore), new(MockMeilisearchService), mockLocker, &configuration.Configuration{}, zap.NewNop().Sugar())
+
+	_, err := reindexer.Start(order.ReindexFull)
+
+	assert.ErrorIs(t, err, order.ErrReindexInProgress)
+	assert.Nil(t, reindexer.Progress())
+}
+
+func TestReindexIncrementalRemovesDeleted(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOutboxStore := new(MockOutboxStore)
+	mockWatermarkStore := new(MockWatermarkStore)
+	mockMeilisearchService := new(MockMeilisearchService)
+	logger := zap.NewNop().Sugar()
+
+	syncedAt := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	since := syncedAt.Add(-time.Minute)
+	deletedAt := syncedAt.Add(time.Hour)
+	mockWatermarkStore.On("Get", mock.Anything, "orders").Return(&order.ReindexWatermark{Index: "orders", SyncedAt: syncedAt}, nil)
+	mockOutboxStore.On("ListDeletedSince", mock.Anything, since, uint(0), mock.Anything).Return([]order.OutboxEvent{
+		{ID: 4, OrderID: 3, Type: order.OutboxOrderDeleted, CreatedAt: deletedAt},
+		{ID: 6, OrderID: 5, Type: order.OutboxOrderDeleted, CreatedAt: deletedAt},
+	}, nil)
+	mockOutboxStore.On("ListDeletedSince", mock.Anything, deletedAt, uint(6), mock.Anything).Return([]order.OutboxEvent{}, nil)
+	mockMeilisearchService.On("Delete", []uint{3, 5}).Return(nil)
+
+	// orders changed after the run started do not move the watermark past it
+	updatedAt := time.Now().Add(time.Hour)
+	batch := []order.Order{{ID: 7, UpdatedAt: updatedAt}}
+	mockStore.On("ListUpdatedSince", mock.Anything, since, uint(0), mock.Anything).Return(batch, nil)
+	mockStore.On("ListUpdatedSince", mock.Anything, updatedAt, uint(7), mock.Anything).Return([]order.Order{}, nil)
+	mockMeilisearchService.On("IndexOrders", mock.Anything, "orders", batch).Return(nil)
+	mockWatermarkStore.On("Save", mock.Anything, mock.MatchedBy(func(watermark *order.ReindexWatermark) bool {
+		return watermark.SyncedAt.Before(time.Now())
+	})).Return(nil)
+
+	mockLock := new(MockLock)
+	mockLock.On("Release", mock.Anything).Return(nil)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"reindex_orders"}).Return(mockLock, nil)
+
+	reindexer := order.NewReindexer(mockStore, mockOutboxStore, mockWatermarkStore, mockMeilisearchService, mockLocker, &configuration.Configuration{}, logger)
+
+	_, err := reindexer.Reindex(context.Background(), order.ReindexIncremental)
+
+	assert.NoError(t, err)
+	mockOutboxStore.AssertExpectations(t)
+	mockMeilisearchService.AssertExpectations(t)
+	mockWatermarkStore.AssertExpectations(t)
+}
+
+func TestReindexFullStopsOnLostLock(t *testing.T) {
+	mockStore := new(MockStore)
+	mockMeilisearchService := new(MockMeilisearchService)
+
+	mockMeilisearchService.On("PrepareIndex", mock.Anything, "orders_reindex").Return(nil)
+	mockStore.On("ListAfter", mock.Anything, order.Cursor{}, mock.Anything).Return([]order.Order{}, nil)
+
+	mockLock := new(MockLock)
+	mockLock.lose()
+	mockLock.On("Release", mock.Anything).Return(lock.ErrNotHeld)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"reindex_orders"}).Return(mockLock, nil)
+
+	reindexer := order.NewReindexer(mockStore, new(MockOutboxStore), new(MockWatermarkStore), mockMeilisearchService, mockLocker, &configuration.Configuration{}, zap.NewNop().Sugar())
+
+	progress, err := reindexer.Reindex(context.Background(), order.ReindexFull)
+
+	assert.ErrorIs(t, err, lock.ErrLost)
+	assert.Equal(t, order.ReindexFailed, progress.State)
+	mockMeilisearchService.AssertNotCalled(t, "PromoteIndex", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,231 @@
+package order_tests
+
+import (
+	"context"
+	"errors"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func newRelayConfiguration() *configuration.Configuration {
+	return &configuration.Configuration{OutboxRetryDelay: time.Second, OutboxMaxAttempts: 3}
+}
+
+// newIndexedBus returns a bus the search indexer subscribed to, as the app wires it.
+func newIndexedBus(meilisearchService order.IMeilisearchService, logger *zap.SugaredLogger) event.IBus {
+	bus := event.NewBus(newRelayConfiguration(), logger)
+	order.NewSearchIndexer(meilisearchService).Subscribe(bus)
+	return bus
+}
+
+func TestRelay(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOutboxStore := new(MockOutboxStore)
+	mockMeilisearchService := new(MockMeilisearchService)
+	logger := zap.NewNop().Sugar()
+
+	mockOutboxStore.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]order.OutboxEvent{
+		{ID: 1, OrderID: 1, Type: order.OutboxOrderCreated, Status: order.OutboxPending},
+		{ID: 2, OrderID: 2, Type: order.OutboxOrderUpdated, Status: order.OutboxPending},
+		{ID: 3, OrderID: 3, Type: order.OutboxOrderDeleted, Status: order.OutboxPending},
+	}, nil)
+
+	mockStore.On("Get", mock.Anything, uint(1)).Return(&order.Order{ID: 1}, nil)
+	mockStore.On("Get", mock.Anything, uint(2)).Return(&order.Order{}, gorm.ErrRecordNotFound)
+	mockMeilisearchService.On("Add", order.Order{ID: 1}).Return(nil)
+	mockMeilisearchService.On("Delete", []uint{2}).Return(nil)
+	mockMeilisearchService.On("Delete", []uint{3}).Return(nil)
+
+	mockOutboxStore.On("Update", mock.Anything, mock.MatchedBy(func(event *order.OutboxEvent) bool {
+		return event.Status == order.OutboxDelivered && event.DeliveredAt != nil
+	})).Return(nil).Times(3)
+
+	relay := order.NewOutboxRelay(mockOutboxStore, mockStore, &MockTransactor{}, newIndexedBus(mockMeilisearchService, logger), newRelayConfiguration(), logger)
+
+	delivered, err := relay.Relay(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, delivered)
+
+	mockStore.AssertExpectations(t)
+	mockOutboxStore.AssertExpectations(t)
+	mockMeilisearchService.AssertExpectations(t)
+}
+
+func TestRelayRetry(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOutboxStore := new(MockOutboxStore)
+	mockMeilisearchService := new(MockMeilisearchService)
+	logger := zap.NewNop().Sugar()
+
+	mockOutboxStore.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]order.OutboxEvent{
+		{ID: 1, OrderID: 1, Type: order.OutboxOrderDeleted, Status: order.OutboxPending, Attempts: 1, Deliveries: map[event.Subscriber]*order.OutboxDelivery{
+			order.SubscriberSearchIndex: {Status: order.OutboxPending, Attempts: 1},
+		}},
+		{ID: 2, OrderID: 2, Type: order.OutboxOrderDeleted, Status: order.OutboxPending, Attempts: 2, Deliveries: map[event.Subscriber]*order.OutboxDelivery{
+			order.SubscriberSearchIndex: {Status: order.OutboxPending, Attempts: 2},
+		}},
+	}, nil)
+	mockMeilisearchService.On("Delete", mock.Anything).Return(errors.New("meilisearch unavailable"))
+
+	var updated []order.OutboxEvent
+	mockOutboxStore.On("Update", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		updated = append(updated, *args.Get(1).(*order.OutboxEvent))
+	}).Return(nil)
+
+	relay := order.NewOutboxRelay(mockOutboxStore, mockStore, &MockTransactor{}, newIndexedBus(mockMeilisearchService, logger), newRelayConfiguration(), logger)
+
+	delivered, err := relay.Relay(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Len(t, updated, 2)
+
+	assert.Equal(t, order.OutboxPending, updated[0].Status)
+	assert.Equal(t, 2, updated[0].Attempts)
+	assert.Equal(t, "search_index: meilisearch unavailable", updated[0].LastError)
+	assert.WithinDuration(t, time.Now().Add(2*time.Second), updated[0].NextAttemptAt, time.Second)
+
+	assert.Equal(t, order.OutboxDead, updated[1].Status)
+	assert.Equal(t, 3, updated[1].Attempts)
+}
+
+func TestRelayStatusChanged(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOutboxStore := new(MockOutboxStore)
+	mockMeilisearchService := new(MockMeilisearchService)
+	logger := zap.NewNop().Sugar()
+
+	mockOutboxStore.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]order.OutboxEvent{
+		{ID: 4, OrderID: 1, Type: order.OutboxOrderStatusChanged, FromStatus: order.StatusPaid, ToStatus: order.StatusShipped, Status: order.OutboxPending},
+	}, nil)
+	mockOutboxStore.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	ord := order.Order{ID: 1, Status: order.StatusDelivered}
+	mockStore.On("Get", mock.Anything, uint(1)).Return(&ord, nil)
+	mockMeilisearchService.On("Update", ord).Return(nil)
+
+	bus := newIndexedBus(mockMeilisearchService, logger)
+	var changed []event.Event
+	bus.Subscribe("recorder", order.EventOrderStatusChanged, func(ctx context.Context, e event.Event) error {
+		changed = append(changed, e)
+		return nil
+	})
+
+	relay := order.NewOutboxRelay(mockOutboxStore, mockStore, &MockTransactor{}, bus, newRelayConfiguration(), logger)
+
+	delivered, err := relay.Relay(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, []event.Event{order.OrderStatusChanged{
+		OutboxID: 4,
+		Order:    ord,
+		From:     order.StatusPaid,
+		To:       order.StatusShipped,
+	}}, changed)
+
+	mockMeilisearchService.AssertExpectations(t)
+}
+
+// recordingTransactor reports whether a transaction is open.
+type recordingTransactor struct {
+	open bool
+}
+
+func (r *recordingTransactor) Transaction(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error) error {
+	r.open = true
+	defer func() { r.open = false }()
+	return fn(ctx, nil)
+}
+
+func TestRelayDeliversOutsideTransaction(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOutboxStore := new(MockOutboxStore)
+	mockMeilisearchService := new(MockMeilisearchService)
+	logger := zap.NewNop().Sugar()
+	transactor := &recordingTransactor{}
+
+	start := time.Now()
+	mockOutboxStore.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything, mock.MatchedBy(func(leaseUntil time.Time) bool {
+		return leaseUntil.After(start)
+	})).Return([]order.OutboxEvent{
+		{ID: 1, OrderID: 1, Type: order.OutboxOrderDeleted, Status: order.OutboxPending},
+	}, nil)
+
+	deliveredInTransaction := false
+	mockMeilisearchService.On("Delete", []uint{1}).Run(func(args mock.Arguments) {
+		deliveredInTransaction = transactor.open
+	}).Return(nil)
+
+	recordedInTransaction := false
+	mockOutboxStore.On("Update", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		recordedInTransaction = transactor.open
+	}).Return(nil)
+
+	relay := order.NewOutboxRelay(mockOutboxStore, mockStore, transactor, newIndexedBus(mockMeilisearchService, logger), newRelayConfiguration(), logger)
+
+	delivered, err := relay.Relay(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.False(t, deliveredInTransaction)
+	assert.True(t, recordedInTransaction)
+	mockOutboxStore.AssertExpectations(t)
+}
+
+func TestRelayRetriesSubscribersApart(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOutboxStore := new(MockOutboxStore)
+	mockMeilisearchService := new(MockMeilisearchService)
+	logger := zap.NewNop().Sugar()
+
+	outboxEvent := order.OutboxEvent{ID: 1, OrderID: 1, Type: order.OutboxOrderDeleted, Status: order.OutboxPending}
+	mockOutboxStore.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]order.OutboxEvent{outboxEvent}, nil).Once()
+	mockMeilisearchService.On("Delete", []uint{1}).Return(errors.New("meilisearch unavailable"))
+
+	var updated []order.OutboxEvent
+	mockOutboxStore.On("Update", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		updated = append(updated, *args.Get(1).(*order.OutboxEvent))
+	}).Return(nil)
+
+	bus := newIndexedBus(mockMeilisearchService, logger)
+	var deleted []event.Event
+	bus.Subscribe("recorder", order.EventOrderDeleted, func(ctx context.Context, e event.Event) error {
+		deleted = append(deleted, e)
+		return nil
+	})
+
+	relay := order.NewOutboxRelay(mockOutboxStore, mockStore, &MockTransactor{}, bus, newRelayConfiguration(), logger)
+
+	delivered, err := relay.Relay(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Len(t, deleted, 1)
+	assert.Equal(t, order.OutboxPending, updated[0].Status)
+	assert.Equal(t, order.OutboxDelivered, updated[0].Deliveries["recorder"].Status)
+	assert.Equal(t, 1, updated[0].Deliveries[order.SubscriberSearchIndex].Attempts)
+
+	// the search index runs out of attempts on its own, the other subscriber does not get the event again
+	retried := updated[0]
+	retried.Deliveries[order.SubscriberSearchIndex].Attempts = 2
+	retried.Deliveries[order.SubscriberSearchIndex].NextAttemptAt = time.Now()
+	mockOutboxStore.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]order.OutboxEvent{retried}, nil).Once()
+
+	delivered, err = relay.Relay(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Len(t, deleted, 1)
+	assert.Equal(t, order.OutboxDead, updated[1].Status)
+	assert.Equal(t, order.OutboxDead, updated[1].Deliveries[order.SubscriberSearchIndex].Status)
+	assert.Equal(t, order.OutboxDelivered, updated[1].Deliveries["recorder"].Status)
+}
@@ -2,13 +2,22 @@ package order_tests
 
 import (
 	"context"
-	"github.com/go-redis/redismock/v9"
+	"errors"
+	"fmt"
+	"github.com/p4xx07/order-service/app/domains/currency"
 	"github.com/p4xx07/order-service/app/domains/inventory"
 	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/app/domains/product"
+	"github.com/p4xx07/order-service/app/domains/promotion"
+	"github.com/p4xx07/order-service/app/domains/tax"
+	"github.com/p4xx07/order-service/app/domains/user"
 	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/lock"
+	"github.com/p4xx07/order-service/internal/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 	"testing"
 	"time"
 )
@@ -17,32 +26,37 @@ func TestDelete(t *testing.T) {
 	mockStore := new(MockStore)
 	mockInventoryService := new(MockInventoryService)
 	mockMeilisearchService := new(MockMeilisearchService)
+	mockOutboxStore := new(MockOutboxStore)
 
 	logger := zap.NewNop().Sugar()
 
 	orderID := uint(1)
 	ord := &order.Order{
-		ID: orderID,
+		ID:     orderID,
+		Status: order.StatusConfirmed,
 		Items: []order.OrderItem{
-			{ID: 1, ProductID: 1, Quantity: 2, Price: 10.0},
-			{ID: 2, ProductID: 2, Quantity: 1, Price: 20.0},
+			{ID: 1, ProductID: 1, Quantity: 2, Price: 1000},
+			{ID: 2, ProductID: 2, Quantity: 1, Price: 2000},
 		},
 	}
 
+	locked := *ord
 	mockStore.On("Get", mock.Anything, orderID).Return(ord, nil)
+	mockStore.On("GetForUpdate", mock.Anything, orderID).Return(&locked, nil)
 
 	mockStore.On("DeleteOrderItems", mock.Anything, []uint{1, 2}).Return(nil)
 	mockStore.On("Delete", mock.Anything, mock.Anything).Return(nil)
 
-	mockInventoryService.On("IncreaseStockBulk", mock.Anything, mock.Anything).Return(nil)
+	mockInventoryService.On("IncreaseStockBulk", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-	mockRedisClient, mockClient := redismock.NewClientMock()
+	mockOutboxStore.On("Add", mock.Anything, outboxEvent(orderID, order.OutboxOrderDeleted)).Return(nil)
 
-	mockClient.ExpectSetNX("stock_lock_product_1", "locked", 5*time.Second).SetVal(true)
-	mockClient.ExpectSetNX("stock_lock_product_2", "locked", 5*time.Second).SetVal(true)
-	mockClient.ExpectDel(mock.Anything).RedisNil()
+	mockLock := new(MockLock)
+	mockLock.On("Release", mock.Anything).Return(nil)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"stock_lock_product_1", "stock_lock_product_2"}).Return(mockLock, nil)
 
-	service := order.NewService(mockMeilisearchService, mockRedisClient, &configuration.Configuration{}, logger, mockStore, mockInventoryService)
+	service := order.NewService(mockMeilisearchService, mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, new(MockUserService), &MockTransactor{}, mockOutboxStore, newCurrencyService(), new(MockPromotionService), untaxed{})
 
 	err := service.Delete(context.Background(), orderID)
 
@@ -50,40 +64,112 @@ func TestDelete(t *testing.T) {
 
 	mockStore.AssertExpectations(t)
 	mockInventoryService.AssertExpectations(t)
+	mockLock.AssertExpectations(t)
+	mockOutboxStore.AssertExpectations(t)
+}
+
+func TestDeleteOrderChanged(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockMeilisearchService := new(MockMeilisearchService)
+	mockOutboxStore := new(MockOutboxStore)
+	logger := zap.NewNop().Sugar()
+
+	orderID := uint(1)
+	items := []order.OrderItem{
+		{ID: 1, ProductID: 1, Quantity: 2, Price: 1000},
+	}
+	mockStore.On("Get", mock.Anything, orderID).Return(&order.Order{ID: orderID, Status: order.StatusConfirmed, Items: items}, nil)
+	// a concurrent transition cancelled the order and restocked its items first
+	mockStore.On("GetForUpdate", mock.Anything, orderID).Return(&order.Order{ID: orderID, Status: order.StatusCancelled, Items: items}, nil)
+
+	mockLock := new(MockLock)
+	mockLock.On("Release", mock.Anything).Return(nil)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"stock_lock_product_1"}).Return(mockLock, nil)
+
+	service := order.NewService(mockMeilisearchService, mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, new(MockUserService), &MockTransactor{}, mockOutboxStore, newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	err := service.Delete(context.Background(), orderID)
+
+	assert.ErrorIs(t, err, order.ErrOrderChanged)
+
+	mockInventoryService.AssertNotCalled(t, "IncreaseStockBulk", mock.Anything, mock.Anything, mock.Anything)
+	mockStore.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	mockOutboxStore.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+}
+
+func TestDeleteStockLocked(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockMeilisearchService := new(MockMeilisearchService)
+	mockOutboxStore := new(MockOutboxStore)
+	logger := zap.NewNop().Sugar()
+
+	orderID := uint(1)
+	mockStore.On("Get", mock.Anything, orderID).Return(&order.Order{
+		ID:     orderID,
+		Status: order.StatusConfirmed,
+		Items: []order.OrderItem{
+			{ID: 1, ProductID: 1, Quantity: 2, Price: 1000},
+		},
+	}, nil)
+
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"stock_lock_product_1"}).Return(nil, lock.ErrNotAcquired)
+
+	service := order.NewService(mockMeilisearchService, mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, new(MockUserService), &MockTransactor{}, mockOutboxStore, newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	err := service.Delete(context.Background(), orderID)
+
+	assert.ErrorIs(t, err, order.ErrStockUpdateInProgress)
+
+	mockInventoryService.AssertNotCalled(t, "IncreaseStockBulk", mock.Anything, mock.Anything, mock.Anything)
+	mockStore.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	mockOutboxStore.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
 }
 
 func TestUpdate(t *testing.T) {
 	mockStore := new(MockStore)
 	mockInventoryService := new(MockInventoryService)
 	mockMeilisearchService := new(MockMeilisearchService)
+	mockOutboxStore := new(MockOutboxStore)
 	logger := zap.NewNop().Sugar()
 
 	orderID := uint(1)
 	ord := &order.Order{
-		ID: orderID,
+		ID:     orderID,
+		Status: order.StatusPending,
 		Items: []order.OrderItem{
-			{ID: 1, ProductID: 1, Quantity: 2, Price: 10.0},
-			{ID: 2, ProductID: 2, Quantity: 1, Price: 20.0},
+			{ID: 1, ProductID: 1, Quantity: 2, Price: 1000},
+			{ID: 2, ProductID: 2, Quantity: 1, Price: 2000},
 		},
 	}
 
+	locked := *ord
 	mockStore.On("Get", mock.Anything, orderID).Return(ord, nil)
+	mockStore.On("GetForUpdate", mock.Anything, orderID).Return(&locked, nil)
 
+	mockStore.On("DeleteOrderItems", mock.Anything, []uint{1, 2}).Return(nil)
 	mockStore.On("Update", mock.Anything, mock.Anything).Return(nil)
 
-	mockInventoryService.On("IncreaseStockBulk", mock.Anything, mock.Anything).Return(nil)
-	mockInventoryService.On("DecreaseStockBulk", mock.Anything, mock.Anything).Return(nil)
+	mockInventoryService.On("ReleaseReservations", mock.Anything, orderID, inventory.ReservationReleased).Return(nil)
+	mockInventoryService.On("Reserve", mock.Anything, orderID, map[uint]int{1: 3, 2: 2}).Return(nil)
 	mockInventoryService.On("GetMultiple", mock.Anything, mock.Anything).Return(map[uint]inventory.Inventory{
-		1: {Stock: 5},
-		2: {Stock: 5},
+		1: {Stock: 5, Product: product.Product{ID: 1}},
+		2: {Stock: 5, Product: product.Product{ID: 2}},
 	}, nil)
 
-	mockRedisClient, mockClient := redismock.NewClientMock()
-	mockClient.ExpectSetNX("stock_lock_product_1", "locked", 5*time.Second).SetVal(true)
-	mockClient.ExpectSetNX("stock_lock_product_2", "locked", 5*time.Second).SetVal(true)
-	mockClient.ExpectDel(mock.Anything).RedisNil()
+	mockOutboxStore.On("Add", mock.Anything, outboxEvent(orderID, order.OutboxOrderUpdated)).Return(nil)
+
+	mockLock := new(MockLock)
+	mockLock.On("Release", mock.Anything).Return(nil)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, mock.MatchedBy(func(keys []string) bool {
+		return assert.ElementsMatch(t, []string{"stock_lock_product_1", "stock_lock_product_2"}, keys)
+	})).Return(mockLock, nil)
 
-	service := order.NewService(mockMeilisearchService, mockRedisClient, &configuration.Configuration{}, logger, mockStore, mockInventoryService)
+	service := order.NewService(mockMeilisearchService, mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, new(MockUserService), &MockTransactor{}, mockOutboxStore, newCurrencyService(), new(MockPromotionService), untaxed{})
 
 	err := service.Update(context.Background(), order.PutRequest{
 		ID: orderID,
@@ -97,32 +183,41 @@ func TestUpdate(t *testing.T) {
 
 	mockStore.AssertExpectations(t)
 	mockInventoryService.AssertExpectations(t)
+	mockOutboxStore.AssertExpectations(t)
 }
 
 func TestCreate(t *testing.T) {
 	mockStore := new(MockStore)
 	mockInventoryService := new(MockInventoryService)
 	mockMeilisearchService := new(MockMeilisearchService)
+	mockOutboxStore := new(MockOutboxStore)
+	mockUserService := new(MockUserService)
 
 	logger := zap.NewNop().Sugar()
 
-	mockStore.On("Create", mock.Anything, mock.Anything).Return(nil)
-	mockInventoryService.On("DecreaseStockBulk", mock.Anything, mock.Anything).Return(nil)
+	mockUserService.On("Get", mock.Anything, uint(1)).Return(&user.UserResponse{ID: 1}, nil)
+	mockStore.On("Create", mock.Anything, mock.MatchedBy(func(o *order.Order) bool {
+		return o.Subtotal == 2999 && o.Total == 2999 && o.Items[0].Total == 2500 && o.Items[1].Total == 499
+	})).Return(nil)
+	mockInventoryService.On("Reserve", mock.Anything, mock.Anything, map[uint]int{1: 2, 2: 1}).Return(nil)
 
 	mockInventoryService.On("GetMultiple", mock.Anything, mock.Anything).Return(map[uint]inventory.Inventory{
-		1: {Stock: 10},
-		2: {Stock: 10},
+		1: {Stock: 10, Product: product.Product{ID: 1, Price: 1250}},
+		2: {Stock: 10, Product: product.Product{ID: 2, Price: 499}},
 	}, nil)
 
 	// Mock Redis client
-	mockRedisClient, mockClient := redismock.NewClientMock()
-	mockClient.ExpectSetNX("stock_lock_product_1", "locked", 5*time.Second).SetVal(true)
-	mockClient.ExpectSetNX("stock_lock_product_2", "locked", 5*time.Second).SetVal(true)
-	mockClient.ExpectDel(mock.Anything).RedisNil()
+	mockOutboxStore.On("Add", mock.Anything, outboxEvent(0, order.OutboxOrderCreated)).Return(nil)
+
+	mockLock := new(MockLock)
+	mockLock.On("Release", mock.Anything).Return(nil)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"stock_lock_product_1", "stock_lock_product_2"}).Return(mockLock, nil)
 
-	service := order.NewService(mockMeilisearchService, mockRedisClient, &configuration.Configuration{}, logger, mockStore, mockInventoryService)
+	service := order.NewService(mockMeilisearchService, mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, mockUserService, &MockTransactor{}, mockOutboxStore, newCurrencyService(), new(MockPromotionService), untaxed{})
 
 	_, err := service.Create(context.Background(), order.PostRequest{
+		UserID: 1,
 		Items: []order.OrderItemRequest{
 			{ProductID: 1, Quantity: 2},
 			{ProductID: 2, Quantity: 1},
@@ -133,4 +228,800 @@ func TestCreate(t *testing.T) {
 
 	mockStore.AssertExpectations(t)
 	mockInventoryService.AssertExpectations(t)
+	mockOutboxStore.AssertExpectations(t)
+}
+
+func TestCreateInCurrency(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockOutboxStore := new(MockOutboxStore)
+	mockUserService := new(MockUserService)
+	mockCurrencyService := new(MockCurrencyService)
+	logger := zap.NewNop().Sugar()
+
+	mockUserService.On("Get", mock.Anything, uint(1)).Return(&user.UserResponse{ID: 1}, nil)
+	mockCurrencyService.On("Rate", mock.Anything, money.Currency("USD")).Return(1.08, nil)
+	mockInventoryService.On("GetMultiple", mock.Anything, mock.Anything).Return(map[uint]inventory.Inventory{
+		1: {Stock: 10, Product: product.Product{ID: 1, Price: 1250}},
+	}, nil)
+	mockInventoryService.On("Reserve", mock.Anything, mock.Anything, map[uint]int{1: 2}).Return(nil)
+	mockOutboxStore.On("Add", mock.Anything, outboxEvent(0, order.OutboxOrderCreated)).Return(nil)
+
+	// the price is converted from the base currency and the rate is kept with the order
+	mockStore.On("Create", mock.Anything, mock.MatchedBy(func(o *order.Order) bool {
+		return o.Currency == "USD" && o.ExchangeRate == 1.08 && o.Items[0].Price == 1350 && o.Total == 2700
+	})).Return(nil)
+
+	mockLock := new(MockLock)
+	mockLock.On("Release", mock.Anything).Return(nil)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"stock_lock_product_1"}).Return(mockLock, nil)
+
+	service := order.NewService(new(MockMeilisearchService), mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, mockUserService, &MockTransactor{}, mockOutboxStore, mockCurrencyService, new(MockPromotionService), untaxed{})
+
+	_, err := service.Create(context.Background(), order.PostRequest{
+		UserID:   1,
+		Currency: "USD",
+		Items:    []order.OrderItemRequest{{ProductID: 1, Quantity: 2}},
+	})
+
+	assert.NoError(t, err)
+
+	mockStore.AssertExpectations(t)
+	mockCurrencyService.AssertExpectations(t)
+}
+
+func TestCreateWithCoupon(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockOutboxStore := new(MockOutboxStore)
+	mockUserService := new(MockUserService)
+	mockPromotionService := new(MockPromotionService)
+	logger := zap.NewNop().Sugar()
+
+	mockUserService.On("Get", mock.Anything, uint(1)).Return(&user.UserResponse{ID: 1}, nil)
+	mockInventoryService.On("GetMultiple", mock.Anything, mock.Anything).Return(map[uint]inventory.Inventory{
+		1: {Stock: 10, Product: product.Product{ID: 1, Price: 1250}},
+	}, nil)
+	mockInventoryService.On("Reserve", mock.Anything, mock.Anything, map[uint]int{1: 2}).Return(nil)
+	mockOutboxStore.On("Add", mock.Anything, outboxEvent(0, order.OutboxOrderCreated)).Return(nil)
+	mockPromotionService.On("Apply", mock.Anything, promotion.ApplyRequest{
+		Codes: []string{"SPRING10"},
+		Cart:  promotion.Cart{ExchangeRate: 1, Lines: []promotion.Line{{ProductID: 1, Quantity: 2, Price: 1250}}},
+	}).Return([]promotion.Discount{{PromotionID: 7, Code: "SPRING10", Amount: 250}}, nil)
+	mockPromotionService.On("Redeem", mock.Anything, []uint{7}).Return(nil)
+
+	// the discount is stored with the order and taken off its total
+	mockStore.On("Create", mock.Anything, mock.MatchedBy(func(o *order.Order) bool {
+		return len(o.Promotions) == 1 && o.Promotions[0].Code == "SPRING10" && o.Subtotal == 2500 && o.Discount == 250 && o.Total == 2250
+	})).Return(nil)
+
+	mockLock := new(MockLock)
+	mockLock.On("Release", mock.Anything).Return(nil)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"stock_lock_product_1"}).Return(mockLock, nil)
+
+	service := order.NewService(new(MockMeilisearchService), mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, mockUserService, &MockTransactor{}, mockOutboxStore, newCurrencyService(), mockPromotionService, untaxed{})
+
+	_, err := service.Create(context.Background(), order.PostRequest{
+		UserID:  1,
+		Coupons: []string{"SPRING10"},
+		Items:   []order.OrderItemRequest{{ProductID: 1, Quantity: 2}},
+	})
+
+	assert.NoError(t, err)
+
+	mockStore.AssertExpectations(t)
+	mockPromotionService.AssertExpectations(t)
+}
+
+func TestCreateWithTax(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockOutboxStore := new(MockOutboxStore)
+	mockUserService := new(MockUserService)
+	mockPromotionService := new(MockPromotionService)
+	mockTaxCalculator := new(MockTaxCalculator)
+	logger := zap.NewNop().Sugar()
+
+	mockUserService.On("Get", mock.Anything, uint(1)).Return(&user.UserResponse{ID: 1}, nil)
+	mockInventoryService.On("GetMultiple", mock.Anything, mock.Anything).Return(map[uint]inventory.Inventory{
+		1: {Stock: 10, Product: product.Product{ID: 1, Price: 1250, Category: "Electronics"}},
+		2: {Stock: 10, Product: product.Product{ID: 2, Price: 500, Category: "Books"}},
+	}, nil)
+	mockInventoryService.On("Reserve", mock.Anything, mock.Anything, map[uint]int{1: 2, 2: 1}).Return(nil)
+	mockOutboxStore.On("Add", mock.Anything, outboxEvent(0, order.OutboxOrderCreated)).Return(nil)
+	mockPromotionService.On("Apply", mock.Anything, mock.Anything).Return([]promotion.Discount{{PromotionID: 7, Code: "SPRING10", Amount: 300}}, nil)
+	mockPromotionService.On("Redeem", mock.Anything, []uint{7}).Return(nil)
+
+	// the discount is spread over the items in proportion to their totals before they are taxed
+	mockTaxCalculator.On("Calculate", mock.Anything, tax.CalculateRequest{
+		Region: "IT",
+		Lines:  []tax.Line{{Category: "Electronics", Amount: 2250}, {Category: "Books", Amount: 450}},
+	}).Return([]tax.LineTax{
+		{Percent: 22, Amount: 495},
+		{Percent: 4, Inclusive: true, Amount: 17},
+	}, nil)
+
+	// only the exclusive tax is added to the total
+	mockStore.On("Create", mock.Anything, mock.MatchedBy(func(o *order.Order) bool {
+		return o.Region == "IT" && o.Items[0].Tax == 495 && o.Items[1].TaxInclusive && o.Tax == 512 && o.Total == 3000-300+495
+	})).Return(nil)
+
+	mockLock := new(MockLock)
+	mockLock.On("Release", mock.Anything).Return(nil)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"stock_lock_product_1", "stock_lock_product_2"}).Return(mockLock, nil)
+
+	service := order.NewService(new(MockMeilisearchService), mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, mockUserService, &MockTransactor{}, mockOutboxStore, newCurrencyService(), mockPromotionService, mockTaxCalculator)
+
+	_, err := service.Create(context.Background(), order.PostRequest{
+		UserID:  1,
+		Region:  "IT",
+		Coupons: []string{"SPRING10"},
+		Items:   []order.OrderItemRequest{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}},
+	})
+
+	assert.NoError(t, err)
+
+	mockStore.AssertExpectations(t)
+	mockTaxCalculator.AssertExpectations(t)
+}
+
+func TestCreateWithInvalidCoupon(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockUserService := new(MockUserService)
+	mockPromotionService := new(MockPromotionService)
+	logger := zap.NewNop().Sugar()
+
+	mockUserService.On("Get", mock.Anything, uint(1)).Return(&user.UserResponse{ID: 1}, nil)
+	mockInventoryService.On("GetMultiple", mock.Anything, mock.Anything).Return(map[uint]inventory.Inventory{
+		1: {Stock: 10, Product: product.Product{ID: 1, Price: 1250}},
+	}, nil)
+	mockPromotionService.On("Apply", mock.Anything, mock.Anything).Return(nil, promotion.ErrPromotionExhausted)
+
+	mockLock := new(MockLock)
+	mockLock.On("Release", mock.Anything).Return(nil)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"stock_lock_product_1"}).Return(mockLock, nil)
+
+	service := order.NewService(new(MockMeilisearchService), mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, mockUserService, &MockTransactor{}, new(MockOutboxStore), newCurrencyService(), mockPromotionService, untaxed{})
+
+	_, err := service.Create(context.Background(), order.PostRequest{
+		UserID:  1,
+		Coupons: []string{"GONE"},
+		Items:   []order.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+	})
+
+	assert.ErrorIs(t, err, promotion.ErrPromotionExhausted)
+	mockStore.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockPromotionService.AssertNotCalled(t, "Redeem", mock.Anything, mock.Anything)
+}
+
+func TestCreateWithAddress(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockOutboxStore := new(MockOutboxStore)
+	mockUserService := new(MockUserService)
+	logger := zap.NewNop().Sugar()
+
+	mockUserService.On("Get", mock.Anything, uint(1)).Return(&user.UserResponse{ID: 1}, nil)
+	mockUserService.On("GetAddress", mock.Anything, uint(1), uint(4)).Return(&user.AddressResponse{
+		ID: 4, UserID: 1, Name: "Ada Lovelace", Line1: "Via Roma 1", City: "Milano", PostalCode: "20121", Country: "IT",
+	}, nil)
+	mockInventoryService.On("GetMultiple", mock.Anything, mock.Anything).Return(map[uint]inventory.Inventory{
+		1: {Stock: 10, Product: product.Product{ID: 1, Price: 1250}},
+	}, nil)
+	mockInventoryService.On("Reserve", mock.Anything, mock.Anything, map[uint]int{1: 1}).Return(nil)
+	mockStore.On("Create", mock.Anything, mock.MatchedBy(func(o *order.Order) bool {
+		return o.Region == "IT" && o.ShippingAddress.City == "Milano" && o.ShippingAddress.Country == "IT"
+	})).Return(nil)
+	mockOutboxStore.On("Add", mock.Anything, mock.Anything).Return(nil)
+
+	mockLock := new(MockLock)
+	mockLock.On("Release", mock.Anything).Return(nil)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"stock_lock_product_1"}).Return(mockLock, nil)
+
+	service := order.NewService(new(MockMeilisearchService), mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, mockUserService, &MockTransactor{}, mockOutboxStore, newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	response, err := service.Create(context.Background(), order.PostRequest{
+		UserID:    1,
+		AddressID: 4,
+		Items:     []order.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+	})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, response)
+	mockStore.AssertExpectations(t)
+}
+
+func TestCreateAddressNotFound(t *testing.T) {
+	mockStore := new(MockStore)
+	mockUserService := new(MockUserService)
+	logger := zap.NewNop().Sugar()
+
+	mockUserService.On("Get", mock.Anything, uint(1)).Return(&user.UserResponse{ID: 1}, nil)
+	mockUserService.On("GetAddress", mock.Anything, uint(1), uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+	service := order.NewService(new(MockMeilisearchService), new(MockLocker), &configuration.Configuration{}, logger, mockStore, new(MockInventoryService), mockUserService, &MockTransactor{}, new(MockOutboxStore), newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	_, err := service.Create(context.Background(), order.PostRequest{
+		UserID:    1,
+		AddressID: 9,
+		Items:     []order.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+	})
+
+	assert.ErrorIs(t, err, order.ErrAddressNotFound)
+	mockStore.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateUnsupportedCurrency(t *testing.T) {
+	mockStore := new(MockStore)
+	mockUserService := new(MockUserService)
+	mockCurrencyService := new(MockCurrencyService)
+	logger := zap.NewNop().Sugar()
+
+	mockUserService.On("Get", mock.Anything, uint(1)).Return(&user.UserResponse{ID: 1}, nil)
+	mockCurrencyService.On("Rate", mock.Anything, money.Currency("JPY")).Return(0.0, currency.ErrUnsupportedCurrency)
+
+	service := order.NewService(new(MockMeilisearchService), new(MockLocker), &configuration.Configuration{}, logger, mockStore, new(MockInventoryService), mockUserService, &MockTransactor{}, new(MockOutboxStore), mockCurrencyService, new(MockPromotionService), untaxed{})
+
+	_, err := service.Create(context.Background(), order.PostRequest{
+		UserID:   1,
+		Currency: "JPY",
+		Items:    []order.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+	})
+
+	assert.ErrorIs(t, err, currency.ErrUnsupportedCurrency)
+	mockStore.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUpdateConfirmedKeepsReservedStock(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockOutboxStore := new(MockOutboxStore)
+	logger := zap.NewNop().Sugar()
+
+	orderID := uint(1)
+	mockStore.On("Get", mock.Anything, orderID).Return(&order.Order{
+		ID:     orderID,
+		Status: order.StatusConfirmed,
+		Items: []order.OrderItem{
+			{ID: 1, ProductID: 1, Quantity: 2, Price: 1000},
+		},
+	}, nil)
+	// 4 of the 5 units in stock are reserved for pending orders
+	mockInventoryService.On("GetMultiple", mock.Anything, mock.Anything).Return(map[uint]inventory.Inventory{
+		1: {Stock: 5, Reserved: 4, Product: product.Product{ID: 1}},
+	}, nil)
+
+	mockLock := new(MockLock)
+	mockLock.On("Release", mock.Anything).Return(nil)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"stock_lock_product_1"}).Return(mockLock, nil)
+
+	service := order.NewService(new(MockMeilisearchService), mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, new(MockUserService), &MockTransactor{}, mockOutboxStore, newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	err := service.Update(context.Background(), order.PutRequest{
+		ID:    orderID,
+		Items: []order.OrderItemRequest{{ProductID: 1, Quantity: 4}},
+	})
+
+	assert.ErrorIs(t, err, order.ErrNoStockAvailable)
+
+	mockInventoryService.AssertNotCalled(t, "DecreaseStockBulk", mock.Anything, mock.Anything, mock.Anything)
+	mockStore.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestDuplicateProductRejected(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	logger := zap.NewNop().Sugar()
+
+	service := order.NewService(new(MockMeilisearchService), new(MockLocker), &configuration.Configuration{}, logger, mockStore, mockInventoryService, new(MockUserService), &MockTransactor{}, new(MockOutboxStore), newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	items := []order.OrderItemRequest{{ProductID: 1, Quantity: 1}, {ProductID: 1, Quantity: 2}}
+
+	_, err := service.Create(context.Background(), order.PostRequest{UserID: 1, Items: items})
+	assert.ErrorIs(t, err, order.ErrDuplicateProduct)
+
+	err = service.Update(context.Background(), order.PutRequest{ID: 1, Items: items})
+	assert.ErrorIs(t, err, order.ErrDuplicateProduct)
+
+	mockInventoryService.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything)
+	mockStore.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func TestUpdateNotEditable(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockMeilisearchService := new(MockMeilisearchService)
+	mockOutboxStore := new(MockOutboxStore)
+	logger := zap.NewNop().Sugar()
+
+	orderID := uint(1)
+	ord := &order.Order{
+		ID:     orderID,
+		Status: order.StatusShipped,
+		Items: []order.OrderItem{
+			{ID: 1, ProductID: 1, Quantity: 2, Price: 1000},
+		},
+	}
+
+	mockStore.On("Get", mock.Anything, orderID).Return(ord, nil)
+
+	mockLocker := new(MockLocker)
+
+	service := order.NewService(mockMeilisearchService, mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, new(MockUserService), &MockTransactor{}, mockOutboxStore, newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	err := service.Update(context.Background(), order.PutRequest{
+		ID: orderID,
+		Items: []order.OrderItemRequest{
+			{ProductID: 1, Quantity: 3},
+		},
+	})
+
+	assert.ErrorIs(t, err, order.ErrOrderNotEditable)
+
+	mockStore.AssertExpectations(t)
+	mockInventoryService.AssertNotCalled(t, "IncreaseStockBulk", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransition(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockMeilisearchService := new(MockMeilisearchService)
+	mockOutboxStore := new(MockOutboxStore)
+	logger := zap.NewNop().Sugar()
+
+	orderID := uint(1)
+	ord := &order.Order{
+		ID:     orderID,
+		Status: order.StatusConfirmed,
+		Items: []order.OrderItem{
+			{ID: 1, ProductID: 1, Quantity: 2, Price: 1000},
+		},
+	}
+
+	locked := *ord
+	mockStore.On("Get", mock.Anything, orderID).Return(ord, nil)
+	mockStore.On("GetForUpdate", mock.Anything, orderID).Return(&locked, nil)
+	mockStore.On("UpdateStatus", mock.Anything, orderID, order.StatusConfirmed, order.StatusCancelled).Return(nil)
+	mockInventoryService.On("IncreaseStockBulk", mock.Anything, map[uint]int{1: 2}, inventory.Reference{Reason: inventory.ReasonOrderCancelled, OrderID: orderID}).Return(nil)
+	mockOutboxStore.On("Add", mock.Anything, statusOutboxEvent(orderID, order.StatusConfirmed, order.StatusCancelled)).Return(nil)
+
+	mockLock := new(MockLock)
+	mockLock.On("Release", mock.Anything).Return(nil)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"stock_lock_product_1"}).Return(mockLock, nil)
+
+	service := order.NewService(mockMeilisearchService, mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, new(MockUserService), &MockTransactor{}, mockOutboxStore, newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	response, err := service.Transition(context.Background(), order.TransitionRequest{ID: orderID, Status: order.StatusCancelled})
+
+	assert.NoError(t, err)
+	assert.Equal(t, order.StatusCancelled, response.Status)
+
+	mockStore.AssertExpectations(t)
+	mockInventoryService.AssertExpectations(t)
+	mockOutboxStore.AssertExpectations(t)
+}
+
+func TestTransitionRollsBackOnLostLock(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockOutboxStore := new(MockOutboxStore)
+	logger := zap.NewNop().Sugar()
+
+	orderID := uint(1)
+	ord := &order.Order{
+		ID:     orderID,
+		Status: order.StatusConfirmed,
+		Items: []order.OrderItem{
+			{ID: 1, ProductID: 1, Quantity: 2, Price: 1000},
+		},
+	}
+
+	locked := *ord
+	mockStore.On("Get", mock.Anything, orderID).Return(ord, nil)
+	mockStore.On("GetForUpdate", mock.Anything, orderID).Return(&locked, nil)
+	mockStore.On("UpdateStatus", mock.Anything, orderID, order.StatusConfirmed, order.StatusCancelled).Return(nil)
+	mockInventoryService.On("IncreaseStockBulk", mock.Anything, map[uint]int{1: 2}, inventory.Reference{Reason: inventory.ReasonOrderCancelled, OrderID: orderID}).Return(nil)
+	mockOutboxStore.On("Add", mock.Anything, statusOutboxEvent(orderID, order.StatusConfirmed, order.StatusCancelled)).Return(nil)
+
+	// the stock lock could not be renewed while the order was cancelled
+	mockLock := new(MockLock)
+	mockLock.lose()
+	mockLock.On("Release", mock.Anything).Return(nil)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"stock_lock_product_1"}).Return(mockLock, nil)
+
+	service := order.NewService(new(MockMeilisearchService), mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, new(MockUserService), &MockTransactor{}, mockOutboxStore, newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	_, err := service.Transition(context.Background(), order.TransitionRequest{ID: orderID, Status: order.StatusCancelled})
+
+	assert.ErrorIs(t, err, order.ErrStockUpdateInProgress)
+	assert.ErrorIs(t, err, lock.ErrLost)
+	mockLock.AssertExpectations(t)
+}
+
+func TestTransitionInvalid(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockMeilisearchService := new(MockMeilisearchService)
+	mockOutboxStore := new(MockOutboxStore)
+	logger := zap.NewNop().Sugar()
+
+	orderID := uint(1)
+	mockStore.On("Get", mock.Anything, orderID).Return(&order.Order{ID: orderID, Status: order.StatusPending}, nil)
+
+	mockLocker := new(MockLocker)
+
+	service := order.NewService(mockMeilisearchService, mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, new(MockUserService), &MockTransactor{}, mockOutboxStore, newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	_, err := service.Transition(context.Background(), order.TransitionRequest{ID: orderID, Status: order.StatusShipped})
+
+	var transitionErr *order.TransitionError
+	assert.ErrorAs(t, err, &transitionErr)
+	assert.ErrorIs(t, err, order.ErrInvalidTransition)
+	assert.Equal(t, order.StatusPending, transitionErr.From)
+
+	mockStore.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransitionLeavesInternalMovesToDomainServices(t *testing.T) {
+	tests := []struct {
+		from order.Status
+		to   order.Status
+	}{
+		{order.StatusConfirmed, order.StatusPaid},
+		{order.StatusPaid, order.StatusShipped},
+		{order.StatusShipped, order.StatusDelivered},
+		{order.StatusDelivered, order.StatusRefunded},
+		{order.StatusDelivered, order.StatusReturned},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" to "+string(tt.to), func(t *testing.T) {
+			mockStore := new(MockStore)
+			orderID := uint(1)
+			mockStore.On("Get", mock.Anything, orderID).Return(&order.Order{ID: orderID, Status: tt.from}, nil)
+
+			service := order.NewService(new(MockMeilisearchService), new(MockLocker), &configuration.Configuration{}, zap.NewNop().Sugar(), mockStore, new(MockInventoryService), new(MockUserService), &MockTransactor{}, new(MockOutboxStore), newCurrencyService(), new(MockPromotionService), untaxed{})
+
+			_, err := service.Transition(context.Background(), order.TransitionRequest{ID: orderID, Status: tt.to, Client: true})
+
+			assert.ErrorIs(t, err, order.ErrInvalidTransition)
+			assert.True(t, tt.from.CanTransitionTo(tt.to))
+			mockStore.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateUnknownUser(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockMeilisearchService := new(MockMeilisearchService)
+	mockOutboxStore := new(MockOutboxStore)
+	mockUserService := new(MockUserService)
+	logger := zap.NewNop().Sugar()
+
+	mockUserService.On("Get", mock.Anything, uint(99)).Return((*user.UserResponse)(nil), gorm.ErrRecordNotFound)
+
+	mockLocker := new(MockLocker)
+
+	service := order.NewService(mockMeilisearchService, mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, mockUserService, &MockTransactor{}, mockOutboxStore, newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	_, err := service.Create(context.Background(), order.PostRequest{
+		UserID: 99,
+		Items: []order.OrderItemRequest{
+			{ProductID: 1, Quantity: 1},
+		},
+	})
+
+	assert.ErrorIs(t, err, order.ErrUserNotFound)
+
+	mockStore.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockInventoryService.AssertNotCalled(t, "GetMultiple", mock.Anything, mock.Anything)
+}
+
+func TestListByUser(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockMeilisearchService := new(MockMeilisearchService)
+	mockOutboxStore := new(MockOutboxStore)
+	mockUserService := new(MockUserService)
+	logger := zap.NewNop().Sugar()
+
+	mockUserService.On("Get", mock.Anything, uint(3)).Return(&user.UserResponse{ID: 3}, nil)
+	mockStore.On("ListByUser", mock.Anything, uint(3), 20, 0).Return([]order.Order{
+		{ID: 5, UserID: 3, Status: order.StatusPending},
+		{ID: 2, UserID: 3, Status: order.StatusDelivered},
+	}, int64(2), nil)
+
+	mockLocker := new(MockLocker)
+
+	service := order.NewService(mockMeilisearchService, mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, mockUserService, &MockTransactor{}, mockOutboxStore, newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	response, err := service.ListByUser(context.Background(), order.ListByUserRequest{UserID: 3})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), response.Total)
+	assert.Equal(t, 20, response.Limit)
+	assert.Equal(t, uint(5), response.Items[0].ID)
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestCreateReservedStock(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockMeilisearchService := new(MockMeilisearchService)
+	mockOutboxStore := new(MockOutboxStore)
+	mockUserService := new(MockUserService)
+	logger := zap.NewNop().Sugar()
+
+	mockUserService.On("Get", mock.Anything, uint(1)).Return(&user.UserResponse{ID: 1}, nil)
+	mockInventoryService.On("GetMultiple", mock.Anything, mock.Anything).Return(map[uint]inventory.Inventory{
+		1: {Stock: 10, Reserved: 9, Product: product.Product{ID: 1}},
+	}, nil)
+
+	mockLock := new(MockLock)
+	mockLock.On("Release", mock.Anything).Return(nil)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"stock_lock_product_1"}).Return(mockLock, nil)
+
+	service := order.NewService(mockMeilisearchService, mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, mockUserService, &MockTransactor{}, mockOutboxStore, newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	_, err := service.Create(context.Background(), order.PostRequest{
+		UserID: 1,
+		Items: []order.OrderItemRequest{
+			{ProductID: 1, Quantity: 2},
+		},
+	})
+
+	assert.ErrorIs(t, err, order.ErrNoStockAvailable)
+
+	mockStore.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockInventoryService.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestCreateReservedConcurrently covers a reservation made by another order between the stock check and the reserve.
+func TestCreateReservedConcurrently(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockMeilisearchService := new(MockMeilisearchService)
+	mockOutboxStore := new(MockOutboxStore)
+	mockUserService := new(MockUserService)
+	logger := zap.NewNop().Sugar()
+
+	mockUserService.On("Get", mock.Anything, uint(1)).Return(&user.UserResponse{ID: 1}, nil)
+	mockInventoryService.On("GetMultiple", mock.Anything, mock.Anything).Return(map[uint]inventory.Inventory{
+		1: {Stock: 10, Product: product.Product{ID: 1, Price: 1250}},
+	}, nil)
+	mockStore.On("Create", mock.Anything, mock.Anything).Return(nil)
+	mockInventoryService.On("Reserve", mock.Anything, mock.Anything, map[uint]int{1: 2}).Return(fmt.Errorf("%w for product 1", inventory.ErrInsufficientStock))
+
+	mockLock := new(MockLock)
+	mockLock.On("Release", mock.Anything).Return(nil)
+	mockLocker := new(MockLocker)
+	mockLocker.On("Acquire", mock.Anything, []string{"stock_lock_product_1"}).Return(mockLock, nil)
+
+	service := order.NewService(mockMeilisearchService, mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, mockUserService, &MockTransactor{}, mockOutboxStore, newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	_, err := service.Create(context.Background(), order.PostRequest{
+		UserID: 1,
+		Items: []order.OrderItemRequest{
+			{ProductID: 1, Quantity: 2},
+		},
+	})
+
+	assert.ErrorIs(t, err, order.ErrNoStockAvailable)
+	mockOutboxStore.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+}
+
+func TestTransitionConfirm(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockMeilisearchService := new(MockMeilisearchService)
+	mockOutboxStore := new(MockOutboxStore)
+	logger := zap.NewNop().Sugar()
+
+	orderID := uint(1)
+	reservedUntil := time.Now().Add(time.Hour)
+	ord := &order.Order{
+		ID:            orderID,
+		Status:        order.StatusPending,
+		ReservedUntil: &reservedUntil,
+		Items: []order.OrderItem{
+			{ID: 1, ProductID: 1, Quantity: 2, Price: 1000},
+		},
+	}
+
+	locked := *ord
+	mockStore.On("Get", mock.Anything, orderID).Return(ord, nil)
+	mockStore.On("GetForUpdate", mock.Anything, orderID).Return(&locked, nil)
+	mockStore.On("UpdateStatus", mock.Anything, orderID, order.StatusPending, order.StatusConfirmed).Return(nil)
+	mockInventoryService.On("CommitReservations", mock.Anything, orderID, inventory.Reference{Reason: inventory.ReasonOrderConfirmed, OrderID: orderID}).Return(nil)
+	mockOutboxStore.On("Add", mock.Anything, statusOutboxEvent(orderID, order.StatusPending, order.StatusConfirmed)).Return(nil)
+
+	mockLocker := new(MockLocker)
+
+	service := order.NewService(mockMeilisearchService, mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, new(MockUserService), &MockTransactor{}, mockOutboxStore, newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	response, err := service.Transition(context.Background(), order.TransitionRequest{ID: orderID, Status: order.StatusConfirmed})
+
+	assert.NoError(t, err)
+	assert.Equal(t, order.StatusConfirmed, response.Status)
+	assert.Nil(t, response.ReservedUntil)
+
+	mockStore.AssertExpectations(t)
+	mockInventoryService.AssertExpectations(t)
+	mockOutboxStore.AssertExpectations(t)
+}
+
+func TestTransitionConfirmExpired(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockMeilisearchService := new(MockMeilisearchService)
+	mockOutboxStore := new(MockOutboxStore)
+	logger := zap.NewNop().Sugar()
+
+	orderID := uint(1)
+	reservedUntil := time.Now().Add(-time.Minute)
+	mockStore.On("Get", mock.Anything, orderID).Return(&order.Order{ID: orderID, Status: order.StatusPending, ReservedUntil: &reservedUntil}, nil)
+
+	mockLocker := new(MockLocker)
+
+	service := order.NewService(mockMeilisearchService, mockLocker, &configuration.Configuration{}, logger, mockStore, mockInventoryService, new(MockUserService), &MockTransactor{}, mockOutboxStore, newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	_, err := service.Transition(context.Background(), order.TransitionRequest{ID: orderID, Status: order.StatusConfirmed})
+
+	assert.ErrorIs(t, err, order.ErrReservationExpired)
+
+	mockInventoryService.AssertNotCalled(t, "CommitReservations", mock.Anything, mock.Anything, mock.Anything)
+	mockStore.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListFallback(t *testing.T) {
+	mockStore := new(MockStore)
+	mockMeilisearchService := new(MockMeilisearchService)
+	logger := zap.NewNop().Sugar()
+
+	request := order.ListRequest{Input: "laptop"}
+	searchRequest := order.ListRequest{Input: "laptop", Limit: 20}
+
+	mockMeilisearchService.On("List", mock.Anything, searchRequest).Return(nil, errors.New("meilisearch unavailable")).Once()
+	mockStore.On("List", mock.Anything, searchRequest).Return([]order.Order{{ID: 1, Status: order.StatusPending}}, int64(1), nil).Twice()
+
+	service := order.NewService(mockMeilisearchService, new(MockLocker), &configuration.Configuration{SearchFallbackCooldown: time.Minute}, logger, mockStore, new(MockInventoryService), new(MockUserService), &MockTransactor{}, new(MockOutboxStore), newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	response, backend, err := service.List(context.Background(), request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, order.SearchBackendDatabase, backend)
+	assert.Len(t, response.Items, 1)
+	assert.Equal(t, int64(1), response.Total)
+	assert.Nil(t, response.Facets)
+
+	// Meilisearch is not asked again while it is cooling down
+	_, backend, err = service.List(context.Background(), request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, order.SearchBackendDatabase, backend)
+
+	mockStore.AssertExpectations(t)
+	mockMeilisearchService.AssertExpectations(t)
+}
+
+func TestListMeilisearch(t *testing.T) {
+	mockStore := new(MockStore)
+	mockMeilisearchService := new(MockMeilisearchService)
+	logger := zap.NewNop().Sugar()
+
+	request := order.ListRequest{Input: "laptop", Limit: 20}
+	mockMeilisearchService.On("List", mock.Anything, request).Return(&order.ListOrdersResponse{
+		Items:  []order.OrderResponse{},
+		Limit:  20,
+		Facets: &order.OrderFacets{Status: map[order.Status]int64{order.StatusPaid: 3}},
+	}, nil)
+
+	service := order.NewService(mockMeilisearchService, new(MockLocker), &configuration.Configuration{SearchFallbackCooldown: time.Minute}, logger, mockStore, new(MockInventoryService), new(MockUserService), &MockTransactor{}, new(MockOutboxStore), newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	response, backend, err := service.List(context.Background(), request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, order.SearchBackendMeilisearch, backend)
+	assert.Equal(t, int64(3), response.Facets.Status[order.StatusPaid])
+
+	mockStore.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestListOffsetCursors(t *testing.T) {
+	mockMeilisearchService := new(MockMeilisearchService)
+	logger := zap.NewNop().Sugar()
+
+	service := order.NewService(mockMeilisearchService, new(MockLocker), &configuration.Configuration{}, logger, new(MockStore), new(MockInventoryService), new(MockUserService), &MockTransactor{}, new(MockOutboxStore), newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	mockMeilisearchService.On("List", mock.Anything, order.ListRequest{Input: "laptop", Limit: 20}).
+		Return(&order.ListOrdersResponse{Total: 45, Limit: 20, Offset: 0}, nil)
+
+	first, _, err := service.List(context.Background(), order.ListRequest{Input: "laptop", Limit: 20})
+
+	assert.NoError(t, err)
+	assert.Empty(t, first.PrevCursor)
+	assert.NotEmpty(t, first.NextCursor)
+
+	mockMeilisearchService.On("List", mock.Anything, order.ListRequest{Input: "laptop", Limit: 20, Offset: 40, Cursor: "eyJvIjo0MH0"}).
+		Return(&order.ListOrdersResponse{Total: 45, Limit: 20, Offset: 40}, nil)
+
+	last, _, err := service.List(context.Background(), order.ListRequest{Input: "laptop", Limit: 20, Cursor: "eyJvIjo0MH0"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, last.NextCursor)
+	assert.Equal(t, "eyJvIjoyMH0", last.PrevCursor)
+
+	_, _, err = service.List(context.Background(), order.ListRequest{Cursor: "not a cursor"})
+
+	assert.ErrorIs(t, err, order.ErrInvalidCursor)
+
+	mockMeilisearchService.AssertExpectations(t)
+}
+
+func TestListKeysetCursors(t *testing.T) {
+	mockMeilisearchService := new(MockMeilisearchService)
+	logger := zap.NewNop().Sugar()
+
+	service := order.NewService(mockMeilisearchService, new(MockLocker), &configuration.Configuration{}, logger, new(MockStore), new(MockInventoryService), new(MockUserService), &MockTransactor{}, new(MockOutboxStore), newCurrencyService(), new(MockPromotionService), untaxed{})
+
+	createdAt := time.Date(2025, 3, 29, 12, 30, 0, 0, time.UTC)
+	page := func(ids ...uint) *order.ListOrdersResponse {
+		items := make([]order.OrderResponse, len(ids))
+		for i, id := range ids {
+			items[i] = order.OrderResponse{ID: id, CreatedAt: createdAt.Add(time.Duration(id) * time.Minute)}
+		}
+		return &order.ListOrdersResponse{Items: items, Total: 5}
+	}
+
+	// one more order than the limit is asked for to tell whether there is a next page
+	mockMeilisearchService.On("List", mock.Anything, order.ListRequest{Limit: 3}).Return(page(5, 4, 3), nil).Once()
+
+	first, _, err := service.List(context.Background(), order.ListRequest{Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Len(t, first.Items, 2)
+	assert.Equal(t, 2, first.Limit)
+	assert.Empty(t, first.PrevCursor)
+	assert.NotEmpty(t, first.NextCursor)
+
+	mockMeilisearchService.On("List", mock.Anything, mock.MatchedBy(func(request order.ListRequest) bool {
+		return request.After != nil && request.After.ID == 4 && request.After.CreatedAt.Equal(createdAt.Add(4*time.Minute))
+	})).Return(page(3, 2, 1), nil).Once()
+
+	second, _, err := service.List(context.Background(), order.ListRequest{Limit: 2, Cursor: first.NextCursor})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), second.Items[0].ID)
+	assert.NotEmpty(t, second.PrevCursor)
+	assert.NotEmpty(t, second.NextCursor)
+
+	mockMeilisearchService.On("List", mock.Anything, mock.MatchedBy(func(request order.ListRequest) bool {
+		return request.Before != nil && request.Before.ID == 3
+	})).Return(page(5, 4), nil).Once()
+
+	previous, _, err := service.List(context.Background(), order.ListRequest{Limit: 2, Cursor: second.PrevCursor})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(5), previous.Items[0].ID)
+	assert.Empty(t, previous.PrevCursor)
+	assert.NotEmpty(t, previous.NextCursor)
+
+	// keyset cursors cannot page a text search, which is ranked by relevance
+	_, _, err = service.List(context.Background(), order.ListRequest{Input: "laptop", Cursor: first.NextCursor})
+
+	assert.ErrorIs(t, err, order.ErrInvalidCursor)
+
+	mockMeilisearchService.AssertExpectations(t)
 }
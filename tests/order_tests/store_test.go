@@ -0,0 +1,124 @@
+package order_tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingDriver keeps the queries it is sent and answers every one of them with no rows.
+type recordingDriver struct {
+	queries []string
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+// orderBy is the sort of the last query that had one.
+func (d *recordingDriver) orderBy() string {
+	var orderBy string
+	for _, query := range d.queries {
+		if _, sort, found := strings.Cut(query, "ORDER BY "); found {
+			orderBy, _, _ = strings.Cut(sort, " LIMIT")
+		}
+	}
+	return orderBy
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.driver.queries = append(c.driver.queries, query)
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string {
+	return nil
+}
+
+func (emptyRows) Close() error {
+	return nil
+}
+
+func (emptyRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
+
+func newRecordingStore(t *testing.T) (order.IStore, *recordingDriver) {
+	recorder := &recordingDriver{}
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sql.OpenDB(connector{recorder}), SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	require.NoError(t, err)
+	return order.NewStore(db), recorder
+}
+
+type connector struct {
+	driver *recordingDriver
+}
+
+func (c connector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.Open("")
+}
+
+func (c connector) Driver() driver.Driver {
+	return c.driver
+}
+
+func TestStoreListSortDirection(t *testing.T) {
+	position := &order.Cursor{CreatedAt: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC), ID: 7}
+
+	tests := []struct {
+		name    string
+		request order.ListRequest
+		orderBy string
+	}{
+		{"keyset default", order.ListRequest{}, "orders.created_at DESC, orders.id DESC"},
+		{"keyset ascending without a field", order.ListRequest{SortDirection: order.SortAsc}, "orders.created_at ASC, orders.id ASC"},
+		{"keyset ascending", order.ListRequest{SortBy: order.SortCreatedAt, SortDirection: order.SortAsc}, "orders.created_at ASC, orders.id ASC"},
+		{"keyset before the cursor", order.ListRequest{Before: position}, "orders.created_at ASC, orders.id ASC"},
+		{"offset default", order.ListRequest{Input: "laptop"}, "orders.created_at DESC, orders.id DESC"},
+		{"offset ascending without a field", order.ListRequest{Input: "laptop", SortDirection: order.SortAsc}, "orders.created_at ASC, orders.id ASC"},
+		{"offset field default", order.ListRequest{SortBy: order.SortTotal}, "orders.total DESC, orders.id DESC"},
+		{"offset field ascending", order.ListRequest{SortBy: order.SortTotal, SortDirection: order.SortAsc}, "orders.total ASC, orders.id ASC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, recorder := newRecordingStore(t)
+
+			_, _, err := store.List(context.Background(), tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.orderBy, recorder.orderBy())
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package order_tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-redis/redismock/v9"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/event"
+	"github.com/p4xx07/order-service/pkg/eventstream"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+const eventStream = "order-service:events"
+
+func newStreamBus(client redis.Cmdable, stream string) event.IBus {
+	c := &configuration.Configuration{EventStream: stream, EventStreamMaxLen: 1000}
+	logger := zap.NewNop().Sugar()
+
+	bus := event.NewBus(c, logger)
+	order.NewStreamPublisher(eventstream.NewPublisher(client, stream, c.EventStreamMaxLen), c, logger).Subscribe(bus)
+	return bus
+}
+
+func TestStreamPublisher(t *testing.T) {
+	redisClient, mockClient := redismock.NewClientMock()
+
+	var envelope eventstream.Envelope
+	mockClient.CustomMatch(func(expected, actual []interface{}) error {
+		if len(actual) != 10 || actual[1] != eventStream || actual[7] != string(order.EventOrderStatusChanged) {
+			return fmt.Errorf("unexpected xadd %v", actual)
+		}
+		return json.Unmarshal([]byte(actual[9].(string)), &envelope)
+	}).ExpectXAdd(&redis.XAddArgs{Stream: eventStream, MaxLen: 1000, Approx: true, Values: []interface{}{"type", "", "envelope", ""}}).SetVal("1-0")
+
+	bus := newStreamBus(redisClient, eventStream)
+	err := bus.Publish(context.Background(), order.OrderStatusChanged{
+		OutboxID: 7,
+		Order:    order.Order{ID: 42, Status: order.StatusConfirmed},
+		From:     order.StatusPending,
+		To:       order.StatusConfirmed,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mockClient.ExpectationsWereMet())
+
+	assert.Equal(t, eventstream.Version, envelope.Version)
+	assert.Equal(t, "order.status_changed.7", envelope.ID)
+	assert.Equal(t, string(order.EventOrderStatusChanged), envelope.Type)
+
+	var data order.OrderStatusChangedData
+	assert.NoError(t, envelope.Decode(&data))
+	assert.Equal(t, order.StatusPending, data.From)
+	assert.Equal(t, order.StatusConfirmed, data.To)
+	assert.Equal(t, uint(42), data.Order.ID)
+}
+
+func TestStreamPublisherDisabled(t *testing.T) {
+	redisClient, mockClient := redismock.NewClientMock()
+
+	bus := newStreamBus(redisClient, "")
+	err := bus.Publish(context.Background(), order.OrderCreated{OutboxID: 1, Order: order.Order{ID: 1}})
+	assert.NoError(t, err)
+	assert.NoError(t, mockClient.ExpectationsWereMet())
+}
@@ -0,0 +1,42 @@
+package order_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/inventory"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestSweep(t *testing.T) {
+	mockStore := new(MockStore)
+	mockInventoryService := new(MockInventoryService)
+	mockOutboxStore := new(MockOutboxStore)
+	logger := zap.NewNop().Sugar()
+
+	mockStore.On("ListExpired", mock.Anything, mock.Anything, mock.Anything).Return([]order.Order{
+		{ID: 1, Status: order.StatusPending},
+		{ID: 2, Status: order.StatusPending},
+	}, nil)
+
+	mockStore.On("UpdateStatus", mock.Anything, uint(1), order.StatusPending, order.StatusExpired).Return(nil)
+	mockStore.On("UpdateStatus", mock.Anything, uint(2), order.StatusPending, order.StatusExpired).
+		Return(&order.TransitionError{From: order.StatusPending, To: order.StatusExpired})
+	mockInventoryService.On("ReleaseReservations", mock.Anything, uint(1), inventory.ReservationExpired).Return(nil)
+	mockOutboxStore.On("Add", mock.Anything, statusOutboxEvent(1, order.StatusPending, order.StatusExpired)).Return(nil)
+
+	sweeper := order.NewReservationSweeper(mockStore, mockInventoryService, &MockTransactor{}, mockOutboxStore, &configuration.Configuration{}, logger)
+
+	expired, err := sweeper.Sweep(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, expired)
+
+	mockStore.AssertExpectations(t)
+	mockInventoryService.AssertExpectations(t)
+	mockOutboxStore.AssertExpectations(t)
+	mockInventoryService.AssertNotCalled(t, "ReleaseReservations", mock.Anything, uint(2), mock.Anything)
+}
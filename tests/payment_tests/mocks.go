@@ -0,0 +1,96 @@
+package payment_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/app/domains/payment"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockTransactor struct{}
+
+func (m *MockTransactor) Transaction(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error) error {
+	return fn(ctx, nil)
+}
+
+type MockStore struct {
+	mock.Mock
+}
+
+func (m *MockStore) WithTx(tx *gorm.DB) payment.IStore {
+	return m
+}
+
+func (m *MockStore) Create(ctx context.Context, p *payment.Payment) error {
+	args := m.Called(ctx, p)
+	return args.Error(0)
+}
+
+func (m *MockStore) Get(ctx context.Context, id uint) (*payment.Payment, error) {
+	args := m.Called(ctx, id)
+	p, _ := args.Get(0).(*payment.Payment)
+	return p, args.Error(1)
+}
+
+func (m *MockStore) GetByReference(ctx context.Context, provider string, reference string) (*payment.Payment, error) {
+	args := m.Called(ctx, provider, reference)
+	p, _ := args.Get(0).(*payment.Payment)
+	return p, args.Error(1)
+}
+
+func (m *MockStore) ListByOrder(ctx context.Context, orderID uint) ([]payment.Payment, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]payment.Payment), args.Error(1)
+}
+
+func (m *MockStore) Update(ctx context.Context, p *payment.Payment, previous payment.Payment) error {
+	args := m.Called(ctx, p, previous)
+	return args.Error(0)
+}
+
+type MockOrderService struct {
+	mock.Mock
+}
+
+func (m *MockOrderService) WithTx(tx *gorm.DB) order.IService {
+	return m
+}
+
+func (m *MockOrderService) List(ctx context.Context, request order.ListRequest) (*order.ListOrdersResponse, order.SearchBackend, error) {
+	args := m.Called(ctx, request)
+	response, _ := args.Get(0).(*order.ListOrdersResponse)
+	return response, args.Get(1).(order.SearchBackend), args.Error(2)
+}
+
+func (m *MockOrderService) Get(ctx context.Context, orderID uint) (*order.OrderResponse, error) {
+	args := m.Called(ctx, orderID)
+	response, _ := args.Get(0).(*order.OrderResponse)
+	return response, args.Error(1)
+}
+
+func (m *MockOrderService) ListByUser(ctx context.Context, request order.ListByUserRequest) (*order.ListOrdersResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*order.ListOrdersResponse), args.Error(1)
+}
+
+func (m *MockOrderService) Create(ctx context.Context, request order.PostRequest) (*order.CreateOrderResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*order.CreateOrderResponse), args.Error(1)
+}
+
+func (m *MockOrderService) Update(ctx context.Context, request order.PutRequest) error {
+	args := m.Called(ctx, request)
+	return args.Error(0)
+}
+
+func (m *MockOrderService) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOrderService) Transition(ctx context.Context, request order.TransitionRequest) (*order.OrderResponse, error) {
+	args := m.Called(ctx, request)
+	response, _ := args.Get(0).(*order.OrderResponse)
+	return response, args.Error(1)
+}
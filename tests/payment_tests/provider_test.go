@@ -0,0 +1,54 @@
+package payment_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/payment"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name          string
+		configuration configuration.Configuration
+		provider      string
+		wantErr       bool
+	}{
+		{"disabled", configuration.Configuration{}, "disabled", false},
+		{"fake in debug", configuration.Configuration{Environment: "debug", PaymentProvider: payment.ProviderFake, PaymentWebhookSecret: secret}, "fake", false},
+		{"fake outside debug", configuration.Configuration{PaymentProvider: payment.ProviderFake, PaymentWebhookSecret: secret}, "", true},
+		{"fake without secret", configuration.Configuration{Environment: "debug", PaymentProvider: payment.ProviderFake}, "", true},
+		{"unknown", configuration.Configuration{PaymentProvider: "acme"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := payment.NewProvider(&tt.configuration)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.provider, provider.Name())
+		})
+	}
+}
+
+func TestDisabledProvider(t *testing.T) {
+	provider := payment.NewDisabledProvider()
+
+	_, err := provider.Authorize(context.Background(), payment.AuthorizeRequest{OrderID: 1, Amount: 100})
+	assert.ErrorIs(t, err, payment.ErrPaymentsDisabled)
+
+	_, err = provider.ParseWebhook([]byte(`{"type": "payment.captured"}`), payment.Sign("", []byte(`{"type": "payment.captured"}`)))
+	assert.ErrorIs(t, err, payment.ErrInvalidSignature)
+}
+
+func TestWebhookEmptySecretRejected(t *testing.T) {
+	provider := payment.NewFakeProvider(&configuration.Configuration{})
+
+	payload := []byte(`{"type": "payment.captured", "reference": "fake_1_1"}`)
+	_, err := provider.ParseWebhook(payload, payment.Sign("", payload))
+	assert.ErrorIs(t, err, payment.ErrInvalidSignature)
+}
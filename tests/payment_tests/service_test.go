@@ -0,0 +1,278 @@
+package payment_tests
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/app/domains/payment"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"testing"
+)
+
+const secret = "webhook-secret"
+
+func confirmedOrder(status order.Status) *order.OrderResponse {
+	return &order.OrderResponse{ID: 7, Status: status, Currency: "EUR", Total: 2500}
+}
+
+func newProvider() payment.PaymentProvider {
+	return payment.NewFakeProvider(&configuration.Configuration{PaymentWebhookSecret: secret})
+}
+
+func newService(store *MockStore, provider payment.PaymentProvider, orderService *MockOrderService) payment.IService {
+	return payment.NewService(store, provider, orderService, &MockTransactor{}, &configuration.Configuration{}, zap.NewNop().Sugar())
+}
+
+// authorized is a payment the provider holds the order total for.
+func authorized(t *testing.T, provider payment.PaymentProvider) *payment.Payment {
+	authorization, err := provider.Authorize(context.Background(), payment.AuthorizeRequest{OrderID: 7, Amount: 2500, Currency: "EUR", Token: "tok_visa"})
+	assert.NoError(t, err)
+	return &payment.Payment{ID: 1, OrderID: 7, Provider: provider.Name(), Reference: authorization.Reference, Status: payment.StatusAuthorized, Amount: 2500, Currency: "EUR"}
+}
+
+func TestAuthorize(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOrderService := new(MockOrderService)
+
+	mockOrderService.On("Get", mock.Anything, uint(7)).Return(confirmedOrder(order.StatusConfirmed), nil)
+	mockStore.On("ListByOrder", mock.Anything, uint(7)).Return([]payment.Payment{{Status: payment.StatusFailed}}, nil)
+	mockStore.On("Create", mock.Anything, mock.MatchedBy(func(p *payment.Payment) bool {
+		return p.Status == payment.StatusAuthorized && p.Amount == 2500 && p.Provider == "fake" && p.Reference != ""
+	})).Return(nil)
+
+	service := newService(mockStore, newProvider(), mockOrderService)
+
+	response, err := service.Authorize(context.Background(), payment.PostRequest{OrderID: 7, Token: "tok_visa"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, payment.StatusAuthorized, response.Status)
+	mockStore.AssertExpectations(t)
+}
+
+func TestAuthorizeDeclined(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOrderService := new(MockOrderService)
+
+	mockOrderService.On("Get", mock.Anything, uint(7)).Return(confirmedOrder(order.StatusConfirmed), nil)
+	mockStore.On("ListByOrder", mock.Anything, uint(7)).Return([]payment.Payment{}, nil)
+	mockStore.On("Create", mock.Anything, mock.MatchedBy(func(p *payment.Payment) bool {
+		return p.Status == payment.StatusFailed && p.FailureReason != ""
+	})).Return(nil)
+
+	service := newService(mockStore, newProvider(), mockOrderService)
+
+	response, err := service.Authorize(context.Background(), payment.PostRequest{OrderID: 7, Token: payment.DeclinedToken})
+
+	assert.ErrorIs(t, err, payment.ErrPaymentDeclined)
+	assert.Equal(t, payment.StatusFailed, response.Status)
+	mockStore.AssertExpectations(t)
+}
+
+func TestAuthorizeConcurrentlyVoidsLoser(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOrderService := new(MockOrderService)
+	provider := newProvider()
+
+	var reference string
+	mockOrderService.On("Get", mock.Anything, uint(7)).Return(confirmedOrder(order.StatusConfirmed), nil)
+	mockStore.On("ListByOrder", mock.Anything, uint(7)).Return([]payment.Payment{}, nil)
+	// another request stored its active payment for the order in between
+	mockStore.On("Create", mock.Anything, mock.MatchedBy(func(p *payment.Payment) bool {
+		reference = p.Reference
+		return true
+	})).Return(gorm.ErrDuplicatedKey)
+
+	service := newService(mockStore, provider, mockOrderService)
+
+	_, err := service.Authorize(context.Background(), payment.PostRequest{OrderID: 7, Token: "tok_visa"})
+
+	assert.ErrorIs(t, err, payment.ErrAlreadyPaid)
+	assert.ErrorIs(t, provider.Capture(context.Background(), reference, 2500), payment.ErrProviderRejected)
+}
+
+func TestAuthorizeRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   order.Status
+		payments []payment.Payment
+		err      error
+	}{
+		{name: "pending order", status: order.StatusPending, err: payment.ErrOrderNotPayable},
+		{name: "paid order", status: order.StatusPaid, err: payment.ErrOrderNotPayable},
+		{name: "already authorized", status: order.StatusConfirmed, payments: []payment.Payment{{Status: payment.StatusAuthorized}}, err: payment.ErrAlreadyPaid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockStore)
+			mockOrderService := new(MockOrderService)
+
+			mockOrderService.On("Get", mock.Anything, uint(7)).Return(confirmedOrder(tt.status), nil)
+			mockStore.On("ListByOrder", mock.Anything, uint(7)).Return(tt.payments, nil)
+
+			service := newService(mockStore, newProvider(), mockOrderService)
+
+			_, err := service.Authorize(context.Background(), payment.PostRequest{OrderID: 7, Token: "tok_visa"})
+
+			assert.ErrorIs(t, err, tt.err)
+			mockStore.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCapturePaysOrder(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOrderService := new(MockOrderService)
+	provider := newProvider()
+
+	mockStore.On("Get", mock.Anything, uint(1)).Return(authorized(t, provider), nil)
+	mockOrderService.On("Get", mock.Anything, uint(7)).Return(confirmedOrder(order.StatusConfirmed), nil)
+	mockStore.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(previous payment.Payment) bool {
+		return previous.Status == payment.StatusAuthorized
+	})).Return(nil)
+	mockOrderService.On("Transition", mock.Anything, order.TransitionRequest{ID: 7, Status: order.StatusPaid}).Return(confirmedOrder(order.StatusPaid), nil)
+
+	service := newService(mockStore, provider, mockOrderService)
+
+	response, err := service.Capture(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, payment.StatusCaptured, response.Status)
+	mockOrderService.AssertExpectations(t)
+}
+
+func TestCaptureRefusedForUnconfirmedOrder(t *testing.T) {
+	for _, status := range []order.Status{order.StatusCancelled, order.StatusExpired} {
+		t.Run(string(status), func(t *testing.T) {
+			mockStore := new(MockStore)
+			mockOrderService := new(MockOrderService)
+			provider := newProvider()
+
+			held := authorized(t, provider)
+			mockStore.On("Get", mock.Anything, uint(1)).Return(held, nil)
+			mockOrderService.On("Get", mock.Anything, uint(7)).Return(confirmedOrder(status), nil)
+
+			service := newService(mockStore, provider, mockOrderService)
+
+			_, err := service.Capture(context.Background(), 1)
+
+			assert.ErrorIs(t, err, payment.ErrOrderNotPayable)
+			mockStore.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+			// the money is still only held, so it can be released
+			assert.NoError(t, provider.Void(context.Background(), held.Reference))
+		})
+	}
+}
+
+func TestVoidCancelsOrder(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOrderService := new(MockOrderService)
+	provider := newProvider()
+
+	mockStore.On("Get", mock.Anything, uint(1)).Return(authorized(t, provider), nil)
+	mockOrderService.On("Get", mock.Anything, uint(7)).Return(confirmedOrder(order.StatusConfirmed), nil)
+	mockStore.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockOrderService.On("Transition", mock.Anything, order.TransitionRequest{ID: 7, Status: order.StatusCancelled}).Return(confirmedOrder(order.StatusCancelled), nil)
+
+	service := newService(mockStore, provider, mockOrderService)
+
+	response, err := service.Void(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, payment.StatusVoided, response.Status)
+	mockOrderService.AssertExpectations(t)
+}
+
+func TestRefund(t *testing.T) {
+	tests := []struct {
+		name         string
+		amount       *money.Amount
+		status       payment.Status
+		refundsOrder bool
+		err          error
+	}{
+		{name: "partial", amount: ptr(money.Amount(1000)), status: payment.StatusPartiallyRefunded},
+		{name: "full", status: payment.StatusRefunded, refundsOrder: true},
+		{name: "too much", amount: ptr(money.Amount(2501)), err: payment.ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockStore)
+			mockOrderService := new(MockOrderService)
+			provider := newProvider()
+
+			captured := authorized(t, provider)
+			assert.NoError(t, provider.Capture(context.Background(), captured.Reference, captured.Amount))
+			captured.Status = payment.StatusCaptured
+
+			mockStore.On("Get", mock.Anything, uint(1)).Return(captured, nil)
+			mockOrderService.On("Get", mock.Anything, uint(7)).Return(confirmedOrder(order.StatusPaid), nil)
+			mockStore.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			mockOrderService.On("Transition", mock.Anything, order.TransitionRequest{ID: 7, Status: order.StatusRefunded}).Return(confirmedOrder(order.StatusRefunded), nil)
+
+			service := newService(mockStore, provider, mockOrderService)
+
+			response, err := service.Refund(context.Background(), payment.RefundRequest{ID: 1, Amount: tt.amount})
+
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				mockStore.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, response.Status)
+			if tt.refundsOrder {
+				mockOrderService.AssertExpectations(t)
+			} else {
+				mockOrderService.AssertNotCalled(t, "Transition", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWebhookCaptured(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOrderService := new(MockOrderService)
+	provider := newProvider()
+
+	existing := authorized(t, provider)
+	mockStore.On("GetByReference", mock.Anything, "fake", existing.Reference).Return(existing, nil)
+	mockOrderService.On("Get", mock.Anything, uint(7)).Return(confirmedOrder(order.StatusConfirmed), nil)
+	mockStore.On("Update", mock.Anything, mock.MatchedBy(func(p *payment.Payment) bool {
+		return p.Status == payment.StatusCaptured
+	}), mock.Anything).Return(nil).Once()
+	mockOrderService.On("Transition", mock.Anything, order.TransitionRequest{ID: 7, Status: order.StatusPaid}).Return(confirmedOrder(order.StatusPaid), nil).Once()
+
+	service := newService(mockStore, provider, mockOrderService)
+
+	payload, _ := json.Marshal(payment.WebhookEvent{Type: payment.EventCaptured, Reference: existing.Reference})
+	assert.NoError(t, service.HandleWebhook(context.Background(), payload, payment.Sign(secret, payload)))
+
+	// a replay of the same event changes nothing
+	assert.NoError(t, service.HandleWebhook(context.Background(), payload, payment.Sign(secret, payload)))
+
+	mockStore.AssertExpectations(t)
+	mockOrderService.AssertExpectations(t)
+}
+
+func TestWebhookInvalidSignature(t *testing.T) {
+	mockStore := new(MockStore)
+
+	service := newService(mockStore, newProvider(), new(MockOrderService))
+
+	payload := []byte(`{"type":"payment.captured","reference":"fake_7_1"}`)
+	err := service.HandleWebhook(context.Background(), payload, payment.Sign("another-secret", payload))
+
+	assert.ErrorIs(t, err, payment.ErrInvalidSignature)
+	mockStore.AssertNotCalled(t, "GetByReference", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
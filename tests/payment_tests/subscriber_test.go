@@ -0,0 +1,54 @@
+package payment_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/app/domains/payment"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func newSubscribedBus(store *MockStore, provider payment.PaymentProvider) event.IBus {
+	logger := zap.NewNop().Sugar()
+	bus := event.NewBus(&configuration.Configuration{EventQueueSize: 1}, logger)
+	payment.NewSubscriber(store, provider, logger).Subscribe(bus)
+	return bus
+}
+
+func TestSubscriberVoidsPaymentOfCancelledOrder(t *testing.T) {
+	for _, status := range []order.Status{order.StatusCancelled, order.StatusExpired} {
+		t.Run(string(status), func(t *testing.T) {
+			mockStore := new(MockStore)
+			provider := newProvider()
+
+			held := authorized(t, provider)
+			mockStore.On("ListByOrder", mock.Anything, uint(7)).Return([]payment.Payment{*held, {ID: 2, OrderID: 7, Status: payment.StatusFailed}}, nil)
+			mockStore.On("Update", mock.Anything, mock.MatchedBy(func(p *payment.Payment) bool {
+				return p.ID == 1 && p.Status == payment.StatusVoided
+			}), mock.Anything).Return(nil).Once()
+
+			bus := newSubscribedBus(mockStore, provider)
+
+			err := bus.Publish(context.Background(), order.OrderStatusChanged{Order: order.Order{ID: 7}, From: order.StatusConfirmed, To: status})
+
+			assert.NoError(t, err)
+			mockStore.AssertExpectations(t)
+			assert.ErrorIs(t, provider.Capture(context.Background(), held.Reference, 2500), payment.ErrProviderRejected)
+		})
+	}
+}
+
+func TestSubscriberIgnoresOtherTransitions(t *testing.T) {
+	mockStore := new(MockStore)
+
+	bus := newSubscribedBus(mockStore, newProvider())
+
+	err := bus.Publish(context.Background(), order.OrderStatusChanged{Order: order.Order{ID: 7}, From: order.StatusConfirmed, To: order.StatusPaid})
+
+	assert.NoError(t, err)
+	mockStore.AssertNotCalled(t, "ListByOrder", mock.Anything, mock.Anything)
+}
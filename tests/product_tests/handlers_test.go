@@ -0,0 +1,86 @@
+package product_tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/p4xx07/order-service/app/domains/product"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_Post(t *testing.T) {
+	mockService := new(MockService)
+	logger := zap.NewNop().Sugar()
+	handler := product.NewHandler(mockService, logger)
+
+	request := product.PostRequest{Name: "Laptop", Price: 1200, Category: "Electronics"}
+	mockService.On("Create", mock.Anything, request).Return(&product.CreateProductResponse{ID: 9}, nil)
+
+	app := fiber.New()
+	app.Post("/products", handler.Post)
+
+	jRequest, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("Error marshalling request: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/products", bytes.NewReader(jRequest))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Data product.CreateProductResponse `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		t.Fatalf("Error unmarshalling response: %v", err)
+	}
+	assert.Equal(t, uint(9), response.Data.ID)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_PostInvalid(t *testing.T) {
+	mockService := new(MockService)
+	logger := zap.NewNop().Sugar()
+	handler := product.NewHandler(mockService, logger)
+
+	app := fiber.New()
+	app.Post("/products", handler.Post)
+
+	req := httptest.NewRequest(http.MethodPost, "/products", bytes.NewReader([]byte(`{"price": 10}`)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestHandler_List(t *testing.T) {
+	mockService := new(MockService)
+	logger := zap.NewNop().Sugar()
+	handler := product.NewHandler(mockService, logger)
+
+	mockService.On("List", mock.Anything, product.ListRequest{Category: "Accessories", Limit: 10, Offset: 20}).
+		Return(&product.ListProductsResponse{Total: 1, Limit: 10, Offset: 20}, nil)
+
+	app := fiber.New()
+	app.Get("/products", handler.List)
+
+	req := httptest.NewRequest(http.MethodGet, "/products?category=Accessories&limit=10&offset=20", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,65 @@
+package product_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/product"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStore struct {
+	mock.Mock
+}
+
+func (m *MockStore) Create(ctx context.Context, p *product.Product) error {
+	args := m.Called(ctx, p)
+	return args.Error(0)
+}
+
+func (m *MockStore) Get(ctx context.Context, id uint) (*product.Product, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*product.Product), args.Error(1)
+}
+
+func (m *MockStore) List(ctx context.Context, request product.ListRequest) ([]product.Product, int64, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).([]product.Product), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockStore) Update(ctx context.Context, p *product.Product) error {
+	args := m.Called(ctx, p)
+	return args.Error(0)
+}
+
+func (m *MockStore) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) List(ctx context.Context, request product.ListRequest) (*product.ListProductsResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*product.ListProductsResponse), args.Error(1)
+}
+
+func (m *MockService) Get(ctx context.Context, id uint) (*product.ProductResponse, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*product.ProductResponse), args.Error(1)
+}
+
+func (m *MockService) Create(ctx context.Context, request product.PostRequest) (*product.CreateProductResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*product.CreateProductResponse), args.Error(1)
+}
+
+func (m *MockService) Update(ctx context.Context, request product.PutRequest) (*product.ProductResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*product.ProductResponse), args.Error(1)
+}
+
+func (m *MockService) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
@@ -0,0 +1,64 @@
+package product_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/product"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	mockStore.On("List", mock.Anything, product.ListRequest{Limit: 100, Offset: 0}).
+		Return([]product.Product{{ID: 1, Name: "Laptop"}, {ID: 2, Name: "Mouse"}}, int64(42), nil)
+
+	service := product.NewService(mockStore, &configuration.Configuration{}, logger)
+
+	response, err := service.List(context.Background(), product.ListRequest{Limit: 1000, Offset: -5})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), response.Total)
+	assert.Equal(t, 100, response.Limit)
+	assert.Len(t, response.Items, 2)
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestUpdate(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	existing := &product.Product{ID: 1, Name: "Laptop", Price: 1200}
+	mockStore.On("Get", mock.Anything, uint(1)).Return(existing, nil)
+	mockStore.On("Update", mock.Anything, mock.MatchedBy(func(p *product.Product) bool {
+		return p.Name == "Gaming Laptop" && p.Price == 1500
+	})).Return(nil)
+
+	service := product.NewService(mockStore, &configuration.Configuration{}, logger)
+
+	response, err := service.Update(context.Background(), product.PutRequest{ID: 1, Name: "Gaming Laptop", Price: 1500})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Gaming Laptop", response.Name)
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestDeleteNotFound(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	mockStore.On("Delete", mock.Anything, uint(7)).Return(gorm.ErrRecordNotFound)
+
+	service := product.NewService(mockStore, &configuration.Configuration{}, logger)
+
+	err := service.Delete(context.Background(), 7)
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,51 @@
+package promotion_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/promotion"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockStore struct {
+	mock.Mock
+}
+
+func (m *MockStore) WithTx(tx *gorm.DB) promotion.IStore {
+	return m
+}
+
+func (m *MockStore) Create(ctx context.Context, p *promotion.Promotion) error {
+	args := m.Called(ctx, p)
+	return args.Error(0)
+}
+
+func (m *MockStore) Get(ctx context.Context, id uint) (*promotion.Promotion, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*promotion.Promotion), args.Error(1)
+}
+
+func (m *MockStore) GetByCodes(ctx context.Context, codes []string) (map[string]promotion.Promotion, error) {
+	args := m.Called(ctx, codes)
+	return args.Get(0).(map[string]promotion.Promotion), args.Error(1)
+}
+
+func (m *MockStore) List(ctx context.Context, request promotion.ListRequest) ([]promotion.Promotion, int64, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).([]promotion.Promotion), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockStore) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockStore) Redeem(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockStore) Release(ctx context.Context, ids []uint) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
@@ -0,0 +1,204 @@
+package promotion_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/promotion"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func uintPtr(value uint) *uint {
+	return &value
+}
+
+// cart holds two units of product 1 at 10.00 and three units of product 2 at 5.00, 35.00 in total.
+var cart = promotion.Cart{
+	ExchangeRate: 1,
+	Lines: []promotion.Line{
+		{ProductID: 1, Quantity: 2, Price: 1000},
+		{ProductID: 2, Quantity: 3, Price: 500},
+	},
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		promotion promotion.Promotion
+		cart      promotion.Cart
+		expected  money.Amount
+	}{
+		{
+			name:      "percentage off the order",
+			promotion: promotion.Promotion{Type: promotion.TypePercentage, Scope: promotion.ScopeOrder, Percent: 10},
+			cart:      cart,
+			expected:  350,
+		},
+		{
+			name:      "percentage off a product",
+			promotion: promotion.Promotion{Type: promotion.TypePercentage, Scope: promotion.ScopeProduct, ProductID: uintPtr(2), Percent: 50},
+			cart:      cart,
+			expected:  750,
+		},
+		{
+			name:      "fixed amount off the order",
+			promotion: promotion.Promotion{Type: promotion.TypeFixed, Scope: promotion.ScopeOrder, Amount: 500},
+			cart:      cart,
+			expected:  500,
+		},
+		{
+			name:      "fixed amount off every unit of a product",
+			promotion: promotion.Promotion{Type: promotion.TypeFixed, Scope: promotion.ScopeProduct, ProductID: uintPtr(1), Amount: 200},
+			cart:      cart,
+			expected:  400,
+		},
+		{
+			name:      "fixed amount in the order currency",
+			promotion: promotion.Promotion{Type: promotion.TypeFixed, Scope: promotion.ScopeOrder, Amount: 500},
+			cart:      promotion.Cart{ExchangeRate: 1.08, Lines: cart.Lines},
+			expected:  540,
+		},
+		{
+			name:      "buy two get one",
+			promotion: promotion.Promotion{Type: promotion.TypeBuyXGetY, Scope: promotion.ScopeProduct, ProductID: uintPtr(2), BuyQuantity: 2, GetQuantity: 1},
+			cart:      cart,
+			expected:  500,
+		},
+		{
+			name:      "capped at the subtotal",
+			promotion: promotion.Promotion{Type: promotion.TypeFixed, Scope: promotion.ScopeOrder, Amount: 10000},
+			cart:      cart,
+			expected:  3500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockStore)
+			tt.promotion.ID, tt.promotion.Code = 1, "CODE"
+			mockStore.On("GetByCodes", mock.Anything, []string{"CODE"}).Return(map[string]promotion.Promotion{"CODE": tt.promotion}, nil)
+
+			service := promotion.NewService(mockStore, &configuration.Configuration{}, zap.NewNop().Sugar())
+
+			discounts, err := service.Apply(context.Background(), promotion.ApplyRequest{Codes: []string{" code "}, Cart: tt.cart})
+
+			assert.NoError(t, err)
+			assert.Equal(t, []promotion.Discount{{PromotionID: 1, Code: "CODE", Amount: tt.expected}}, discounts)
+		})
+	}
+}
+
+func TestApplyRejected(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	promotions := map[string]promotion.Promotion{
+		"ENDED":      {ID: 1, Code: "ENDED", Type: promotion.TypeFixed, Scope: promotion.ScopeOrder, Amount: 100, EndsAt: &past},
+		"UPCOMING":   {ID: 2, Code: "UPCOMING", Type: promotion.TypeFixed, Scope: promotion.ScopeOrder, Amount: 100, StartsAt: &future},
+		"USEDUP":     {ID: 3, Code: "USEDUP", Type: promotion.TypeFixed, Scope: promotion.ScopeOrder, Amount: 100, UsageLimit: 5, UsageCount: 5},
+		"OTHER":      {ID: 4, Code: "OTHER", Type: promotion.TypePercentage, Scope: promotion.ScopeProduct, ProductID: uintPtr(9), Percent: 10},
+		"RETIRED":    {ID: 5, Code: "RETIRED", Type: promotion.TypeFixed, Scope: promotion.ScopeOrder, Amount: 100, DeletedAt: gorm.DeletedAt{Time: past, Valid: true}},
+		"NOT_ENOUGH": {ID: 6, Code: "NOT_ENOUGH", Type: promotion.TypeBuyXGetY, Scope: promotion.ScopeProduct, ProductID: uintPtr(1), BuyQuantity: 2, GetQuantity: 1},
+	}
+
+	tests := []struct {
+		code     string
+		expected error
+	}{
+		{code: "MISSING", expected: promotion.ErrPromotionNotFound},
+		{code: "RETIRED", expected: promotion.ErrPromotionNotFound},
+		{code: "ENDED", expected: promotion.ErrPromotionNotActive},
+		{code: "UPCOMING", expected: promotion.ErrPromotionNotActive},
+		{code: "USEDUP", expected: promotion.ErrPromotionExhausted},
+		{code: "OTHER", expected: promotion.ErrPromotionNotApplicable},
+		{code: "NOT_ENOUGH", expected: promotion.ErrPromotionNotApplicable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			mockStore := new(MockStore)
+			mockStore.On("GetByCodes", mock.Anything, []string{tt.code}).Return(promotions, nil)
+
+			service := promotion.NewService(mockStore, &configuration.Configuration{}, zap.NewNop().Sugar())
+
+			_, err := service.Apply(context.Background(), promotion.ApplyRequest{Codes: []string{tt.code}, Cart: cart})
+
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func TestApplyRedeemed(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	mockStore := new(MockStore)
+	mockStore.On("GetByCodes", mock.Anything, []string{"ENDED"}).Return(map[string]promotion.Promotion{
+		"ENDED": {ID: 1, Code: "ENDED", Type: promotion.TypeFixed, Scope: promotion.ScopeOrder, Amount: 100, EndsAt: &past, UsageLimit: 1, UsageCount: 1},
+	}, nil)
+
+	service := promotion.NewService(mockStore, &configuration.Configuration{}, zap.NewNop().Sugar())
+
+	// an order keeps the promotions it already redeemed when its items change
+	discounts, err := service.Apply(context.Background(), promotion.ApplyRequest{Codes: []string{"ENDED"}, Redeemed: []string{"ENDED"}, Cart: cart})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []promotion.Discount{{PromotionID: 1, Code: "ENDED", Amount: 100}}, discounts)
+}
+
+func TestApplyStacked(t *testing.T) {
+	mockStore := new(MockStore)
+	mockStore.On("GetByCodes", mock.Anything, []string{"HALF", "FIVE"}).Return(map[string]promotion.Promotion{
+		"HALF": {ID: 1, Code: "HALF", Type: promotion.TypePercentage, Scope: promotion.ScopeOrder, Percent: 50},
+		"FIVE": {ID: 2, Code: "FIVE", Type: promotion.TypeFixed, Scope: promotion.ScopeOrder, Amount: 5000},
+	}, nil)
+
+	service := promotion.NewService(mockStore, &configuration.Configuration{}, zap.NewNop().Sugar())
+
+	discounts, err := service.Apply(context.Background(), promotion.ApplyRequest{Codes: []string{"HALF", "FIVE", "half"}, Cart: cart})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []promotion.Discount{
+		{PromotionID: 1, Code: "HALF", Amount: 1750},
+		{PromotionID: 2, Code: "FIVE", Amount: 1750},
+	}, discounts)
+}
+
+func TestCreateInvalid(t *testing.T) {
+	mockStore := new(MockStore)
+	service := promotion.NewService(mockStore, &configuration.Configuration{}, zap.NewNop().Sugar())
+
+	requests := []promotion.PostRequest{
+		{Code: "A", Type: "free", Scope: promotion.ScopeOrder},
+		{Code: "B", Type: promotion.TypePercentage, Scope: promotion.ScopeOrder, Percent: 120},
+		{Code: "C", Type: promotion.TypeFixed, Scope: promotion.ScopeProduct, Amount: 100},
+		{Code: "D", Type: promotion.TypeBuyXGetY, Scope: promotion.ScopeOrder, BuyQuantity: 2, GetQuantity: 1},
+	}
+
+	for _, request := range requests {
+		_, err := service.Create(context.Background(), request)
+		assert.ErrorIs(t, err, promotion.ErrInvalidPromotion, request.Code)
+	}
+	mockStore.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateCodeTaken(t *testing.T) {
+	mockStore := new(MockStore)
+	mockStore.On("Create", mock.Anything, mock.MatchedBy(func(p *promotion.Promotion) bool {
+		return p.Code == "SPRING10"
+	})).Return(gorm.ErrDuplicatedKey)
+
+	service := promotion.NewService(mockStore, &configuration.Configuration{}, zap.NewNop().Sugar())
+
+	_, err := service.Create(context.Background(), promotion.PostRequest{
+		Code:    "spring10",
+		Type:    promotion.TypePercentage,
+		Scope:   promotion.ScopeOrder,
+		Percent: 10,
+	})
+
+	assert.ErrorIs(t, err, promotion.ErrCodeTaken)
+}
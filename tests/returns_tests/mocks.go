@@ -0,0 +1,164 @@
+package returns_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/inventory"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/app/domains/returns"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockTransactor struct{}
+
+func (m *MockTransactor) Transaction(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error) error {
+	return fn(ctx, nil)
+}
+
+type MockStore struct {
+	mock.Mock
+}
+
+func (m *MockStore) WithTx(tx *gorm.DB) returns.IStore {
+	return m
+}
+
+func (m *MockStore) Create(ctx context.Context, r *returns.Return) error {
+	args := m.Called(ctx, r)
+	return args.Error(0)
+}
+
+func (m *MockStore) Get(ctx context.Context, id uint) (*returns.Return, error) {
+	args := m.Called(ctx, id)
+	r, _ := args.Get(0).(*returns.Return)
+	return r, args.Error(1)
+}
+
+func (m *MockStore) ListByOrder(ctx context.Context, orderID uint) ([]returns.Return, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]returns.Return), args.Error(1)
+}
+
+func (m *MockStore) ListByOrderForUpdate(ctx context.Context, orderID uint) ([]returns.Return, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]returns.Return), args.Error(1)
+}
+
+func (m *MockStore) Update(ctx context.Context, r *returns.Return, from returns.Status) error {
+	args := m.Called(ctx, r, from)
+	return args.Error(0)
+}
+
+type MockInventoryService struct {
+	mock.Mock
+}
+
+func (m *MockInventoryService) Get(ctx context.Context, productID uint) (*inventory.Inventory, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).(*inventory.Inventory), args.Error(1)
+}
+
+func (m *MockInventoryService) WithTx(tx *gorm.DB) inventory.IService {
+	return m
+}
+
+func (m *MockInventoryService) DecreaseStockBulk(ctx context.Context, updates map[uint]int, reference inventory.Reference) error {
+	args := m.Called(ctx, updates, reference)
+	return args.Error(0)
+}
+
+func (m *MockInventoryService) IncreaseStockBulk(ctx context.Context, updates map[uint]int, reference inventory.Reference) error {
+	args := m.Called(ctx, updates, reference)
+	return args.Error(0)
+}
+
+func (m *MockInventoryService) SetStock(ctx context.Context, request inventory.SetStockRequest) (*inventory.InventoryResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*inventory.InventoryResponse), args.Error(1)
+}
+
+func (m *MockInventoryService) SetReorder(ctx context.Context, request inventory.ReorderRequest) (*inventory.InventoryResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*inventory.InventoryResponse), args.Error(1)
+}
+
+func (m *MockInventoryService) Adjust(ctx context.Context, request inventory.AdjustmentRequest) (*inventory.InventoryResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*inventory.InventoryResponse), args.Error(1)
+}
+
+func (m *MockInventoryService) ListMovements(ctx context.Context, request inventory.ListMovementsRequest) (*inventory.ListMovementsResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*inventory.ListMovementsResponse), args.Error(1)
+}
+
+func (m *MockInventoryService) ListAlerts(ctx context.Context, request inventory.ListAlertsRequest) (*inventory.ListAlertsResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*inventory.ListAlertsResponse), args.Error(1)
+}
+
+func (m *MockInventoryService) GetMultiple(ctx context.Context, ids []uint) (map[uint]inventory.Inventory, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).(map[uint]inventory.Inventory), args.Error(1)
+}
+
+func (m *MockInventoryService) Reserve(ctx context.Context, orderID uint, quantities map[uint]int) error {
+	args := m.Called(ctx, orderID, quantities)
+	return args.Error(0)
+}
+
+func (m *MockInventoryService) CommitReservations(ctx context.Context, orderID uint, reference inventory.Reference) error {
+	args := m.Called(ctx, orderID, reference)
+	return args.Error(0)
+}
+
+func (m *MockInventoryService) ReleaseReservations(ctx context.Context, orderID uint, status inventory.ReservationStatus) error {
+	args := m.Called(ctx, orderID, status)
+	return args.Error(0)
+}
+
+type MockOrderService struct {
+	mock.Mock
+}
+
+func (m *MockOrderService) WithTx(tx *gorm.DB) order.IService {
+	return m
+}
+
+func (m *MockOrderService) List(ctx context.Context, request order.ListRequest) (*order.ListOrdersResponse, order.SearchBackend, error) {
+	args := m.Called(ctx, request)
+	response, _ := args.Get(0).(*order.ListOrdersResponse)
+	return response, args.Get(1).(order.SearchBackend), args.Error(2)
+}
+
+func (m *MockOrderService) Get(ctx context.Context, orderID uint) (*order.OrderResponse, error) {
+	args := m.Called(ctx, orderID)
+	response, _ := args.Get(0).(*order.OrderResponse)
+	return response, args.Error(1)
+}
+
+func (m *MockOrderService) ListByUser(ctx context.Context, request order.ListByUserRequest) (*order.ListOrdersResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*order.ListOrdersResponse), args.Error(1)
+}
+
+func (m *MockOrderService) Create(ctx context.Context, request order.PostRequest) (*order.CreateOrderResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*order.CreateOrderResponse), args.Error(1)
+}
+
+func (m *MockOrderService) Update(ctx context.Context, request order.PutRequest) error {
+	args := m.Called(ctx, request)
+	return args.Error(0)
+}
+
+func (m *MockOrderService) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOrderService) Transition(ctx context.Context, request order.TransitionRequest) (*order.OrderResponse, error) {
+	args := m.Called(ctx, request)
+	response, _ := args.Get(0).(*order.OrderResponse)
+	return response, args.Error(1)
+}
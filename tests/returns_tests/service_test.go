@@ -0,0 +1,230 @@
+package returns_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/inventory"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/app/domains/product"
+	"github.com/p4xx07/order-service/app/domains/returns"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+// deliveredOrder has a 10% discount and exclusive taxes on its second item.
+func deliveredOrder(status order.Status) *order.OrderResponse {
+	return &order.OrderResponse{
+		ID:       7,
+		Status:   status,
+		Currency: "EUR",
+		Subtotal: 3000,
+		Discount: 300,
+		Tax:      90,
+		Total:    2790,
+		Items: []order.OrderItemResponse{
+			{ID: 1, Quantity: 2, Price: 1000, Total: 2000, TaxInclusive: true, Tax: 360, Product: product.ProductResponse{ID: 4}},
+			{ID: 2, Quantity: 1, Price: 1000, Total: 1000, Tax: 90, Product: product.ProductResponse{ID: 5}},
+		},
+	}
+}
+
+func newService(store *MockStore, orderService *MockOrderService, inventoryService *MockInventoryService) returns.IService {
+	return returns.NewService(store, orderService, inventoryService, &MockTransactor{}, &configuration.Configuration{}, zap.NewNop().Sugar())
+}
+
+func TestCreate(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOrderService := new(MockOrderService)
+
+	mockOrderService.On("Get", mock.Anything, uint(7)).Return(deliveredOrder(order.StatusDelivered), nil)
+	mockStore.On("ListByOrderForUpdate", mock.Anything, uint(7)).Return([]returns.Return{
+		{ID: 1, Status: returns.StatusRejected, Items: []returns.ReturnItem{{OrderItemID: 1, Quantity: 2}}},
+	}, nil)
+	mockStore.On("Create", mock.Anything, mock.MatchedBy(func(r *returns.Return) bool {
+		return r.Status == returns.StatusRequested && r.Currency == "EUR" && len(r.Items) == 1 && r.Items[0].Quantity == 2
+	})).Return(nil)
+
+	service := newService(mockStore, mockOrderService, new(MockInventoryService))
+
+	response, err := service.Create(context.Background(), returns.PostRequest{
+		OrderID: 7,
+		Reason:  "damaged",
+		Items:   []returns.ItemRequest{{OrderItemID: 1, Quantity: 2}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, returns.StatusRequested, response.Status)
+	mockStore.AssertExpectations(t)
+}
+
+func TestCreateRejected(t *testing.T) {
+	requested := []returns.Return{
+		{ID: 1, Status: returns.StatusApproved, Items: []returns.ReturnItem{{OrderItemID: 1, Quantity: 1}}},
+	}
+
+	tests := []struct {
+		name   string
+		status order.Status
+		items  []returns.ItemRequest
+		err    error
+	}{
+		{name: "not delivered", status: order.StatusShipped, items: []returns.ItemRequest{{OrderItemID: 1, Quantity: 1}}, err: returns.ErrOrderNotReturnable},
+		{name: "already requested", status: order.StatusDelivered, items: []returns.ItemRequest{{OrderItemID: 1, Quantity: 2}}, err: returns.ErrInvalidItems},
+		{name: "unknown item", status: order.StatusDelivered, items: []returns.ItemRequest{{OrderItemID: 9, Quantity: 1}}, err: returns.ErrInvalidItems},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockStore)
+			mockOrderService := new(MockOrderService)
+
+			mockOrderService.On("Get", mock.Anything, uint(7)).Return(deliveredOrder(tt.status), nil)
+			mockStore.On("ListByOrderForUpdate", mock.Anything, uint(7)).Return(requested, nil)
+
+			service := newService(mockStore, mockOrderService, new(MockInventoryService))
+
+			_, err := service.Create(context.Background(), returns.PostRequest{OrderID: 7, Items: tt.items})
+
+			assert.ErrorIs(t, err, tt.err)
+			mockStore.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestApproveInvalidTransition(t *testing.T) {
+	mockStore := new(MockStore)
+
+	mockStore.On("Get", mock.Anything, uint(1)).Return(&returns.Return{ID: 1, Status: returns.StatusRejected}, nil)
+
+	service := newService(mockStore, new(MockOrderService), new(MockInventoryService))
+
+	_, err := service.Approve(context.Background(), 1)
+
+	assert.ErrorIs(t, err, returns.ErrInvalidTransition)
+	mockStore.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReceivePartial(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOrderService := new(MockOrderService)
+	mockInventoryService := new(MockInventoryService)
+
+	approved := &returns.Return{ID: 1, OrderID: 7, Status: returns.StatusApproved, Items: []returns.ReturnItem{{OrderItemID: 1, Quantity: 2}}}
+	mockStore.On("Get", mock.Anything, uint(1)).Return(approved, nil)
+	mockOrderService.On("Get", mock.Anything, uint(7)).Return(deliveredOrder(order.StatusDelivered), nil)
+	mockStore.On("ListByOrderForUpdate", mock.Anything, uint(7)).Return([]returns.Return{*approved}, nil)
+	mockStore.On("Update", mock.Anything, mock.Anything, returns.StatusApproved).Return(nil)
+	mockInventoryService.On("IncreaseStockBulk", mock.Anything, map[uint]int{4: 2}, inventory.Reference{Reason: inventory.ReasonOrderReturned, OrderID: 7}).Return(nil)
+	mockOrderService.On("Transition", mock.Anything, order.TransitionRequest{ID: 7, Status: order.StatusPartiallyReturned}).Return(deliveredOrder(order.StatusPartiallyReturned), nil)
+
+	service := newService(mockStore, mockOrderService, mockInventoryService)
+
+	response, err := service.Receive(context.Background(), returns.ReceiveRequest{ID: 1, Restock: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, returns.StatusReceived, response.Status)
+	assert.NotNil(t, response.ReceivedAt)
+	mockInventoryService.AssertExpectations(t)
+	mockOrderService.AssertExpectations(t)
+}
+
+func TestReceiveCompletesReturn(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOrderService := new(MockOrderService)
+	mockInventoryService := new(MockInventoryService)
+
+	approved := &returns.Return{ID: 2, OrderID: 7, Status: returns.StatusApproved, Items: []returns.ReturnItem{{OrderItemID: 2, Quantity: 1}}}
+	mockStore.On("Get", mock.Anything, uint(2)).Return(approved, nil)
+	mockOrderService.On("Get", mock.Anything, uint(7)).Return(deliveredOrder(order.StatusPartiallyReturned), nil)
+	mockStore.On("ListByOrderForUpdate", mock.Anything, uint(7)).Return([]returns.Return{
+		{ID: 1, OrderID: 7, Status: returns.StatusRefunded, Items: []returns.ReturnItem{{OrderItemID: 1, Quantity: 2}}},
+		*approved,
+	}, nil)
+	mockStore.On("Update", mock.Anything, mock.Anything, returns.StatusApproved).Return(nil)
+	mockOrderService.On("Transition", mock.Anything, order.TransitionRequest{ID: 7, Status: order.StatusReturned}).Return(deliveredOrder(order.StatusReturned), nil)
+
+	service := newService(mockStore, mockOrderService, mockInventoryService)
+
+	_, err := service.Receive(context.Background(), returns.ReceiveRequest{ID: 2})
+
+	assert.NoError(t, err)
+	mockOrderService.AssertExpectations(t)
+	mockInventoryService.AssertNotCalled(t, "IncreaseStockBulk", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReceiveAlreadyReceivedConcurrently(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOrderService := new(MockOrderService)
+
+	approved := &returns.Return{ID: 1, OrderID: 7, Status: returns.StatusApproved, Items: []returns.ReturnItem{{OrderItemID: 1, Quantity: 2}}}
+	mockStore.On("Get", mock.Anything, uint(1)).Return(approved, nil)
+	// another request received the return between the read and the lock
+	mockStore.On("ListByOrderForUpdate", mock.Anything, uint(7)).Return([]returns.Return{
+		{ID: 1, OrderID: 7, Status: returns.StatusReceived, Items: []returns.ReturnItem{{OrderItemID: 1, Quantity: 2}}},
+	}, nil)
+
+	service := newService(mockStore, mockOrderService, new(MockInventoryService))
+
+	_, err := service.Receive(context.Background(), returns.ReceiveRequest{ID: 1, Restock: true})
+
+	assert.ErrorIs(t, err, returns.ErrInvalidTransition)
+	mockStore.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	mockOrderService.AssertNotCalled(t, "Transition", mock.Anything, mock.Anything)
+}
+
+func TestRefund(t *testing.T) {
+	tests := []struct {
+		name   string
+		items  []returns.ReturnItem
+		amount *money.Amount
+		want   money.Amount
+		err    error
+	}{
+		// 2000 less 200 of the discount, the tax is already in the price
+		{name: "inclusive tax", items: []returns.ReturnItem{{OrderItemID: 1, Quantity: 2}}, want: 1800},
+		{name: "one unit", items: []returns.ReturnItem{{OrderItemID: 1, Quantity: 1}}, want: 900},
+		// 1000 less 100 of the discount plus 90 of tax
+		{name: "exclusive tax", items: []returns.ReturnItem{{OrderItemID: 2, Quantity: 1}}, want: 990},
+		{name: "given amount", items: []returns.ReturnItem{{OrderItemID: 2, Quantity: 1}}, amount: ptr(money.Amount(500)), want: 500},
+		// 500 of the 2790 total were refunded before
+		{name: "over the order total", items: []returns.ReturnItem{{OrderItemID: 1, Quantity: 2}}, amount: ptr(money.Amount(2291)), err: returns.ErrInvalidRefund},
+		{name: "zero amount", items: []returns.ReturnItem{{OrderItemID: 1, Quantity: 2}}, amount: ptr(money.Amount(0)), err: returns.ErrInvalidRefund},
+		{name: "negative amount", items: []returns.ReturnItem{{OrderItemID: 1, Quantity: 2}}, amount: ptr(money.Amount(-100)), err: returns.ErrInvalidRefund},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockStore)
+			mockOrderService := new(MockOrderService)
+
+			received := &returns.Return{ID: 2, OrderID: 7, Status: returns.StatusReceived, Items: tt.items}
+			mockStore.On("Get", mock.Anything, uint(2)).Return(received, nil)
+			mockOrderService.On("Get", mock.Anything, uint(7)).Return(deliveredOrder(order.StatusPartiallyReturned), nil)
+			mockStore.On("ListByOrderForUpdate", mock.Anything, uint(7)).Return([]returns.Return{
+				{ID: 1, OrderID: 7, Status: returns.StatusRefunded, RefundAmount: 500},
+				*received,
+			}, nil)
+			mockStore.On("Update", mock.Anything, mock.Anything, returns.StatusReceived).Return(nil)
+
+			service := newService(mockStore, mockOrderService, new(MockInventoryService))
+
+			response, err := service.Refund(context.Background(), returns.RefundRequest{ID: 2, Amount: tt.amount})
+
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				mockStore.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, response.RefundAmount)
+			assert.Equal(t, returns.StatusRefunded, response.Status)
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
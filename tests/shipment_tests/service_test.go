@@ -0,0 +1,169 @@
+package shipment_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/app/domains/shipment"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func paidOrder(status order.Status) *order.OrderResponse {
+	return &order.OrderResponse{
+		ID:     7,
+		Status: status,
+		Items: []order.OrderItemResponse{
+			{ID: 1, Quantity: 2},
+			{ID: 2, Quantity: 1},
+		},
+	}
+}
+
+func TestCreateShipsOrder(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOrderService := new(MockOrderService)
+	logger := zap.NewNop().Sugar()
+
+	mockOrderService.On("Get", mock.Anything, uint(7)).Return(paidOrder(order.StatusPaid), nil)
+	mockStore.On("ListByOrder", mock.Anything, uint(7)).Return([]shipment.Shipment{}, nil)
+	mockStore.On("Create", mock.Anything, mock.MatchedBy(func(s *shipment.Shipment) bool {
+		return s.Status == shipment.StatusShipped && len(s.Items) == 1 && s.Items[0].OrderItemID == 1 && s.Items[0].Quantity == 1
+	})).Return(nil)
+	mockOrderService.On("Transition", mock.Anything, order.TransitionRequest{ID: 7, Status: order.StatusShipped}).Return(paidOrder(order.StatusShipped), nil)
+
+	service := shipment.NewService(mockStore, mockOrderService, &MockTransactor{}, &configuration.Configuration{}, logger)
+
+	response, err := service.Create(context.Background(), shipment.PostRequest{
+		OrderID:        7,
+		Carrier:        "DHL",
+		TrackingNumber: "JD0001",
+		Items:          []shipment.ItemRequest{{OrderItemID: 1, Quantity: 1}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "DHL", response.Carrier)
+	mockStore.AssertExpectations(t)
+	mockOrderService.AssertExpectations(t)
+}
+
+func TestCreateShipsRemaining(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOrderService := new(MockOrderService)
+	logger := zap.NewNop().Sugar()
+
+	mockOrderService.On("Get", mock.Anything, uint(7)).Return(paidOrder(order.StatusShipped), nil)
+	mockStore.On("ListByOrder", mock.Anything, uint(7)).Return([]shipment.Shipment{
+		{ID: 1, OrderID: 7, Status: shipment.StatusShipped, Items: []shipment.ShipmentItem{{OrderItemID: 1, Quantity: 1}}},
+	}, nil)
+	mockStore.On("Create", mock.Anything, mock.MatchedBy(func(s *shipment.Shipment) bool {
+		return len(s.Items) == 2 && s.Items[0].Quantity == 1 && s.Items[1].OrderItemID == 2
+	})).Return(nil)
+
+	service := shipment.NewService(mockStore, mockOrderService, &MockTransactor{}, &configuration.Configuration{}, logger)
+
+	_, err := service.Create(context.Background(), shipment.PostRequest{OrderID: 7, Carrier: "DHL", TrackingNumber: "JD0002"})
+
+	assert.NoError(t, err)
+	mockStore.AssertExpectations(t)
+	mockOrderService.AssertNotCalled(t, "Transition", mock.Anything, mock.Anything)
+}
+
+func TestCreateRejected(t *testing.T) {
+	shipped := []shipment.Shipment{
+		{ID: 1, Items: []shipment.ShipmentItem{{OrderItemID: 1, Quantity: 2}, {OrderItemID: 2, Quantity: 1}}},
+	}
+
+	tests := []struct {
+		name      string
+		status    order.Status
+		shipments []shipment.Shipment
+		items     []shipment.ItemRequest
+		err       error
+	}{
+		{name: "pending order", status: order.StatusPending, err: shipment.ErrOrderNotShippable},
+		{name: "too many units", status: order.StatusPaid, items: []shipment.ItemRequest{{OrderItemID: 1, Quantity: 3}}, err: shipment.ErrInvalidItems},
+		{name: "unknown item", status: order.StatusPaid, items: []shipment.ItemRequest{{OrderItemID: 9, Quantity: 1}}, err: shipment.ErrInvalidItems},
+		{name: "repeated item", status: order.StatusPaid, items: []shipment.ItemRequest{{OrderItemID: 2, Quantity: 1}, {OrderItemID: 2, Quantity: 1}}, err: shipment.ErrInvalidItems},
+		{name: "all shipped", status: order.StatusShipped, shipments: shipped, err: shipment.ErrNothingToShip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockStore)
+			mockOrderService := new(MockOrderService)
+			logger := zap.NewNop().Sugar()
+
+			mockOrderService.On("Get", mock.Anything, uint(7)).Return(paidOrder(tt.status), nil)
+			mockStore.On("ListByOrder", mock.Anything, uint(7)).Return(append([]shipment.Shipment{}, tt.shipments...), nil)
+
+			service := shipment.NewService(mockStore, mockOrderService, &MockTransactor{}, &configuration.Configuration{}, logger)
+
+			_, err := service.Create(context.Background(), shipment.PostRequest{OrderID: 7, Carrier: "DHL", TrackingNumber: "JD0003", Items: tt.items})
+
+			assert.ErrorIs(t, err, tt.err)
+			mockStore.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestDeliverCompletesOrder(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOrderService := new(MockOrderService)
+	logger := zap.NewNop().Sugar()
+
+	shipments := []shipment.Shipment{
+		{ID: 1, OrderID: 7, Status: shipment.StatusDelivered, Items: []shipment.ShipmentItem{{OrderItemID: 1, Quantity: 2}}},
+		{ID: 2, OrderID: 7, Status: shipment.StatusShipped, Items: []shipment.ShipmentItem{{OrderItemID: 2, Quantity: 1}}},
+	}
+	mockStore.On("Get", mock.Anything, uint(2)).Return(&shipments[1], nil)
+	mockOrderService.On("Get", mock.Anything, uint(7)).Return(paidOrder(order.StatusShipped), nil)
+	mockStore.On("ListByOrder", mock.Anything, uint(7)).Return(shipments, nil)
+	mockStore.On("MarkDelivered", mock.Anything, uint(2), mock.Anything).Return(nil)
+	mockOrderService.On("Transition", mock.Anything, order.TransitionRequest{ID: 7, Status: order.StatusDelivered}).Return(paidOrder(order.StatusDelivered), nil)
+
+	service := shipment.NewService(mockStore, mockOrderService, &MockTransactor{}, &configuration.Configuration{}, logger)
+
+	response, err := service.Deliver(context.Background(), shipment.DeliverRequest{ID: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, shipment.StatusDelivered, response.Status)
+	assert.NotNil(t, response.DeliveredAt)
+	mockOrderService.AssertExpectations(t)
+}
+
+func TestDeliverPartial(t *testing.T) {
+	mockStore := new(MockStore)
+	mockOrderService := new(MockOrderService)
+	logger := zap.NewNop().Sugar()
+
+	shipments := []shipment.Shipment{
+		{ID: 1, OrderID: 7, Status: shipment.StatusShipped, Items: []shipment.ShipmentItem{{OrderItemID: 1, Quantity: 2}}},
+	}
+	mockStore.On("Get", mock.Anything, uint(1)).Return(&shipments[0], nil)
+	mockOrderService.On("Get", mock.Anything, uint(7)).Return(paidOrder(order.StatusShipped), nil)
+	mockStore.On("ListByOrder", mock.Anything, uint(7)).Return(shipments, nil)
+	mockStore.On("MarkDelivered", mock.Anything, uint(1), mock.Anything).Return(nil)
+
+	service := shipment.NewService(mockStore, mockOrderService, &MockTransactor{}, &configuration.Configuration{}, logger)
+
+	_, err := service.Deliver(context.Background(), shipment.DeliverRequest{ID: 1})
+
+	assert.NoError(t, err)
+	mockOrderService.AssertNotCalled(t, "Transition", mock.Anything, mock.Anything)
+}
+
+func TestDeliverTwice(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	mockStore.On("Get", mock.Anything, uint(1)).Return(&shipment.Shipment{ID: 1, OrderID: 7, Status: shipment.StatusDelivered}, nil)
+
+	service := shipment.NewService(mockStore, new(MockOrderService), &MockTransactor{}, &configuration.Configuration{}, logger)
+
+	_, err := service.Deliver(context.Background(), shipment.DeliverRequest{ID: 1})
+
+	assert.ErrorIs(t, err, shipment.ErrAlreadyDelivered)
+}
@@ -0,0 +1,103 @@
+package tax_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/tax"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+var rates = []tax.Rate{
+	{ID: 1, Percent: 20},
+	{ID: 2, Region: "IT", Percent: 22, Inclusive: true},
+	{ID: 3, Category: "Books", Percent: 5},
+	{ID: 4, Category: "Books", Region: "IT", Percent: 4, Inclusive: true},
+	{ID: 5, Category: "Food", Region: "US", Percent: 0},
+}
+
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		name     string
+		region   string
+		line     tax.Line
+		expected tax.LineTax
+	}{
+		{
+			name:     "default rate",
+			region:   "FR",
+			line:     tax.Line{Category: "Electronics", Amount: 10000},
+			expected: tax.LineTax{Percent: 20, Amount: 2000},
+		},
+		{
+			name:     "category rate",
+			region:   "FR",
+			line:     tax.Line{Category: "Books", Amount: 1000},
+			expected: tax.LineTax{Percent: 5, Amount: 50},
+		},
+		{
+			name:     "region rate included in the price",
+			region:   "IT",
+			line:     tax.Line{Category: "Electronics", Amount: 12200},
+			expected: tax.LineTax{Percent: 22, Inclusive: true, Amount: 2200},
+		},
+		{
+			name:     "category and region rate",
+			region:   "IT",
+			line:     tax.Line{Category: "Books", Amount: 1040},
+			expected: tax.LineTax{Percent: 4, Inclusive: true, Amount: 40},
+		},
+		{
+			name:     "zero rate",
+			region:   "US",
+			line:     tax.Line{Category: "Food", Amount: 1000},
+			expected: tax.LineTax{Percent: 0, Amount: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockStore)
+			mockStore.On("List", mock.Anything).Return(rates, nil)
+
+			calculator := tax.NewRuleCalculator(mockStore, zap.NewNop().Sugar())
+
+			taxes, err := calculator.Calculate(context.Background(), tax.CalculateRequest{Region: tt.region, Lines: []tax.Line{tt.line}})
+
+			assert.NoError(t, err)
+			assert.Equal(t, []tax.LineTax{tt.expected}, taxes)
+		})
+	}
+}
+
+func TestCalculateWithoutRates(t *testing.T) {
+	mockStore := new(MockStore)
+	mockStore.On("List", mock.Anything).Return([]tax.Rate{}, nil)
+
+	calculator := tax.NewRuleCalculator(mockStore, zap.NewNop().Sugar())
+
+	taxes, err := calculator.Calculate(context.Background(), tax.CalculateRequest{
+		Region: "IT",
+		Lines:  []tax.Line{{Category: "Books", Amount: 1000}, {Category: "Food", Amount: 500}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []tax.LineTax{{}, {}}, taxes)
+}
+
+func TestSetRatesInvalid(t *testing.T) {
+	mockStore := new(MockStore)
+	service := tax.NewService(mockStore, &configuration.Configuration{}, zap.NewNop().Sugar())
+
+	for _, request := range []tax.PutRatesRequest{
+		{},
+		{Rates: []tax.RateRequest{{Region: "IT", Percent: -1}}},
+		{Rates: []tax.RateRequest{{Region: "IT", Percent: 100}}},
+	} {
+		_, err := service.SetRates(context.Background(), request)
+		assert.ErrorIs(t, err, tax.ErrInvalidRate)
+	}
+	mockStore.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
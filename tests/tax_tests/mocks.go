@@ -0,0 +1,26 @@
+package tax_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/tax"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStore struct {
+	mock.Mock
+}
+
+func (m *MockStore) List(ctx context.Context) ([]tax.Rate, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]tax.Rate), args.Error(1)
+}
+
+func (m *MockStore) Save(ctx context.Context, rates []tax.Rate) error {
+	args := m.Called(ctx, rates)
+	return args.Error(0)
+}
+
+func (m *MockStore) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
@@ -0,0 +1,57 @@
+package user_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/user"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStore struct {
+	mock.Mock
+}
+
+func (m *MockStore) Create(ctx context.Context, u *user.User) error {
+	args := m.Called(ctx, u)
+	return args.Error(0)
+}
+
+func (m *MockStore) Get(ctx context.Context, id uint) (*user.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockStore) List(ctx context.Context, request user.ListRequest) ([]user.User, int64, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).([]user.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockStore) Update(ctx context.Context, u *user.User) error {
+	args := m.Called(ctx, u)
+	return args.Error(0)
+}
+
+func (m *MockStore) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockStore) CreateAddress(ctx context.Context, address *user.Address) error {
+	args := m.Called(ctx, address)
+	return args.Error(0)
+}
+
+func (m *MockStore) GetAddress(ctx context.Context, userID uint, addressID uint) (*user.Address, error) {
+	args := m.Called(ctx, userID, addressID)
+	address, _ := args.Get(0).(*user.Address)
+	return address, args.Error(1)
+}
+
+func (m *MockStore) ListAddresses(ctx context.Context, userID uint) ([]user.Address, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]user.Address), args.Error(1)
+}
+
+func (m *MockStore) DeleteAddress(ctx context.Context, userID uint, addressID uint) error {
+	args := m.Called(ctx, userID, addressID)
+	return args.Error(0)
+}
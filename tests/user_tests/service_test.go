@@ -0,0 +1,95 @@
+package user_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/user"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gopkg.in/validator.v2"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestCreate(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	mockStore.On("Create", mock.Anything, &user.User{Name: "Ada Lovelace", Email: "ada@example.com"}).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*user.User).ID = 6
+		}).
+		Return(nil)
+
+	service := user.NewService(mockStore, &configuration.Configuration{}, logger)
+
+	response, err := service.Create(context.Background(), user.PostRequest{Name: "Ada Lovelace", Email: "ada@example.com"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(6), response.ID)
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestCreateDuplicateEmail(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	mockStore.On("Create", mock.Anything, mock.Anything).Return(gorm.ErrDuplicatedKey)
+
+	service := user.NewService(mockStore, &configuration.Configuration{}, logger)
+
+	_, err := service.Create(context.Background(), user.PostRequest{Name: "John Doe", Email: "john.doe@example.com"})
+
+	assert.ErrorIs(t, err, user.ErrEmailTaken)
+}
+
+func TestPostRequestValidation(t *testing.T) {
+	assert.NoError(t, validator.Validate(user.PostRequest{Name: "John", Email: "john@example.com"}))
+	assert.Error(t, validator.Validate(user.PostRequest{Name: "John", Email: "not-an-email"}))
+	assert.Error(t, validator.Validate(user.PostRequest{Email: "john@example.com"}))
+}
+
+func TestCreateAddress(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	mockStore.On("Get", mock.Anything, uint(1)).Return(&user.User{ID: 1}, nil)
+	mockStore.On("CreateAddress", mock.Anything, mock.MatchedBy(func(address *user.Address) bool {
+		return address.UserID == 1 && address.Country == "IT"
+	})).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*user.Address).ID = 3
+		}).
+		Return(nil)
+
+	service := user.NewService(mockStore, &configuration.Configuration{}, logger)
+
+	response, err := service.CreateAddress(context.Background(), user.AddressRequest{
+		UserID:     1,
+		Name:       "Ada Lovelace",
+		Line1:      "Via Roma 1",
+		City:       "Milano",
+		PostalCode: "20121",
+		Country:    "it",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), response.ID)
+	assert.Equal(t, "IT", response.Country)
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestCreateAddressInvalidCountry(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	service := user.NewService(mockStore, &configuration.Configuration{}, logger)
+
+	_, err := service.CreateAddress(context.Background(), user.AddressRequest{UserID: 1, Line1: "Via Roma 1", City: "Milano", Country: "Italy"})
+
+	assert.ErrorIs(t, err, user.ErrInvalidCountry)
+	mockStore.AssertNotCalled(t, "CreateAddress", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,184 @@
+package webhook_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/webhook"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const secret = "whsec_test_secret"
+
+func newDispatcherConfiguration() *configuration.Configuration {
+	return &configuration.Configuration{WebhookRetryDelay: time.Second, WebhookMaxAttempts: 3, WebhookTimeout: time.Second}
+}
+
+// receiver answers with status and checks every request is signed with the secret.
+func receiver(t *testing.T, status int, received *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*received++
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		timestamp, err := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		assert.NoError(t, err)
+
+		assert.Equal(t, webhook.Sign(secret, timestamp, body), r.Header.Get(webhook.HeaderSignature))
+		assert.Equal(t, "order.created.1", r.Header.Get(webhook.HeaderID))
+		assert.Equal(t, "order.created", r.Header.Get(webhook.HeaderEvent))
+		assert.JSONEq(t, `{"id": "order.created.1"}`, string(body))
+
+		w.WriteHeader(status)
+	}))
+}
+
+func dispatch(t *testing.T, subscription webhook.Subscription, delivery webhook.Delivery) (int, webhook.Delivery) {
+	mockStore := new(MockStore)
+
+	mockStore.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]webhook.Delivery{delivery}, nil)
+	mockStore.On("GetSubscriptions", mock.Anything, []uint{subscription.ID}).Return(map[uint]webhook.Subscription{subscription.ID: subscription}, nil)
+
+	var updated webhook.Delivery
+	mockStore.On("UpdateDelivery", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		updated = *args.Get(1).(*webhook.Delivery)
+	}).Return(nil)
+
+	dispatcher := webhook.NewDispatcher(mockStore, &MockTransactor{}, newDispatcherConfiguration(), zap.NewNop().Sugar())
+
+	delivered, err := dispatcher.Dispatch(context.Background())
+	assert.NoError(t, err)
+	return delivered, updated
+}
+
+func newDelivery(attempts int) webhook.Delivery {
+	return webhook.Delivery{
+		ID:             1,
+		SubscriptionID: 1,
+		EventID:        "order.created.1",
+		EventType:      "order.created",
+		Payload:        `{"id": "order.created.1"}`,
+		Status:         webhook.StatusPending,
+		Attempts:       attempts,
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	received := 0
+	server := receiver(t, http.StatusNoContent, &received)
+	defer server.Close()
+
+	delivered, updated := dispatch(t, webhook.Subscription{ID: 1, URL: server.URL, Secret: secret, Active: true}, newDelivery(0))
+
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, 1, received)
+	assert.Equal(t, webhook.StatusDelivered, updated.Status)
+	assert.Equal(t, 1, updated.Attempts)
+	assert.Equal(t, http.StatusNoContent, updated.ResponseStatus)
+	assert.NotNil(t, updated.DeliveredAt)
+}
+
+func TestDispatchRetry(t *testing.T) {
+	received := 0
+	server := receiver(t, http.StatusInternalServerError, &received)
+	defer server.Close()
+
+	delivered, updated := dispatch(t, webhook.Subscription{ID: 1, URL: server.URL, Secret: secret, Active: true}, newDelivery(1))
+
+	assert.Equal(t, 0, delivered)
+	assert.Equal(t, 1, received)
+	assert.Equal(t, webhook.StatusPending, updated.Status)
+	assert.Equal(t, 2, updated.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, updated.ResponseStatus)
+	assert.Equal(t, "receiver answered 500", updated.LastError)
+	assert.WithinDuration(t, time.Now().Add(2*time.Second), updated.NextAttemptAt, time.Second)
+}
+
+func TestDispatchDead(t *testing.T) {
+	received := 0
+	server := receiver(t, http.StatusBadGateway, &received)
+	defer server.Close()
+
+	_, updated := dispatch(t, webhook.Subscription{ID: 1, URL: server.URL, Secret: secret, Active: true}, newDelivery(2))
+
+	assert.Equal(t, webhook.StatusDead, updated.Status)
+	assert.Equal(t, 3, updated.Attempts)
+}
+
+func TestDispatchUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	_, updated := dispatch(t, webhook.Subscription{ID: 1, URL: url, Secret: secret, Active: true}, newDelivery(0))
+
+	assert.Equal(t, webhook.StatusPending, updated.Status)
+	assert.Equal(t, 0, updated.ResponseStatus)
+	assert.NotEmpty(t, updated.LastError)
+}
+
+func TestDispatchPausedSubscription(t *testing.T) {
+	received := 0
+	server := receiver(t, http.StatusOK, &received)
+	defer server.Close()
+
+	_, updated := dispatch(t, webhook.Subscription{ID: 1, URL: server.URL, Secret: secret, Active: false}, newDelivery(0))
+
+	assert.Equal(t, 0, received)
+	assert.Equal(t, webhook.StatusDead, updated.Status)
+	assert.Equal(t, 0, updated.Attempts)
+}
+
+// recordingTransactor reports whether a transaction is open.
+type recordingTransactor struct {
+	open bool
+}
+
+func (r *recordingTransactor) Transaction(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error) error {
+	r.open = true
+	defer func() { r.open = false }()
+	return fn(ctx, nil)
+}
+
+func TestDispatchSendsOutsideTransaction(t *testing.T) {
+	transactor := &recordingTransactor{}
+	sentInTransaction := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sentInTransaction = transactor.open
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockStore := new(MockStore)
+	start := time.Now()
+	// the lease outlasts a batch whose receivers all time out
+	mockStore.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything, mock.MatchedBy(func(leaseUntil time.Time) bool {
+		return leaseUntil.After(start.Add(20 * time.Second))
+	})).Return([]webhook.Delivery{newDelivery(0)}, nil)
+	mockStore.On("GetSubscriptions", mock.Anything, []uint{1}).Return(map[uint]webhook.Subscription{
+		1: {ID: 1, URL: server.URL, Secret: secret, Active: true},
+	}, nil)
+
+	recordedInTransaction := false
+	mockStore.On("UpdateDelivery", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		recordedInTransaction = transactor.open
+	}).Return(nil)
+
+	dispatcher := webhook.NewDispatcher(mockStore, transactor, newDispatcherConfiguration(), zap.NewNop().Sugar())
+
+	delivered, err := dispatcher.Dispatch(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.False(t, sentInTransaction)
+	assert.True(t, recordedInTransaction)
+	mockStore.AssertExpectations(t)
+}
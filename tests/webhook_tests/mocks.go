@@ -0,0 +1,86 @@
+package webhook_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/webhook"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"time"
+)
+
+type MockTransactor struct{}
+
+func (m *MockTransactor) Transaction(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error) error {
+	return fn(ctx, nil)
+}
+
+type MockStore struct {
+	mock.Mock
+}
+
+func (m *MockStore) WithTx(tx *gorm.DB) webhook.IStore {
+	return m
+}
+
+func (m *MockStore) CreateSubscription(ctx context.Context, subscription *webhook.Subscription) error {
+	args := m.Called(ctx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockStore) GetSubscription(ctx context.Context, id uint) (*webhook.Subscription, error) {
+	args := m.Called(ctx, id)
+	subscription, _ := args.Get(0).(*webhook.Subscription)
+	return subscription, args.Error(1)
+}
+
+func (m *MockStore) GetSubscriptions(ctx context.Context, ids []uint) (map[uint]webhook.Subscription, error) {
+	args := m.Called(ctx, ids)
+	subscriptions, _ := args.Get(0).(map[uint]webhook.Subscription)
+	return subscriptions, args.Error(1)
+}
+
+func (m *MockStore) ListSubscriptions(ctx context.Context, request webhook.ListRequest) ([]webhook.Subscription, int64, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).([]webhook.Subscription), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockStore) ListActiveSubscriptions(ctx context.Context) ([]webhook.Subscription, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]webhook.Subscription), args.Error(1)
+}
+
+func (m *MockStore) UpdateSubscription(ctx context.Context, subscription *webhook.Subscription) error {
+	args := m.Called(ctx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockStore) DeleteSubscription(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockStore) CreateDeliveries(ctx context.Context, deliveries []webhook.Delivery) error {
+	args := m.Called(ctx, deliveries)
+	return args.Error(0)
+}
+
+func (m *MockStore) ClaimDue(ctx context.Context, now time.Time, limit int, leaseUntil time.Time) ([]webhook.Delivery, error) {
+	args := m.Called(ctx, now, limit, leaseUntil)
+	return args.Get(0).([]webhook.Delivery), args.Error(1)
+}
+
+func (m *MockStore) ListDeliveries(ctx context.Context, request webhook.ListDeliveriesRequest) ([]webhook.Delivery, int64, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).([]webhook.Delivery), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockStore) UpdateDelivery(ctx context.Context, delivery *webhook.Delivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockStore) Redeliver(ctx context.Context, id uint) (*webhook.Delivery, error) {
+	args := m.Called(ctx, id)
+	delivery, _ := args.Get(0).(*webhook.Delivery)
+	return delivery, args.Error(1)
+}
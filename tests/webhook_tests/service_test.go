@@ -0,0 +1,133 @@
+package webhook_tests
+
+import (
+	"context"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/app/domains/webhook"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+)
+
+func TestCreate(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	var created *webhook.Subscription
+	mockStore.On("CreateSubscription", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*webhook.Subscription)
+		created.ID = 1
+	}).Return(nil)
+
+	service := webhook.NewService(mockStore, &configuration.Configuration{}, logger)
+
+	response, err := service.Create(context.Background(), webhook.PostRequest{
+		URL:        "https://example.com/hooks",
+		EventTypes: []event.Name{order.EventOrderUpdated, order.EventOrderCreated, order.EventOrderUpdated},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), response.ID)
+	assert.True(t, response.Active)
+	assert.Equal(t, []event.Name{order.EventOrderCreated, order.EventOrderUpdated}, response.EventTypes)
+	assert.True(t, strings.HasPrefix(response.Secret, "whsec_"))
+	assert.Equal(t, created.Secret, response.Secret)
+}
+
+func TestCreateInvalid(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+
+	tests := []struct {
+		name    string
+		request webhook.PostRequest
+	}{
+		{
+			name:    "relative url",
+			request: webhook.PostRequest{URL: "/hooks", EventTypes: []event.Name{order.EventOrderCreated}},
+		},
+		{
+			name:    "unsupported scheme",
+			request: webhook.PostRequest{URL: "ftp://example.com/hooks", EventTypes: []event.Name{order.EventOrderCreated}},
+		},
+		{
+			name:    "unknown event type",
+			request: webhook.PostRequest{URL: "https://example.com/hooks", EventTypes: []event.Name{"stock.decreased"}},
+		},
+		{
+			name:    "short secret",
+			request: webhook.PostRequest{URL: "https://example.com/hooks", EventTypes: []event.Name{order.EventOrderCreated}, Secret: "short"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockStore)
+			service := webhook.NewService(mockStore, &configuration.Configuration{}, logger)
+
+			_, err := service.Create(context.Background(), tt.request)
+
+			assert.ErrorIs(t, err, webhook.ErrInvalidSubscription)
+			mockStore.AssertNotCalled(t, "CreateSubscription", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUpdatePauses(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	mockStore.On("GetSubscription", mock.Anything, uint(1)).Return(&webhook.Subscription{
+		ID:         1,
+		URL:        "https://example.com/hooks",
+		EventTypes: webhook.Events{order.EventOrderCreated},
+		Secret:     "whsec_secret",
+		Active:     true,
+	}, nil)
+	mockStore.On("UpdateSubscription", mock.Anything, mock.MatchedBy(func(subscription *webhook.Subscription) bool {
+		return !subscription.Active && subscription.URL == "https://example.com/v2/hooks" && subscription.Secret == "whsec_secret"
+	})).Return(nil)
+
+	service := webhook.NewService(mockStore, &configuration.Configuration{}, logger)
+
+	active := false
+	response, err := service.Update(context.Background(), webhook.PutRequest{
+		ID:         1,
+		URL:        "https://example.com/v2/hooks",
+		EventTypes: []event.Name{order.EventOrderStatusChanged},
+		Active:     &active,
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, response.Active)
+	assert.Equal(t, []event.Name{order.EventOrderStatusChanged}, response.EventTypes)
+
+	mockStore.AssertExpectations(t)
+}
+
+func TestListDeliveriesUnknownSubscription(t *testing.T) {
+	mockStore := new(MockStore)
+	logger := zap.NewNop().Sugar()
+
+	mockStore.On("GetSubscription", mock.Anything, uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+	service := webhook.NewService(mockStore, &configuration.Configuration{}, logger)
+
+	_, err := service.ListDeliveries(context.Background(), webhook.ListDeliveriesRequest{SubscriptionID: 9})
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	mockStore.AssertNotCalled(t, "ListDeliveries", mock.Anything, mock.Anything)
+}
+
+func TestListDeliveriesInvalidStatus(t *testing.T) {
+	mockStore := new(MockStore)
+	service := webhook.NewService(mockStore, &configuration.Configuration{}, zap.NewNop().Sugar())
+
+	_, err := service.ListDeliveries(context.Background(), webhook.ListDeliveriesRequest{SubscriptionID: 1, Status: "lost"})
+
+	assert.ErrorIs(t, err, webhook.ErrInvalidDeliveryStatus)
+}
@@ -0,0 +1,126 @@
+package webhook_tests
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/p4xx07/order-service/app/domains/inventory"
+	"github.com/p4xx07/order-service/app/domains/order"
+	"github.com/p4xx07/order-service/app/domains/webhook"
+	"github.com/p4xx07/order-service/configuration"
+	"github.com/p4xx07/order-service/internal/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func newSubscribedBus(mockStore *MockStore) event.IBus {
+	logger := zap.NewNop().Sugar()
+	bus := event.NewBus(&configuration.Configuration{}, logger)
+	webhook.NewSubscriber(mockStore, &configuration.Configuration{}, logger).Subscribe(bus)
+	return bus
+}
+
+func TestSubscriberCreatesDeliveries(t *testing.T) {
+	mockStore := new(MockStore)
+
+	mockStore.On("ListActiveSubscriptions", mock.Anything).Return([]webhook.Subscription{
+		{ID: 1, EventTypes: webhook.Events{order.EventOrderStatusChanged}, Active: true},
+		{ID: 2, EventTypes: webhook.Events{order.EventOrderCreated}, Active: true},
+		{ID: 3, EventTypes: webhook.Events{order.EventOrderCreated, order.EventOrderStatusChanged}, Active: true},
+	}, nil)
+
+	var deliveries []webhook.Delivery
+	mockStore.On("CreateDeliveries", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		deliveries = args.Get(1).([]webhook.Delivery)
+	}).Return(nil)
+
+	err := newSubscribedBus(mockStore).Publish(context.Background(), order.OrderStatusChanged{
+		OutboxID: 7,
+		Order:    order.Order{ID: 3, Status: order.StatusShipped},
+		From:     order.StatusPaid,
+		To:       order.StatusShipped,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 2)
+	assert.Equal(t, uint(1), deliveries[0].SubscriptionID)
+	assert.Equal(t, uint(3), deliveries[1].SubscriptionID)
+
+	for _, delivery := range deliveries {
+		assert.Equal(t, "order.status_changed.7", delivery.EventID)
+		assert.Equal(t, order.EventOrderStatusChanged, delivery.EventType)
+		assert.Equal(t, webhook.StatusPending, delivery.Status)
+	}
+
+	var payload struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			From  string `json:"from"`
+			To    string `json:"to"`
+			Order struct {
+				ID uint `json:"id"`
+			} `json:"order"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(deliveries[0].Payload), &payload))
+	assert.Equal(t, "order.status_changed.7", payload.ID)
+	assert.Equal(t, "order.status_changed", payload.Type)
+	assert.Equal(t, "paid", payload.Data.From)
+	assert.Equal(t, "shipped", payload.Data.To)
+	assert.Equal(t, uint(3), payload.Data.Order.ID)
+}
+
+func TestSubscriberWithoutSubscriptions(t *testing.T) {
+	mockStore := new(MockStore)
+
+	mockStore.On("ListActiveSubscriptions", mock.Anything).Return([]webhook.Subscription{
+		{ID: 1, EventTypes: webhook.Events{order.EventOrderCreated}, Active: true},
+	}, nil)
+	mockStore.On("CreateDeliveries", mock.Anything, []webhook.Delivery(nil)).Return(nil)
+
+	err := newSubscribedBus(mockStore).Publish(context.Background(), order.OrderDeleted{OutboxID: 8, OrderID: 3})
+
+	assert.NoError(t, err)
+	mockStore.AssertExpectations(t)
+}
+
+func TestSubscriberLowStock(t *testing.T) {
+	mockStore := new(MockStore)
+
+	mockStore.On("ListActiveSubscriptions", mock.Anything).Return([]webhook.Subscription{
+		{ID: 1, EventTypes: webhook.Events{inventory.EventStockLow}, Active: true},
+		{ID: 2, EventTypes: webhook.Events{order.EventOrderCreated}, Active: true},
+	}, nil)
+
+	var deliveries []webhook.Delivery
+	mockStore.On("CreateDeliveries", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		deliveries = args.Get(1).([]webhook.Delivery)
+	}).Return(nil)
+
+	err := newSubscribedBus(mockStore).Publish(context.Background(), inventory.StockLow{Alert: inventory.LowStockAlert{
+		ID:              4,
+		ProductID:       2,
+		Stock:           3,
+		ReorderPoint:    5,
+		ReorderQuantity: 40,
+		Reason:          inventory.ReasonDamage,
+		Status:          inventory.AlertOpen,
+	}})
+
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+	assert.Equal(t, uint(1), deliveries[0].SubscriptionID)
+	assert.Equal(t, "stock.low.4", deliveries[0].EventID)
+
+	var payload struct {
+		Data struct {
+			ProductID       uint `json:"product_id"`
+			ReorderQuantity int  `json:"reorder_quantity"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(deliveries[0].Payload), &payload))
+	assert.Equal(t, uint(2), payload.Data.ProductID)
+	assert.Equal(t, 40, payload.Data.ReorderQuantity)
+}